@@ -0,0 +1,146 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/spf13/cobra"
+)
+
+var (
+	apiKeyName      string
+	apiKeyScopes    string
+	apiKeyExpiresIn string
+)
+
+// apiKeyCmd represents the apikey command
+var apiKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manage API keys of service accounts",
+	Long: `Creates, lists and revokes API keys. Services send a key in the X-API-Key
+header or as "Authorization: Bearer <key>", and may do what its scopes allow:
+users:read, users:write and sessions:read.`,
+}
+
+var createAPIKeyCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key and print it once",
+	Run: func(cmd *cobra.Command, args []string) {
+		var expiresAt *time.Time
+		expiresIn, err := parseRetention(apiKeyExpiresIn)
+		if err != nil {
+			fail("invalid --expires-in", err)
+		}
+		if expiresIn > 0 {
+			at := time.Now().Add(expiresIn)
+			expiresAt = &at
+		}
+
+		var scopes entities.Scopes
+		for _, scope := range strings.Split(apiKeyScopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, entities.Scope(scope))
+			}
+		}
+
+		key, raw, err := newAPIKeyUsecase().Create(cliContext(), apiKeyName, scopes, expiresAt)
+		if err != nil {
+			fail("create api key failed", err)
+		}
+
+		fmt.Printf("Created API key %d (%s) for %s\n", key.ID, key.Prefix, key.ServiceAccount)
+		fmt.Println("Store it now, it can't be shown again:")
+		fmt.Println(raw)
+	},
+}
+
+var listAPIKeysCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys",
+	Run: func(cmd *cobra.Command, args []string) {
+		keys, err := newAPIKeyUsecase().List(cliContext())
+		if err != nil {
+			fail("list api keys failed", err)
+		}
+
+		now := time.Now()
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tPREFIX\tSERVICE ACCOUNT\tSCOPES\tEXPIRES\tLAST USED\tSTATUS")
+		for _, key := range keys {
+			status := "active"
+			switch {
+			case key.RevokedAt != nil:
+				status = "revoked"
+			case !key.Active(now):
+				status = "expired"
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				key.ID, key.Prefix, key.ServiceAccount, joinScopes(key.Scopes), formatTime(key.ExpiresAt, "never"), formatTime(key.LastUsedAt, "-"), status)
+		}
+		_ = w.Flush()
+	},
+}
+
+var revokeAPIKeyCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke an API key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil || id == 0 {
+			fail("invalid api key id", fmt.Errorf("%q is not an api key id", args[0]))
+		}
+
+		err = newAPIKeyUsecase().Revoke(cliContext(), id)
+		if err != nil {
+			fail("revoke api key failed", err)
+		}
+
+		fmt.Printf("Revoked API key %d\n", id)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(apiKeyCmd)
+
+	apiKeyCmd.AddCommand(createAPIKeyCmd)
+	apiKeyCmd.AddCommand(listAPIKeysCmd)
+	apiKeyCmd.AddCommand(revokeAPIKeyCmd)
+
+	createAPIKeyCmd.Flags().StringVar(&apiKeyName, "name", "", "Service account the key belongs to")
+	createAPIKeyCmd.Flags().StringVar(&apiKeyScopes, "scopes", "", "Comma separated scopes, e.g. users:read,users:write")
+	createAPIKeyCmd.Flags().StringVar(&apiKeyExpiresIn, "expires-in", "90d", "Lifetime of the key (e.g. 90d, 720h), 0 for never")
+}
+
+// newAPIKeyUsecase wires the API key usecase for CLI commands.
+func newAPIKeyUsecase() usecase.APIKeyUsecase {
+	c := newContainer()
+
+	return usecase.NewAPIKeyUsecase(c.APIKeyRepository(), usecase.NewAuthorizer(c.RoleRepository()), c.Clock)
+}
+
+// joinScopes formats scopes as a comma separated list.
+func joinScopes(scopes entities.Scopes) string {
+	values := make([]string, len(scopes))
+	for i, scope := range scopes {
+		values[i] = string(scope)
+	}
+
+	return strings.Join(values, ",")
+}
+
+// formatTime formats t for tables, or returns empty when t is nil.
+func formatTime(t *time.Time, empty string) string {
+	if t == nil {
+		return empty
+	}
+
+	return t.Local().Format(time.DateTime)
+}
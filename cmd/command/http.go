@@ -2,15 +2,19 @@ package command
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/alirezaghasemi/user-manager/internal/config"
 	"github.com/alirezaghasemi/user-manager/internal/container"
 	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/middleware"
 	"github.com/alirezaghasemi/user-manager/internal/delivary/http/router"
-	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/cursor"
 	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
 )
 
@@ -21,9 +25,10 @@ var httpCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		initializeConfigs()
 
-		log.Println("starting kyc http server")
-
-		startServer(&Cfg)
+		err := startServer(&Cfg)
+		if err != nil {
+			fail("http server failed", err)
+		}
 	},
 }
 
@@ -31,21 +36,126 @@ func init() {
 	rootCmd.AddCommand(httpCmd)
 }
 
+// placeholderSecrets are example values of secrets, never safe to run with.
+var placeholderSecrets = []string{"change-me-jwt-secret", "change-me-mfa-key", "change-me-cursor-secret", "secret", "changeme", "change-me"}
+
+// checkSecrets refuses to start with secrets anyone could guess: the key
+// sealing TOTP secrets, in jwt mode the secret signing access tokens, and
+// the key signing cursors unless it is left unset for a random one.
+func checkSecrets(cfg config.Config) error {
+	if cfg.Auth.Mode == config.AuthModeJWT {
+		err := checkSecret("AUTH_JWT_SECRET", cfg.Auth.JWTSecret)
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.Server.CursorSecret != "" {
+		err := checkSecret("SERVER_CURSOR_SECRET", cfg.Server.CursorSecret)
+		if err != nil {
+			return err
+		}
+	}
+
+	return checkSecret("AUTH_MFA_ENCRYPTION_KEY", cfg.Auth.MFAEncryptionKey)
+}
+
+// checkSecret fails when the secret in the variable name is unset or a
+// placeholder.
+func checkSecret(name string, secret string) error {
+	if secret == "" {
+		return fmt.Errorf("%s is not set, set it to a long random value", name)
+	}
+	if slices.Contains(placeholderSecrets, strings.ToLower(secret)) {
+		return fmt.Errorf("%s is a placeholder, set it to a long random value", name)
+	}
+
+	return nil
+}
+
 func startServer(cfg *config.Config) error {
-	c := container.NewContainer(*cfg)
+	err := checkSecrets(*cfg)
+	if err != nil {
+		return err
+	}
+
+	c := container.NewContainer(*cfg, Logger)
 	// ----- Repositories -----
-	userRepository := repository.NewUserRepository(c.DB)
+	userRepository := c.UserRepository()
+	sessionRepository := c.SessionRepository()
+	roleRepository := c.RoleRepository()
+	apiKeyRepository := c.APIKeyRepository()
+	mfaRepository := c.MFARepository()
+	oneTimeTokenRepository := c.OneTimeTokenRepository()
+	lockoutRepository := c.LockoutRepository()
+	auditRepository := c.AuditRepository()
+	txManager := c.TxManager()
+
+	tokens := c.TokenManager()
+
+	mail, err := c.Mailer()
+	if err != nil {
+		return err
+	}
+
+	// ----- Authentication -----
+	var authenticate gin.HandlerFunc
+	switch cfg.Auth.Mode {
+	case config.AuthModeJWT:
+		authenticate = middleware.Authenticate(tokens)
+	case config.AuthModeHeader:
+		c.Logger.Warn("trusting the user id in a header, only run this behind a proxy that sets it", slog.String("header", cfg.Auth.TrustedHeader))
+		authenticate = middleware.TrustedHeader(cfg.Auth.TrustedHeader)
+	default:
+		return fmt.Errorf("unknown AUTH_MODE %q, use %s or %s", cfg.Auth.Mode, config.AuthModeJWT, config.AuthModeHeader)
+	}
 
 	// ----- Usecases -----
-	userUsecase := usecase.NewUserUsecase(userRepository, c.Validate)
+	authorizer := usecase.NewAuthorizer(roleRepository)
+	userUsecase := usecase.NewUserUsecase(userRepository, auditRepository, txManager, authorizer, c.Clock, c.Validate)
+	userUsecase = usecase.NewMetricsUserUsecase(usecase.NewLoggingUserUsecase(userUsecase, c.Logger), c.Metrics)
+	lockout := usecase.LockoutOptions{
+		Threshold:    cfg.Auth.LockoutThreshold,
+		IPThreshold:  cfg.Auth.LockoutIPThreshold,
+		Window:       cfg.Auth.LockoutWindow,
+		BaseDuration: cfg.Auth.LockoutDuration,
+		MaxDuration:  cfg.Auth.LockoutMaxDuration,
+	}
+	authUsecase := usecase.NewAuthUsecase(userRepository, sessionRepository, lockoutRepository, authorizer, tokens, c.Clock, cfg.Auth.RefreshTokenTTL, lockout)
+	roleUsecase := usecase.NewRoleUsecase(roleRepository, userRepository, txManager, authorizer)
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepository, authorizer, c.Clock)
+	mfaUsecase := usecase.NewMFAUsecase(mfaRepository, userRepository, lockoutRepository, auditRepository, txManager, authorizer, c.SecretBox(), c.Clock, usecase.MFAOptions{
+		Issuer:  cfg.Auth.MFAIssuer,
+		Skew:    cfg.Auth.MFASkew,
+		Lockout: lockout,
+	})
+	accountUsecase := usecase.NewAccountUsecase(userRepository, oneTimeTokenRepository, sessionRepository, lockoutRepository, txManager, authorizer, mail, c.Logger, c.Clock, usecase.AccountOptions{
+		LinkBaseURL:          cfg.Mail.LinkBaseURL,
+		EmailVerificationTTL: cfg.Auth.EmailVerificationTTL,
+		PasswordResetTTL:     cfg.Auth.PasswordResetTTL,
+		MaxPendingMails:      cfg.Mail.MaxPending,
+		ResetLimit:           cfg.Auth.PasswordResetLimit,
+		ResetWindow:          cfg.Auth.PasswordResetWindow,
+	})
+
+	auditUsecase := usecase.NewAuditUsecase(auditRepository, authorizer)
+
+	// API keys are accepted whichever way users authenticate
+	authenticate = middleware.AuthenticateAPIKey(apiKeyUsecase, authenticate)
 
 	// ----- Handlers -----
-	userHandler := handler.NewUserHandler(userUsecase, c.Validate)
+	userHandler := handler.NewUserHandler(userUsecase, c.Validate, cursor.NewCodec(cfg.Server.CursorSecret))
+	authHandler := handler.NewAuthHandler(authUsecase, c.Validate)
+	roleHandler := handler.NewRoleHandler(roleUsecase)
+	mfaHandler := handler.NewMFAHandler(mfaUsecase, c.Validate)
+	accountHandler := handler.NewAccountHandler(accountUsecase, c.Validate)
+	auditHandler := handler.NewAuditHandler(auditUsecase, c.Validate)
 
 	// ----- Routers -----
-	router := router.NewRouter(*userHandler)
+	router, err := router.NewRouter(*userHandler, *authHandler, *roleHandler, *mfaHandler, *accountHandler, *auditHandler, c.Translator, c.Logger, c.Metrics, cfg.Server.TrustedProxies, authenticate)
+	if err != nil {
+		return err
+	}
 
-	fmt.Printf("%s:%d\n", cfg.Server.Host, cfg.Server.Port)
 	server := &http.Server{
 		Addr:         fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
 		WriteTimeout: cfg.Server.WriteTimeout,
@@ -53,7 +163,8 @@ func startServer(cfg *config.Config) error {
 		Handler:      router,
 	}
 
-	err := server.ListenAndServe()
+	c.Logger.Info("starting http server", slog.String("addr", server.Addr))
+	err = server.ListenAndServe()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/spf13/cobra"
+)
+
+// rolesCmd represents the roles command
+var rolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "Manage the roles of users",
+	Long: `Lists roles and assigns them to users, e.g. to make the first user an
+admin who can then manage roles over HTTP. Every user holds the "user" role
+without it being assigned.`,
+}
+
+var listRolesCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List roles with their permissions",
+	Run: func(cmd *cobra.Command, args []string) {
+		roles, err := newRoleUsecase().List(cliContext())
+		if err != nil {
+			fail("list roles failed", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ROLE\tPERMISSIONS\tDESCRIPTION")
+		for _, role := range roles {
+			permissions := make([]string, len(role.Permissions))
+			for i, permission := range role.Permissions {
+				permissions[i] = string(permission)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", role.Name, strings.Join(permissions, ","), role.Description)
+		}
+		_ = w.Flush()
+	},
+}
+
+var assignRoleCmd = &cobra.Command{
+	Use:   "assign <user-id> <role>",
+	Short: "Assign a role to a user",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		userID := parseUserID(args[0])
+
+		err := newRoleUsecase().Assign(cliContext(), userID, args[1])
+		if err != nil {
+			fail("assign role failed", err)
+		}
+
+		fmt.Printf("Assigned role %s to user %d\n", args[1], userID)
+	},
+}
+
+var revokeRoleCmd = &cobra.Command{
+	Use:   "revoke <user-id> <role>",
+	Short: "Revoke a role from a user",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		userID := parseUserID(args[0])
+
+		err := newRoleUsecase().Revoke(cliContext(), userID, args[1])
+		if err != nil {
+			fail("revoke role failed", err)
+		}
+
+		fmt.Printf("Revoked role %s from user %d\n", args[1], userID)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rolesCmd)
+
+	rolesCmd.AddCommand(listRolesCmd)
+	rolesCmd.AddCommand(assignRoleCmd)
+	rolesCmd.AddCommand(revokeRoleCmd)
+}
+
+// newRoleUsecase wires the role usecase for CLI commands.
+func newRoleUsecase() usecase.RoleUsecase {
+	c := newContainer()
+	roleRepository := c.RoleRepository()
+
+	return usecase.NewRoleUsecase(roleRepository, c.UserRepository(), c.TxManager(), usecase.NewAuthorizer(roleRepository))
+}
+
+// parseUserID parses a user id argument or exits.
+func parseUserID(value string) uint64 {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		fail("invalid user id", fmt.Errorf("%q is not a user id", value))
+	}
+
+	return id
+}
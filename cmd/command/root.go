@@ -1,10 +1,11 @@
 package command
 
 import (
-	"log"
+	"log/slog"
 	"os"
 
 	"github.com/alirezaghasemi/user-manager/internal/config"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/logger"
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/spf13/cobra"
@@ -12,6 +13,7 @@ import (
 
 var (
 	Cfg     config.Config
+	Logger  *slog.Logger
 	envFile string
 	rootCmd = &cobra.Command{
 		Use: "user-manager",
@@ -25,7 +27,7 @@ func initializeConfigs() {
 	if envFile != "" {
 		err := godotenv.Load(envFile)
 		if err != nil {
-			log.Fatalf("Error loading env file: %v", err)
+			fail("Error loading env file", err)
 		}
 	} else {
 		_ = godotenv.Load()
@@ -33,8 +35,36 @@ func initializeConfigs() {
 
 	err := envconfig.Process("", &Cfg)
 	if err != nil {
-		log.Fatalf("Error parsing config: %v", err)
+		fail("Error parsing config", err)
 	}
+
+	// the logger outlives a reload of the config by the http command
+	if Logger != nil {
+		return
+	}
+
+	output, err := logger.Open(Cfg.Log.Output)
+	if err != nil {
+		fail("Error opening log output", err)
+	}
+	Logger, err = logger.New(output, Cfg.Log)
+	if err != nil {
+		fail("Error configuring logger", err)
+	}
+	// libraries logging through the log package end up in the same place
+	slog.SetDefault(Logger)
+}
+
+// fail logs why the command failed and exits. Before the config is loaded
+// it logs to the default logger.
+func fail(msg string, err error) {
+	log := Logger
+	if log == nil {
+		log = slog.Default()
+	}
+
+	log.ErrorContext(cliContext(), msg, slog.Any("error", err))
+	os.Exit(1)
 }
 
 func init() {
@@ -0,0 +1,94 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/container"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/spf13/cobra"
+)
+
+var purgeOlderThan string
+
+// usersCmd represents the users command
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "Manage users",
+}
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently remove soft-deleted users",
+	Long:  `Permanently removes users that were soft-deleted longer ago than --older-than, e.g. 30d, 12h or 90m.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		olderThan, err := parseRetention(purgeOlderThan)
+		if err != nil {
+			fail("invalid --older-than", err)
+		}
+
+		userUsecase := newUserUsecase(newContainer())
+
+		purged, err := userUsecase.Purge(cliContext(), olderThan)
+		if err != nil {
+			fail("purge failed", err)
+		}
+
+		fmt.Printf("Purged %d users deleted more than %s ago\n", purged, purgeOlderThan)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(usersCmd)
+
+	usersCmd.AddCommand(purgeCmd)
+	purgeCmd.Flags().StringVar(&purgeOlderThan, "older-than", "30d", "Only purge users deleted longer ago than this (e.g. 30d, 12h)")
+}
+
+// newContainer builds the container for CLI commands from the loaded config.
+func newContainer() *container.Container {
+	return container.NewContainer(Cfg, Logger)
+}
+
+// newUserUsecase wires the user usecase for CLI commands.
+func newUserUsecase(c *container.Container) usecase.UserUsecase {
+	userRepository := c.UserRepository()
+
+	return usecase.NewUserUsecase(userRepository, c.AuditRepository(), c.TxManager(), usecase.NewAuthorizer(c.RoleRepository()), c.Clock, c.Validate)
+}
+
+// cliContext is the context CLI commands run their usecases with. Whoever
+// runs the CLI has access to the database anyway, so it acts as the system
+// principal, recording "cli" as the actor of any change.
+func cliContext() context.Context {
+	ctx := principal.WithPrincipal(context.Background(), entities.SystemPrincipal)
+	return actor.WithActor(ctx, "cli")
+}
+
+// parseRetention parses a duration that may also use a "d" (days) suffix.
+func parseRetention(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("duration must not be negative")
+	}
+
+	return duration, nil
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/request"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createName          string
+	createFamily        string
+	createEmail         string
+	createAge           int
+	createPassword      string
+	createPasswordStdin bool
+)
+
+var createUserCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a user, e.g. the first one able to log in",
+	Long: `Creates a user like POST /api/v1/user does. Pass the password with
+--password-stdin to keep it out of the shell history and process list.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		password := createPassword
+		if createPasswordStdin {
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				fail("read password from stdin", err)
+			}
+			password = strings.TrimRight(line, "\r\n")
+		}
+
+		req := request.CreateUserRequest{
+			Name:     createName,
+			Family:   createFamily,
+			Email:    createEmail,
+			Age:      createAge,
+			Password: password,
+		}
+
+		c := newContainer()
+		err := c.Validate.Struct(req)
+		if err != nil {
+			fail("invalid user", err)
+		}
+
+		user, err := newUserUsecase(c).Create(cliContext(), entities.User{
+			Name:     req.Name,
+			Family:   req.Family,
+			Email:    req.Email,
+			Age:      req.Age,
+			Password: req.Password,
+		})
+		if err != nil {
+			fail("create failed", err)
+		}
+
+		fmt.Printf("Created user %d <%s>\n", user.ID, user.Email)
+	},
+}
+
+func init() {
+	usersCmd.AddCommand(createUserCmd)
+	createUserCmd.Flags().StringVar(&createName, "name", "", "First name")
+	createUserCmd.Flags().StringVar(&createFamily, "family", "", "Family name")
+	createUserCmd.Flags().StringVar(&createEmail, "email", "", "Email, also the login")
+	createUserCmd.Flags().IntVar(&createAge, "age", 0, "Age")
+	createUserCmd.Flags().StringVar(&createPassword, "password", "", "Login password, users without one can't log in")
+	createUserCmd.Flags().BoolVar(&createPasswordStdin, "password-stdin", false, "Read the password from the first line of stdin")
+	_ = createUserCmd.MarkFlagRequired("email")
+	createUserCmd.MarkFlagsMutuallyExclusive("password", "password-stdin")
+}
@@ -0,0 +1,126 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/userfile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutput         string
+	exportFormat         string
+	exportColumns        string
+	exportName           string
+	exportFamily         string
+	exportEmail          string
+	exportMinAge         int
+	exportMaxAge         int
+	exportIncludeDeleted bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export users as csv, ndjson or xlsx",
+	Long: `Streams every user matching the filters to --output (stdout by default)
+in batches, so the whole table is never held in memory.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := userfile.Format(exportFormat)
+		if format == "" {
+			format = exportFormatFromPath(exportOutput)
+		}
+
+		columns, err := userfile.ParseColumns(exportColumns)
+		if err != nil {
+			fail("invalid --columns", err)
+		}
+
+		filter := entities.UserFilter{
+			Name:           exportName,
+			Family:         exportFamily,
+			Email:          exportEmail,
+			IncludeDeleted: exportIncludeDeleted,
+		}
+		if cmd.Flags().Changed("min-age") {
+			filter.MinAge = &exportMinAge
+		}
+		if cmd.Flags().Changed("max-age") {
+			filter.MaxAge = &exportMaxAge
+		}
+
+		out := os.Stdout
+		if exportOutput != "-" {
+			out, err = os.Create(exportOutput)
+			if err != nil {
+				fail("create output file", err)
+			}
+			defer out.Close()
+		}
+
+		buffered := bufio.NewWriter(out)
+		writer, err := userfile.NewWriter(buffered, format, columns)
+		if err != nil {
+			fail("export failed", err)
+		}
+
+		exported := 0
+		userUsecase := newUserUsecase(newContainer())
+		err = userUsecase.Stream(cliContext(), filter, func(users []entities.User) error {
+			for _, user := range users {
+				err := writer.Write(user)
+				if err != nil {
+					return err
+				}
+			}
+
+			exported += len(users)
+			return writer.Flush()
+		})
+		if err == nil {
+			err = writer.Close()
+		}
+		if err == nil {
+			err = buffered.Flush()
+		}
+		if err != nil {
+			fail("export failed", err)
+		}
+
+		if exportOutput != "-" {
+			fmt.Printf("Exported %d users to %s\n", exported, exportOutput)
+		}
+	},
+}
+
+// exportFormatFromPath guesses the export format from the output extension,
+// falling back to csv for stdout and unknown extensions.
+func exportFormatFromPath(path string) userfile.Format {
+	if strings.EqualFold(filepath.Ext(path), ".xlsx") {
+		return userfile.FormatXLSX
+	}
+
+	format, err := userfile.FormatFromPath(path)
+	if err != nil || format == userfile.FormatJSON {
+		return userfile.FormatCSV
+	}
+
+	return format
+}
+
+func init() {
+	usersCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "-", "File to write, - for stdout")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "Output format: csv, ndjson or xlsx (default from the output extension, else csv)")
+	exportCmd.Flags().StringVar(&exportColumns, "columns", "", "Comma separated columns to export (default all)")
+	exportCmd.Flags().StringVar(&exportName, "name", "", "Only users whose name contains this")
+	exportCmd.Flags().StringVar(&exportFamily, "family", "", "Only users whose family contains this")
+	exportCmd.Flags().StringVar(&exportEmail, "email", "", "Only users whose email contains this")
+	exportCmd.Flags().IntVar(&exportMinAge, "min-age", 0, "Only users at least this old")
+	exportCmd.Flags().IntVar(&exportMaxAge, "max-age", 0, "Only users at most this old")
+	exportCmd.Flags().BoolVar(&exportIncludeDeleted, "include-deleted", false, "Also export soft-deleted users")
+}
@@ -0,0 +1,218 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/request"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/userfile"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFile       string
+	importFormat     string
+	importDryRun     bool
+	importOnConflict string
+	importReportPath string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import users from a csv, json or ndjson file",
+	Long: `Imports users from --file. Every row is validated like POST /api/v1/user;
+invalid rows and emails repeated in the file are rejected, the rest is imported
+in one transaction. --on-conflict decides what happens to users whose email is
+already taken: skip them, update them, or fail the whole import. Rejected rows
+are written as JSON to --report.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		onConflict := entities.ConflictPolicy(importOnConflict)
+		if !onConflict.IsValid() {
+			fail("invalid --on-conflict", fmt.Errorf("want skip, update or fail, got %q", importOnConflict))
+		}
+
+		format := userfile.Format(importFormat)
+		if format == "" {
+			var err error
+			format, err = userfile.FormatFromPath(importFile)
+			if err != nil {
+				fail("unknown import format, pass --format", err)
+			}
+		}
+
+		file, err := os.Open(importFile)
+		if err != nil {
+			fail("open import file", err)
+		}
+		defer file.Close()
+
+		c := newContainer()
+		rows, users, report, err := readImportFile(file, format, c.Validate)
+		if err != nil {
+			fail("read import file", err)
+		}
+
+		userUsecase := newUserUsecase(c)
+		results, importErr := userUsecase.Import(cliContext(), users, entities.ImportOptions{
+			OnConflict: onConflict,
+			DryRun:     importDryRun,
+		})
+		if importErr != nil && !errors.Is(importErr, usecase.ErrMsgBulkAborted) {
+			fail("import failed", importErr)
+		}
+		report.add(rows, results)
+		report.DryRun = importDryRun
+		report.Aborted = importErr != nil
+
+		if importReportPath != "" {
+			err = report.write(importReportPath)
+			if err != nil {
+				fail("write report", err)
+			}
+		}
+
+		if report.Aborted {
+			fail("import aborted, nothing was imported", importErr)
+		}
+
+		prefix := "Processed"
+		if importDryRun {
+			prefix = "Dry run, processed"
+		}
+		fmt.Printf("%s %d rows: %d created, %d updated, %d skipped, %d rejected\n",
+			prefix, report.Total, report.Created, report.Updated, report.Skipped, len(report.Rejected))
+	},
+}
+
+func init() {
+	usersCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVarP(&importFile, "file", "f", "", "File to import")
+	importCmd.Flags().StringVar(&importFormat, "format", "", "File format: csv, json or ndjson (default from the file extension)")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Validate and run the import, then roll it back")
+	importCmd.Flags().StringVar(&importOnConflict, "on-conflict", string(entities.ConflictFail), "What to do with an already taken email: skip, update or fail")
+	importCmd.Flags().StringVar(&importReportPath, "report", "", "Write a JSON report of rejected rows to this file (- for stdout)")
+	_ = importCmd.MarkFlagRequired("file")
+}
+
+// importSummary is the machine-readable summary written by --report.
+type importSummary struct {
+	DryRun   bool             `json:"dry_run"`
+	Aborted  bool             `json:"aborted"`
+	Total    int              `json:"total"`
+	Created  int              `json:"created"`
+	Updated  int              `json:"updated"`
+	Skipped  int              `json:"skipped"`
+	Rejected []rejectedImport `json:"rejected"`
+}
+
+type rejectedImport struct {
+	Row    int    `json:"row"`
+	Email  string `json:"email,omitempty"`
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+}
+
+// Definition Import Reject Reasons
+const (
+	rejectInvalid   = "invalid"
+	rejectDuplicate = "duplicate"
+	rejectAborted   = "aborted"
+	rejectFailed    = "failed"
+)
+
+// readImportFile decodes and validates every record. It returns the valid
+// users with the row each came from and a report holding the rejected rows.
+func readImportFile(r io.Reader, format userfile.Format, validate *validator.Validate) ([]int, []entities.User, *importSummary, error) {
+	reader, err := userfile.NewReader(r, format)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	report := &importSummary{Rejected: []rejectedImport{}}
+	var rows []int
+	var users []entities.User
+	for {
+		record, row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		report.Total++
+
+		var recordErr *userfile.RecordError
+		if errors.As(err, &recordErr) {
+			report.Rejected = append(report.Rejected, rejectedImport{Row: row, Reason: rejectInvalid, Error: recordErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		req := request.CreateUserRequest{
+			Name:   record.Name,
+			Family: record.Family,
+			Email:  record.Email,
+			Age:    record.Age,
+		}
+		err = validate.Struct(req)
+		if err != nil {
+			report.Rejected = append(report.Rejected, rejectedImport{Row: row, Email: record.Email, Reason: rejectInvalid, Error: err.Error()})
+			continue
+		}
+
+		rows = append(rows, row)
+		users = append(users, entities.User{
+			Name:   req.Name,
+			Family: req.Family,
+			Email:  req.Email,
+			Age:    req.Age,
+		})
+	}
+
+	return rows, users, report, nil
+}
+
+// add counts the import results, rows[i] being the row of results[i].
+func (r *importSummary) add(rows []int, results []entities.ImportResult) {
+	for i, result := range results {
+		switch {
+		case result.Err == nil:
+			switch result.Outcome {
+			case entities.ImportCreated:
+				r.Created++
+			case entities.ImportUpdated:
+				r.Updated++
+			case entities.ImportSkipped:
+				r.Skipped++
+			}
+			continue
+		case errors.Is(result.Err, usecase.ErrMsgBulkRolledBack):
+			r.Rejected = append(r.Rejected, rejectedImport{Row: rows[i], Email: result.User.Email, Reason: rejectAborted, Error: result.Err.Error()})
+		case errors.Is(result.Err, usecase.ErrMsgDuplicateUser):
+			r.Rejected = append(r.Rejected, rejectedImport{Row: rows[i], Email: result.User.Email, Reason: rejectDuplicate, Error: result.Err.Error()})
+		default:
+			r.Rejected = append(r.Rejected, rejectedImport{Row: rows[i], Email: result.User.Email, Reason: rejectFailed, Error: result.Err.Error()})
+		}
+	}
+}
+
+func (r *importSummary) write(path string) error {
+	out := os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
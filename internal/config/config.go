@@ -8,20 +8,89 @@ import (
 	"github.com/kelseyhightower/envconfig"
 )
 
+// Definition Database Drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
+// Definition Mail Drivers
+const (
+	MailDriverLog  = "log"
+	MailDriverFile = "file"
+)
+
+// Definition Log Formats
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// Definition Auth Modes
+const (
+	AuthModeJWT    = "jwt"
+	AuthModeHeader = "header"
+)
+
 type Config struct {
 	Server   Server
 	Database Database
 	Redis    Redis
 	Swagger  Swagger
 	Goose    Goose
+	Auth     Auth
+	Mail     Mail
+	Log      Log
 }
 
 type Server struct {
-	Host         string        `envconfig:"SERVER_HOST" default:"127.0.0.1"`
-	Port         int           `envconfig:"SERVER_PORT" default:"6661"`
-	WriteTimeout time.Duration `envconfig:"SERVER_WITH_TIMEOUT" default:"10s"`
-	ReadTimeout  time.Duration `envconfig:"SERVER_READ_TIMEOUT" default:"10s"`
-	Debug        bool          `envconfig:"SERVER_DEBUG" default:"true"`
+	Host           string        `envconfig:"SERVER_HOST" default:"127.0.0.1"`
+	Port           int           `envconfig:"SERVER_PORT" default:"6661"`
+	WriteTimeout   time.Duration `envconfig:"SERVER_WITH_TIMEOUT" default:"10s"`
+	ReadTimeout    time.Duration `envconfig:"SERVER_READ_TIMEOUT" default:"10s"`
+	Debug          bool          `envconfig:"SERVER_DEBUG" default:"true"`
+	CursorSecret   string        `envconfig:"SERVER_CURSOR_SECRET"`
+	TrustedProxies []string      `envconfig:"SERVER_TRUSTED_PROXIES"`
+}
+
+// Auth configures how callers authenticate, see .env for each variable.
+type Auth struct {
+	Mode                 string        `envconfig:"AUTH_MODE" default:"jwt"`
+	TrustedHeader        string        `envconfig:"AUTH_TRUSTED_HEADER" default:"X-User-ID"`
+	JWTSecret            string        `envconfig:"AUTH_JWT_SECRET"`
+	JWTPreviousSecrets   []string      `envconfig:"AUTH_JWT_PREVIOUS_SECRETS"`
+	JWTIssuer            string        `envconfig:"AUTH_JWT_ISSUER" default:"user-manager"`
+	AccessTokenTTL       time.Duration `envconfig:"AUTH_ACCESS_TOKEN_TTL" default:"15m"`
+	RefreshTokenTTL      time.Duration `envconfig:"AUTH_REFRESH_TOKEN_TTL" default:"720h"`
+	MFAIssuer            string        `envconfig:"AUTH_MFA_ISSUER" default:"user-manager"`
+	MFAEncryptionKey     string        `envconfig:"AUTH_MFA_ENCRYPTION_KEY"`
+	MFASkew              int           `envconfig:"AUTH_MFA_SKEW" default:"1"`
+	EmailVerificationTTL time.Duration `envconfig:"AUTH_EMAIL_VERIFICATION_TTL" default:"24h"`
+	PasswordResetTTL     time.Duration `envconfig:"AUTH_PASSWORD_RESET_TTL" default:"1h"`
+	PasswordResetLimit   int           `envconfig:"AUTH_PASSWORD_RESET_LIMIT" default:"3"`
+	PasswordResetWindow  time.Duration `envconfig:"AUTH_PASSWORD_RESET_WINDOW" default:"1h"`
+	LockoutThreshold     int           `envconfig:"AUTH_LOCKOUT_THRESHOLD" default:"5"`
+	LockoutIPThreshold   int           `envconfig:"AUTH_LOCKOUT_IP_THRESHOLD" default:"20"`
+	LockoutWindow        time.Duration `envconfig:"AUTH_LOCKOUT_WINDOW" default:"15m"`
+	LockoutDuration      time.Duration `envconfig:"AUTH_LOCKOUT_DURATION" default:"1m"`
+	LockoutMaxDuration   time.Duration `envconfig:"AUTH_LOCKOUT_MAX_DURATION" default:"1h"`
+}
+
+// Mail configures mail to users, see .env for each variable.
+type Mail struct {
+	Driver      string `envconfig:"MAIL_DRIVER" default:"log"`
+	Dir         string `envconfig:"MAIL_DIR" default:"mail"`
+	From        string `envconfig:"MAIL_FROM" default:"no-reply@user-manager.local"`
+	LinkBaseURL string `envconfig:"MAIL_LINK_BASE_URL" default:"http://127.0.0.1:5000"`
+	MaxPending  int    `envconfig:"MAIL_MAX_PENDING" default:"32"`
+}
+
+// Log configures the logger of every command, see .env for each variable.
+type Log struct {
+	Level  string `envconfig:"LOG_LEVEL" default:"info"`
+	Format string `envconfig:"LOG_FORMAT" default:"json"`
+	Output string `envconfig:"LOG_OUTPUT" default:"stdout"`
 }
 
 type Database struct {
@@ -33,6 +102,7 @@ type Database struct {
 	SSLMode           string `envconfig:"DATABASE_SSLMODE" default:"disable"`
 	MaxOpenConnection int    `envconfig:"DATABASE_MAX_OPEN_CONNECTION" default:"100"`
 	Driver            string `envconfig:"DATABASE_DRIVER" default:"postgres"`
+	Path              string `envconfig:"DATABASE_PATH" default:"user_manager.db"`
 }
 
 type Redis struct {
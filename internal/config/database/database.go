@@ -0,0 +1,79 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/alirezaghasemi/user-manager/internal/config"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type Database struct {
+	DB  *gorm.DB
+	cfg config.Config
+}
+
+// NewDatabaseConnection opens the configured database, its queries logged
+// to logger.
+func NewDatabaseConnection(cfg config.Config, logger gormlogger.Interface) (*Database, error) {
+	dialector, err := Dialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// TranslateError maps driver specific errors such as unique violations to
+	// gorm.ErrDuplicatedKey so repositories stay driver neutral
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true, Logger: logger})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Database.Driver == config.DriverSQLite {
+		// SQLite allows a single writer, queueing on one connection avoids SQLITE_BUSY
+		sqlDB.SetMaxOpenConns(1)
+	} else if cfg.Database.MaxOpenConnection > 0 {
+		sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConnection)
+	}
+
+	if err := sqlDB.Ping(); err != nil {
+		return nil, fmt.Errorf("cannot connect to database: %w", err)
+	}
+
+	return &Database{
+		DB:  db,
+		cfg: cfg,
+	}, nil
+}
+
+// Dialector returns the GORM dialector for the configured driver.
+func Dialector(cfg config.Config) (gorm.Dialector, error) {
+	switch cfg.Database.Driver {
+	case config.DriverPostgres, "":
+		return postgres.Open(PostgresDSN(cfg)), nil
+	case config.DriverSQLite:
+		return sqlite.Open(SQLiteDSN(cfg)), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Database.Driver)
+	}
+}
+
+func PostgresDSN(cfg config.Config) string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Database.Host, cfg.Database.Port, cfg.Database.Username, cfg.Database.Password, cfg.Database.Name, cfg.Database.SSLMode)
+}
+
+// SQLiteDSN opens the database file with foreign keys on and a busy timeout.
+func SQLiteDSN(cfg config.Config) string {
+	return fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", cfg.Database.Path)
+}
+
+func (d *Database) Connection() *gorm.DB {
+	return d.DB
+}
@@ -1,29 +1,180 @@
 package container
 
 import (
+	"fmt"
+	"log/slog"
+
 	"github.com/alirezaghasemi/user-manager/internal/config"
 	"github.com/alirezaghasemi/user-manager/internal/config/database"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/logger"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/mailer"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/metrics"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/secretbox"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/token"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
 )
 
 type Container struct {
-	DB       *gorm.DB
-	Validate *validator.Validate
+	Config     config.Config
+	DB         *gorm.DB
+	Validate   *validator.Validate
+	Translator *validation.Translator
+	Clock      clock.Clock
+	Logger     *slog.Logger
+	Metrics    *metrics.Metrics
 }
 
-func NewContainer(cfg config.Config) *Container {
-	// Database
-	db, err := database.NewDatabaseConnection(cfg)
+// NewContainer wires the dependencies cfg configures. They log to log, which
+// the command builds from cfg.Log before there is a container.
+func NewContainer(cfg config.Config, log *slog.Logger) *Container {
+	// Metrics, on a registry of their own that /metrics serves
+	stats := metrics.NewDefault()
+
+	// Database, the memory driver keeps everything in process and needs none
+	var conn *gorm.DB
+	if cfg.Database.Driver != config.DriverMemory {
+		db, err := database.NewDatabaseConnection(cfg, logger.NewGormLogger(log))
+		if err != nil {
+			panic(err)
+		}
+		conn = db.Connection()
+
+		sqlDB, err := conn.DB()
+		if err != nil {
+			panic(err)
+		}
+		err = stats.WatchDB(cfg.Database.Driver, sqlDB)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// Validator, naming fields like the requests do, with translated messages
+	validate := validation.New()
+	translator, err := validation.NewTranslator(validate)
 	if err != nil {
 		panic(err)
 	}
 
-	// Validator
-	validate := validator.New()
-
 	return &Container{
-		DB:       db.Connection(),
-		Validate: validate,
+		Config:     cfg,
+		DB:         conn,
+		Validate:   validate,
+		Translator: translator,
+		Clock:      clock.New(),
+		Logger:     log,
+		Metrics:    stats,
+	}
+}
+
+// UserRepository returns the user repository backing the configured driver.
+func (c *Container) UserRepository() repository.UserRepository {
+	if c.Config.Database.Driver == config.DriverMemory {
+		return repository.NewMemoryUserRepository(c.Clock)
+	}
+
+	return repository.NewUserRepository(c.DB, c.Clock)
+}
+
+// SessionRepository returns the session repository backing the configured driver.
+func (c *Container) SessionRepository() repository.SessionRepository {
+	if c.Config.Database.Driver == config.DriverMemory {
+		return repository.NewMemorySessionRepository(c.Clock)
+	}
+
+	return repository.NewSessionRepository(c.DB, c.Clock)
+}
+
+// RoleRepository returns the role repository backing the configured driver.
+func (c *Container) RoleRepository() repository.RoleRepository {
+	if c.Config.Database.Driver == config.DriverMemory {
+		return repository.NewMemoryRoleRepository(c.Clock)
+	}
+
+	return repository.NewRoleRepository(c.DB, c.Clock)
+}
+
+// APIKeyRepository returns the API key repository backing the configured driver.
+func (c *Container) APIKeyRepository() repository.APIKeyRepository {
+	if c.Config.Database.Driver == config.DriverMemory {
+		return repository.NewMemoryAPIKeyRepository(c.Clock)
+	}
+
+	return repository.NewAPIKeyRepository(c.DB, c.Clock)
+}
+
+// MFARepository returns the MFA repository backing the configured driver.
+func (c *Container) MFARepository() repository.MFARepository {
+	if c.Config.Database.Driver == config.DriverMemory {
+		return repository.NewMemoryMFARepository(c.Clock)
+	}
+
+	return repository.NewMFARepository(c.DB, c.Clock)
+}
+
+// OneTimeTokenRepository returns the one-time token repository backing the configured driver.
+func (c *Container) OneTimeTokenRepository() repository.OneTimeTokenRepository {
+	if c.Config.Database.Driver == config.DriverMemory {
+		return repository.NewMemoryOneTimeTokenRepository(c.Clock)
+	}
+
+	return repository.NewOneTimeTokenRepository(c.DB, c.Clock)
+}
+
+// LockoutRepository returns the lockout repository backing the configured driver.
+func (c *Container) LockoutRepository() repository.LockoutRepository {
+	if c.Config.Database.Driver == config.DriverMemory {
+		return repository.NewMemoryLockoutRepository(c.Clock)
+	}
+
+	return repository.NewLockoutRepository(c.DB, c.Clock)
+}
+
+// AuditRepository returns the audit repository backing the configured driver.
+func (c *Container) AuditRepository() repository.AuditRepository {
+	if c.Config.Database.Driver == config.DriverMemory {
+		return repository.NewMemoryAuditRepository(c.Clock)
+	}
+
+	return repository.NewAuditRepository(c.DB, c.Clock)
+}
+
+// TxManager returns the transaction manager matching the configured driver.
+func (c *Container) TxManager() repository.TxManager {
+	if c.Config.Database.Driver == config.DriverMemory {
+		return repository.NewMemoryTxManager()
+	}
+
+	return repository.NewTxManager(c.DB)
+}
+
+// TokenManager returns the access token manager configured by cfg.Auth.
+func (c *Container) TokenManager() token.Manager {
+	return token.NewManager(token.Options{
+		Secret:          c.Config.Auth.JWTSecret,
+		PreviousSecrets: c.Config.Auth.JWTPreviousSecrets,
+		Issuer:          c.Config.Auth.JWTIssuer,
+		TTL:             c.Config.Auth.AccessTokenTTL,
+	}, c.Clock)
+}
+
+// SecretBox returns the box sealing TOTP secrets, keyed by cfg.Auth.
+func (c *Container) SecretBox() *secretbox.Box {
+	return secretbox.NewBox(c.Config.Auth.MFAEncryptionKey)
+}
+
+// Mailer returns the mailer picked by cfg.Mail.Driver.
+func (c *Container) Mailer() (mailer.Mailer, error) {
+	switch c.Config.Mail.Driver {
+	case config.MailDriverLog:
+		return mailer.NewLogMailer(c.Logger, c.Config.Mail.From), nil
+	case config.MailDriverFile:
+		return mailer.NewFileMailer(c.Config.Mail.Dir, c.Config.Mail.From, c.Clock)
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q, use %s or %s", c.Config.Mail.Driver, config.MailDriverLog, config.MailDriverFile)
 	}
 }
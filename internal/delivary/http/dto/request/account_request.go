@@ -0,0 +1,14 @@
+package request
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required,max=255"`
+}
+
+type PasswordResetRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ConfirmPasswordResetRequest struct {
+	Token    string `json:"token" validate:"required,max=255"`
+	Password string `json:"password" validate:"required,min=8,max_bytes=72"`
+}
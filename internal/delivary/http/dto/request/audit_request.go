@@ -0,0 +1,15 @@
+package request
+
+import "time"
+
+// ListAuditRequest is bound from the query string. From and To are RFC 3339
+// times, Cursor is the next_cursor of the previous page.
+type ListAuditRequest struct {
+	UserID *uint64    `form:"user_id" validate:"omitempty,gte=1"`
+	Actor  string     `form:"actor" validate:"omitempty,max=255"`
+	Action string     `form:"action" validate:"omitempty,oneof=user.created user.updated user.deleted user.restored user.mfa_enrolled user.mfa_enabled user.mfa_disabled"`
+	From   *time.Time `form:"from"`
+	To     *time.Time `form:"to"`
+	Cursor uint64     `form:"cursor"`
+	Limit  int        `form:"limit" validate:"omitempty,gte=1,lte=200"`
+}
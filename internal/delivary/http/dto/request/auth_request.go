@@ -0,0 +1,18 @@
+package request
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,max_bytes=72"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=255"`
+}
+
+// VerifyPasswordRequest carries the credentials a service checks, ClientIP
+// being the address of the user it acts for.
+type VerifyPasswordRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,max_bytes=72"`
+	ClientIP string `json:"client_ip" validate:"omitempty,ip"`
+}
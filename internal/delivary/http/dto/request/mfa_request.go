@@ -0,0 +1,12 @@
+package request
+
+// MFACodeRequest carries a TOTP code or, when verifying, a recovery code.
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// DisableMFARequest carries a TOTP or recovery code, which users need when
+// they turn off their own enabled second factor. The body may be left out.
+type DisableMFARequest struct {
+	Code string `json:"code" validate:"omitempty,max=32"`
+}
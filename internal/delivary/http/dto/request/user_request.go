@@ -5,6 +5,8 @@ type CreateUserRequest struct {
 	Family string `json:"family" validate:"required,min=2,max=30"`
 	Email  string `json:"email" validate:"required,email"`
 	Age    int    `json:"age" validate:"required,gte=18,lte=120"`
+	// Password is optional, users created without one can't log in.
+	Password string `json:"password,omitempty" validate:"omitempty,min=8,max_bytes=72"`
 }
 
 type UpdateUserRequest struct {
@@ -16,3 +18,61 @@ type UpdateUserRequest struct {
 
 type DeleteUserRequest struct {
 }
+
+// FindAllUserRequest is bound from the query string. Either page/per_page or
+// limit/offset may be used; limit/offset wins when both are sent.
+type FindAllUserRequest struct {
+	Page    int    `form:"page" validate:"omitempty,gte=1"`
+	PerPage int    `form:"per_page" validate:"omitempty,gte=1,lte=100"`
+	Limit   *int   `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset  *int   `form:"offset" validate:"omitempty,gte=0"`
+	Name    string `form:"name" validate:"omitempty,max=30"`
+	Family  string `form:"family" validate:"omitempty,max=30"`
+	Email   string `form:"email" validate:"omitempty,max=255"`
+	MinAge  *int   `form:"min_age" validate:"omitempty,gte=0,lte=120"`
+	MaxAge  *int   `form:"max_age" validate:"omitempty,gte=0,lte=120"`
+	Sort    string `form:"sort" validate:"omitempty,max=255"`
+
+	IncludeDeleted bool `form:"include_deleted"`
+}
+
+// MaxBulkPasswords caps the users of a BulkCreateUserRequest sent with a
+// password. Each takes bcrypt about 80ms, so a thousand would outlast the
+// write timeout of the server even hashed in parallel.
+const MaxBulkPasswords = 100
+
+// BulkCreateUserRequest creates many users at once. Mode is "atomic" (the
+// default) or "best_effort"; each user is validated like CreateUserRequest.
+// At most MaxBulkPasswords of them may have a password.
+type BulkCreateUserRequest struct {
+	Mode  string              `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	Users []CreateUserRequest `json:"users" validate:"required,min=1,max=1000"`
+}
+
+type BulkUpdateUserItem struct {
+	ID      uint64 `json:"id" validate:"required"`
+	Version *int   `json:"version,omitempty" validate:"omitempty,gte=1"`
+	UpdateUserRequest
+}
+
+type BulkUpdateUserRequest struct {
+	Mode  string               `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	Users []BulkUpdateUserItem `json:"users" validate:"required,min=1,max=1000"`
+}
+
+type BulkDeleteUserRequest struct {
+	Mode string   `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	IDs  []uint64 `json:"ids" validate:"required,min=1,max=1000,unique"`
+}
+
+// ExportUserRequest is bound from the query string of an export. Columns is a
+// comma separated list of the columns to include, all of them when empty.
+type ExportUserRequest struct {
+	Format  string `form:"format" validate:"omitempty,oneof=csv ndjson xlsx"`
+	Columns string `form:"columns" validate:"omitempty,max=255"`
+	Name    string `form:"name" validate:"omitempty,max=30"`
+	Family  string `form:"family" validate:"omitempty,max=30"`
+	Email   string `form:"email" validate:"omitempty,max=255"`
+	MinAge  *int   `form:"min_age" validate:"omitempty,gte=0,lte=120"`
+	MaxAge  *int   `form:"max_age" validate:"omitempty,gte=0,lte=120"`
+}
@@ -0,0 +1,17 @@
+package response
+
+import (
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+)
+
+type AuditEventResponse struct {
+	ID        uint64                 `json:"id"`
+	Actor     string                 `json:"actor"`
+	Action    string                 `json:"action"`
+	UserID    uint64                 `json:"user_id"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Changes   []entities.AuditChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at"`
+}
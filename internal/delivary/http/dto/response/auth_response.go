@@ -0,0 +1,42 @@
+package response
+
+import "time"
+
+type LoginResponse struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresIn        int       `json:"expires_in"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type LogoutAllResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+// SessionResponse is one open session, Current marking the one the request
+// was made with.
+type SessionResponse struct {
+	ID         uint64    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type VerifyPasswordResponse struct {
+	Valid  bool   `json:"valid"`
+	UserID uint64 `json:"user_id"`
+}
+
+// LockStatusResponse is the lockout of a user, FailedAttempts counting the
+// failures within the current window.
+type LockStatusResponse struct {
+	Locked         bool       `json:"locked"`
+	LockedUntil    *time.Time `json:"locked_until"`
+	FailedAttempts int        `json:"failed_attempts"`
+	Lockouts       int        `json:"lockouts"`
+}
@@ -0,0 +1,28 @@
+package response
+
+import "time"
+
+// MFAEnrollmentResponse is shown once, for the user to add the secret to an
+// authenticator app, either by scanning the URI as QR code or typing the
+// secret.
+type MFAEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFAActivationResponse carries the recovery codes, shown this one time.
+type MFAActivationResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAStatusResponse struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"`
+}
+
+// MFAVerifyResponse tells whether a TOTP code or a recovery code passed.
+type MFAVerifyResponse struct {
+	Valid  bool   `json:"valid"`
+	Method string `json:"method"`
+}
@@ -0,0 +1,14 @@
+package response
+
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UserRolesResponse lists the roles assigned to a user. Every user also
+// holds the implicit "user" role, which is not listed.
+type UserRolesResponse struct {
+	UserID uint64         `json:"user_id"`
+	Roles  []RoleResponse `json:"roles"`
+}
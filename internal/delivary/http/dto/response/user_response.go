@@ -1,19 +1,33 @@
 package response
 
+import "time"
+
 type CreatedUserResponse struct {
-	ID     uint64 `json:"id"`
-	Name   string `json:"name"`
-	Family string `json:"family"`
-	Email  string `json:"email"`
-	Age    int    `json:"age"`
+	ID              uint64     `json:"id"`
+	Name            string     `json:"name"`
+	Family          string     `json:"family"`
+	Email           string     `json:"email"`
+	Age             int        `json:"age"`
+	Version         int        `json:"version"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CreatedBy       string     `json:"created_by"`
+	UpdatedBy       string     `json:"updated_by"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
 }
 
 type UpdatedUserResponse struct {
-	ID     uint64 `json:"id"`
-	Name   string `json:"name"`
-	Family string `json:"family"`
-	Email  string `json:"email"`
-	Age    int    `json:"age"`
+	ID              uint64     `json:"id"`
+	Name            string     `json:"name"`
+	Family          string     `json:"family"`
+	Email           string     `json:"email"`
+	Age             int        `json:"age"`
+	Version         int        `json:"version"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CreatedBy       string     `json:"created_by"`
+	UpdatedBy       string     `json:"updated_by"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
 }
 
 type DeletedUserResponse struct {
@@ -21,17 +35,60 @@ type DeletedUserResponse struct {
 }
 
 type FindUserByIDResponse struct {
-	ID     uint64 `json:"id"`
-	Name   string `json:"name"`
-	Family string `json:"family"`
-	Email  string `json:"email"`
-	Age    int    `json:"age"`
+	ID              uint64     `json:"id"`
+	Name            string     `json:"name"`
+	Family          string     `json:"family"`
+	Email           string     `json:"email"`
+	Age             int        `json:"age"`
+	Version         int        `json:"version"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CreatedBy       string     `json:"created_by"`
+	UpdatedBy       string     `json:"updated_by"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
 }
 
 type FindAllUserResponse struct {
-	ID     uint64 `json:"id"`
-	Name   string `json:"name"`
-	Family string `json:"family"`
-	Email  string `json:"email"`
-	Age    int    `json:"age"`
+	ID              uint64     `json:"id"`
+	Name            string     `json:"name"`
+	Family          string     `json:"family"`
+	Email           string     `json:"email"`
+	Age             int        `json:"age"`
+	Version         int        `json:"version"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CreatedBy       string     `json:"created_by"`
+	UpdatedBy       string     `json:"updated_by"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
+
+type RestoredUserResponse struct {
+	ID              uint64     `json:"id"`
+	Name            string     `json:"name"`
+	Family          string     `json:"family"`
+	Email           string     `json:"email"`
+	Age             int        `json:"age"`
+	Version         int        `json:"version"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CreatedBy       string     `json:"created_by"`
+	UpdatedBy       string     `json:"updated_by"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
+
+// BulkUserResult is the outcome of the item at Index of a bulk request.
+type BulkUserResult struct {
+	Index   int    `json:"index"`
+	Status  string `json:"status"`
+	ID      uint64 `json:"id,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type BulkUserResponse struct {
+	Mode      string           `json:"mode"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkUserResult `json:"results"`
 }
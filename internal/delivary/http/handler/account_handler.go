@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/request"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Definition Error
+var (
+	ErrMsgInvalidOneTimeToken  = errors.New("invalid or expired token")
+	ErrMsgEmailAlreadyVerified = errors.New("email is already verified")
+
+	SuccessMsgVerificationSent  = "Verification Mail Sent"
+	SuccessMsgEmailVerified     = "Email Verified Successfully"
+	SuccessMsgPasswordResetSent = "If The Email Has An Account, A Reset Link Was Sent"
+	SuccessMsgPasswordReset     = "Password Reset Successfully"
+)
+
+// Definition Struct (Class)
+type AccountHandler struct {
+	usecase  usecase.AccountUsecase
+	validate *validator.Validate
+}
+
+// Definition Constructor
+func NewAccountHandler(usecase usecase.AccountUsecase, validate *validator.Validate) *AccountHandler {
+	return &AccountHandler{
+		usecase:  usecase,
+		validate: validate,
+	}
+}
+
+// Definition Implement Methods (RequestEmailVerification, VerifyEmail, RequestPasswordReset, ResetPassword)
+func (h *AccountHandler) RequestEmailVerification(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return
+	}
+
+	err = h.usecase.RequestEmailVerification(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, httpresponse.Success(SuccessMsgVerificationSent, nil))
+}
+
+// Definition Implement Methods (RequestEmailVerification, VerifyEmail, RequestPasswordReset, ResetPassword)
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var req request.VerifyEmailRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	err := h.usecase.VerifyEmail(c, req.Token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgEmailVerified, nil))
+}
+
+// Definition Implement Methods (RequestEmailVerification, VerifyEmail, RequestPasswordReset, ResetPassword)
+// RequestPasswordReset answers the same whether the email has an account or
+// not. The mail is sent after answering, so the usecase gets the context of
+// the request rather than c, which gin reuses once the handler returned.
+func (h *AccountHandler) RequestPasswordReset(c *gin.Context) {
+	var req request.PasswordResetRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	err := h.usecase.RequestPasswordReset(c.Request.Context(), req.Email)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, httpresponse.Success(SuccessMsgPasswordResetSent, nil))
+}
+
+// Definition Implement Methods (RequestEmailVerification, VerifyEmail, RequestPasswordReset, ResetPassword)
+// ResetPassword hands the usecase the context of the request, like
+// RequestPasswordReset, for the mail it sends after answering.
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var req request.ConfirmPasswordResetRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	err := h.usecase.ResetPassword(c.Request.Context(), req.Token, req.Password)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgPasswordReset, nil))
+}
+
+// bind reads and validates the JSON body into req, or responds with the error
+// and returns false.
+func (h *AccountHandler) bind(c *gin.Context, req interface{}) bool {
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidBody, err))
+		return false
+	}
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return false
+	}
+
+	return true
+}
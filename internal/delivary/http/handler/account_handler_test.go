@@ -0,0 +1,172 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAccountUsecase) RequestEmailVerification(ctx context.Context, userID uint64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAccountUsecase) VerifyEmail(ctx context.Context, rawToken string) error {
+	args := m.Called(ctx, rawToken)
+	return args.Error(0)
+}
+
+func (m *MockAccountUsecase) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockAccountUsecase) ResetPassword(ctx context.Context, rawToken string, newPassword string) error {
+	args := m.Called(ctx, rawToken, newPassword)
+	return args.Error(0)
+}
+
+func TestAccountHandler_RequestEmailVerification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockAccountUsecase{}
+
+	accountHandler := handler.NewAccountHandler(mockUsecase, validation.New())
+
+	setupGinContext := func(t *testing.T, id string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/user/"+id+"/email/verification", nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase.On("RequestEmailVerification", mock.Anything, uint64(7)).Return(nil).Once()
+
+		c, w := setupGinContext(t, "7")
+
+		accountHandler.RequestEmailVerification(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("AlreadyVerified", func(t *testing.T) {
+		mockUsecase.On("RequestEmailVerification", mock.Anything, uint64(7)).Return(usecase.ErrMsgEmailAlreadyVerified).Once()
+
+		c, w := setupGinContext(t, "7")
+
+		accountHandler.RequestEmailVerification(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase.On("RequestEmailVerification", mock.Anything, uint64(8)).Return(usecase.ErrMsgForbidden).Once()
+
+		c, w := setupGinContext(t, "8")
+
+		accountHandler.RequestEmailVerification(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		c, w := setupGinContext(t, "abc")
+
+		accountHandler.RequestEmailVerification(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAccountHandler_ResetPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockAccountUsecase{}
+
+	accountHandler := handler.NewAccountHandler(mockUsecase, validation.New())
+
+	setupGinContext := func(t *testing.T, path string, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c, w
+	}
+
+	t.Run("Request", func(t *testing.T) {
+		mockUsecase.On("RequestPasswordReset", mock.Anything, "ali@gmail.com").Return(nil).Once()
+
+		c, w := setupGinContext(t, "/auth/password-reset", `{"email":"ali@gmail.com"}`)
+
+		accountHandler.RequestPasswordReset(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase.On("ResetPassword", mock.Anything, "raw-token", "new password").Return(nil).Once()
+
+		c, w := setupGinContext(t, "/auth/password-reset/confirm", `{"token":"raw-token","password":"new password"}`)
+
+		accountHandler.ResetPassword(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		mockUsecase.On("ResetPassword", mock.Anything, "raw-token", "new password").Return(usecase.ErrMsgInvalidOneTimeToken).Once()
+
+		c, w := setupGinContext(t, "/auth/password-reset/confirm", `{"token":"raw-token","password":"new password"}`)
+
+		accountHandler.ResetPassword(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var res httpresponse.Problem
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, handler.CodeInvalidOneTimeToken, res.Code)
+		assert.Empty(t, res.Detail)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("ShortPassword", func(t *testing.T) {
+		c, w := setupGinContext(t, "/auth/password-reset/confirm", `{"token":"raw-token","password":"short"}`)
+
+		accountHandler.ResetPassword(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("InternalError", func(t *testing.T) {
+		mockUsecase.On("VerifyEmail", mock.Anything, "raw-token").Return(errors.New("db down")).Once()
+
+		c, w := setupGinContext(t, "/auth/verify-email", `{"token":"raw-token"}`)
+
+		accountHandler.VerifyEmail(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/request"
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/response"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Definition Error
+var (
+	SuccessMsgFoundAuditEvents = "found audit events successfully"
+)
+
+// Definition Struct (Class)
+type AuditHandler struct {
+	usecase  usecase.AuditUsecase
+	validate *validator.Validate
+}
+
+// Definition Constructor
+func NewAuditHandler(usecase usecase.AuditUsecase, validate *validator.Validate) *AuditHandler {
+	return &AuditHandler{
+		usecase:  usecase,
+		validate: validate,
+	}
+}
+
+// Definition Implement Methods (List)
+// List pages through the audit log newest first, following next_cursor.
+func (h *AuditHandler) List(c *gin.Context) {
+	var req request.ListAuditRequest
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidQuery, err))
+		return
+	}
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return
+	}
+
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		respondError(c, fmt.Errorf("%w: from must be before to", ErrMsgValidation))
+		return
+	}
+
+	query := entities.AuditQuery{
+		UserID:   req.UserID,
+		Actor:    req.Actor,
+		Action:   entities.AuditAction(req.Action),
+		From:     req.From,
+		To:       req.To,
+		BeforeID: req.Cursor,
+		Limit:    req.Limit,
+	}.Normalize()
+
+	page, err := h.usecase.List(c, query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res := make([]response.AuditEventResponse, len(page.Events))
+	for i, event := range page.Events {
+		res[i] = response.AuditEventResponse{
+			ID:        event.ID,
+			Actor:     event.Actor,
+			Action:    string(event.Action),
+			UserID:    event.UserID,
+			RequestID: event.RequestID,
+			Changes:   event.Changes,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+
+	meta := httpresponse.Cursor{PerPage: query.Limit, HasMore: page.NextBeforeID != 0}
+	if page.NextBeforeID != 0 {
+		meta.NextCursor = strconv.FormatUint(page.NextBeforeID, 10)
+	}
+
+	c.JSON(http.StatusOK, httpresponse.CursorPaginated(SuccessMsgFoundAuditEvents, res, meta))
+}
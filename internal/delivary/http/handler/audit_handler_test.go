@@ -0,0 +1,120 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAuditUsecase) List(ctx context.Context, query entities.AuditQuery) (entities.AuditPage, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(entities.AuditPage), args.Error(1)
+}
+
+func TestAuditHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockAuditUsecase{}
+
+	auditHandler := handler.NewAuditHandler(mockUsecase, validation.New())
+
+	setupGinContext := func(t *testing.T, query string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/audit?"+query, nil)
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		userID := uint64(7)
+		from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		mockUsecase.On("List", mock.Anything, entities.AuditQuery{
+			UserID:   &userID,
+			Actor:    "admin",
+			Action:   entities.AuditUserUpdated,
+			From:     &from,
+			BeforeID: 40,
+			Limit:    2,
+		}).Return(entities.AuditPage{
+			Events: []entities.AuditEvent{
+				{ID: 39, Actor: "admin", Action: entities.AuditUserUpdated, UserID: 7, RequestID: "req-1", Changes: []entities.AuditChange{{Field: "age", Before: 30, After: 31}}},
+				{ID: 35, Actor: "admin", Action: entities.AuditUserUpdated, UserID: 7},
+			},
+			NextBeforeID: 35,
+		}, nil).Once()
+
+		c, w := setupGinContext(t, "user_id=7&actor=admin&action=user.updated&from=2026-10-01T00:00:00Z&cursor=40&limit=2")
+
+		auditHandler.List(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res struct {
+			Data []struct {
+				ID        uint64                 `json:"id"`
+				RequestID string                 `json:"request_id"`
+				Changes   []entities.AuditChange `json:"changes"`
+			} `json:"data"`
+			Cursor struct {
+				NextCursor string `json:"next_cursor"`
+				HasMore    bool   `json:"has_more"`
+			} `json:"cursor"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Len(t, res.Data, 2)
+		assert.Equal(t, "req-1", res.Data[0].RequestID)
+		assert.Equal(t, "age", res.Data[0].Changes[0].Field)
+		assert.Equal(t, "35", res.Cursor.NextCursor)
+		assert.True(t, res.Cursor.HasMore)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("UnknownAction", func(t *testing.T) {
+		c, w := setupGinContext(t, "action=user.viewed")
+
+		auditHandler.List(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("InvalidTime", func(t *testing.T) {
+		c, w := setupGinContext(t, "from=yesterday")
+
+		auditHandler.List(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("EmptyRange", func(t *testing.T) {
+		c, w := setupGinContext(t, "from=2026-10-02T00:00:00Z&to=2026-10-01T00:00:00Z")
+
+		auditHandler.List(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase.On("List", mock.Anything, mock.Anything).Return(entities.AuditPage{}, usecase.ErrMsgForbidden).Once()
+
+		c, w := setupGinContext(t, "")
+
+		auditHandler.List(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
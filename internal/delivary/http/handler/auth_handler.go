@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/request"
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/response"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Definition Error
+var (
+	ErrMsgInvalidCredentials  = errors.New("invalid email or password")
+	ErrMsgInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrMsgRefreshTokenReused  = errors.New("refresh token was reused, the session is revoked")
+	ErrMsgSessionNotFound     = errors.New("session not found")
+	ErrMsgUnauthenticated     = errors.New("authentication required")
+	ErrMsgForbidden           = errors.New("forbidden")
+	ErrMsgTooManyAttempts     = errors.New("too many failed attempts, try again later")
+	ErrMsgPasswordNotVerified = errors.New("email and password do not match")
+	ErrMsgNoSession           = errors.New("the credential of the request has no session")
+
+	SuccessMsgLoggedIn      = "Logged In Successfully"
+	SuccessMsgRefreshed     = "Token Refreshed Successfully"
+	SuccessMsgLoggedOut     = "Logged Out Successfully"
+	SuccessMsgLoggedOutAll  = "Logged Out Of All Sessions Successfully"
+	SuccessMsgFoundSessions = "found sessions successfully"
+	SuccessMsgPasswordValid = "Password Verified Successfully"
+	SuccessMsgFoundLock     = "found lock status successfully"
+	SuccessMsgUnlocked      = "User Unlocked Successfully"
+)
+
+// Definition Struct (Class)
+type AuthHandler struct {
+	usecase  usecase.AuthUsecase
+	validate *validator.Validate
+}
+
+// Definition Constructor
+func NewAuthHandler(usecase usecase.AuthUsecase, validate *validator.Validate) *AuthHandler {
+	return &AuthHandler{
+		usecase:  usecase,
+		validate: validate,
+	}
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req request.LoginRequest
+
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidBody, err))
+		return
+	}
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return
+	}
+
+	issued, err := h.usecase.Login(c, req.Email, req.Password, entities.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgLoggedIn, loginResponse(issued)))
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req request.RefreshRequest
+
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidBody, err))
+		return
+	}
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return
+	}
+
+	issued, err := h.usecase.Refresh(c, req.RefreshToken)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgRefreshed, loginResponse(issued)))
+}
+
+// loginResponse maps an issued token pair to its response.
+func loginResponse(issued entities.AuthToken) response.LoginResponse {
+	return response.LoginResponse{
+		AccessToken:      issued.AccessToken,
+		TokenType:        issued.TokenType,
+		ExpiresIn:        int(issued.ExpiresIn.Seconds()),
+		ExpiresAt:        issued.ExpiresAt,
+		RefreshToken:     issued.RefreshToken,
+		RefreshExpiresAt: issued.RefreshExpiresAt,
+	}
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+// Logout revokes the session the access token of the request belongs to.
+// API keys and users authenticated by a proxy have none to revoke.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	caller, ok := principal.FromContext(c.Request.Context())
+	if !ok {
+		respondError(c, usecase.ErrMsgUnauthenticated)
+		return
+	}
+
+	if caller.SessionID == 0 {
+		respondError(c, invalid(ErrMsgNoSession, errors.New("only access tokens from a login belong to a session, revoke an api key instead or log out at the proxy")))
+		return
+	}
+
+	err := h.usecase.Logout(c, caller.SessionID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgLoggedOut, nil))
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+// LogoutAll revokes every session of the caller, this one included. API
+// keys belong to no user and so have no sessions.
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	caller, ok := principal.FromContext(c.Request.Context())
+	if !ok {
+		respondError(c, usecase.ErrMsgUnauthenticated)
+		return
+	}
+
+	if caller.UserID == 0 {
+		respondError(c, invalid(ErrMsgNoSession, errors.New("only users have sessions, revoke an api key instead")))
+		return
+	}
+
+	revoked, err := h.usecase.LogoutAll(c, caller.UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgLoggedOutAll, response.LogoutAllResponse{Revoked: revoked}))
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+// Sessions lists the open sessions of the user in the path.
+func (h *AuthHandler) Sessions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return
+	}
+
+	sessions, err := h.usecase.Sessions(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	caller, _ := principal.FromContext(c.Request.Context())
+	res := make([]response.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		res[i] = response.SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == caller.SessionID,
+		}
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgFoundSessions, res))
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+// VerifyPassword checks credentials for a trusted service. The client IP the
+// failures are counted against is the one in the body, the service relays it
+// for the user, or the address of the request when the body has none.
+func (h *AuthHandler) VerifyPassword(c *gin.Context) {
+	var req request.VerifyPasswordRequest
+
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidBody, err))
+		return
+	}
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return
+	}
+
+	clientIP := req.ClientIP
+	if clientIP == "" {
+		clientIP = c.ClientIP()
+	}
+
+	user, err := h.usecase.VerifyPassword(c, req.Email, req.Password, clientIP)
+	if err != nil {
+		// a 401 would read as the calling service failing to authenticate
+		if errors.Is(err, usecase.ErrMsgInvalidCredentials) {
+			err = ErrMsgPasswordNotVerified
+		}
+
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgPasswordValid, response.VerifyPasswordResponse{Valid: true, UserID: user.ID}))
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+func (h *AuthHandler) LockStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return
+	}
+
+	status, err := h.usecase.LockStatus(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgFoundLock, response.LockStatusResponse{
+		Locked:         status.Locked,
+		LockedUntil:    status.LockedUntil,
+		FailedAttempts: status.FailedAttempts,
+		Lockouts:       status.Lockouts,
+	}))
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+func (h *AuthHandler) Unlock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return
+	}
+
+	err = h.usecase.Unlock(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgUnlocked, nil))
+}
+
+// streamAuthzError answers an authentication or authorization error for
+// handlers that set the headers of a streamed body before calling the
+// usecase. It only responds while nothing of the body was written, dropping
+// those headers first, and reports whether it did.
+func streamAuthzError(c *gin.Context, err error) bool {
+	if c.Writer.Written() || !(errors.Is(err, usecase.ErrMsgUnauthenticated) || errors.Is(err, usecase.ErrMsgForbidden)) {
+		return false
+	}
+
+	c.Writer.Header().Del("Content-Disposition")
+	c.Writer.Header().Del("Content-Type")
+	respondError(c, err)
+	return true
+}
@@ -0,0 +1,520 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAuthUsecase) Login(ctx context.Context, email string, password string, client entities.SessionClient) (entities.AuthToken, error) {
+	args := m.Called(ctx, email, password, client)
+	return args.Get(0).(entities.AuthToken), args.Error(1)
+}
+
+func (m *MockAuthUsecase) Refresh(ctx context.Context, refreshToken string) (entities.AuthToken, error) {
+	args := m.Called(ctx, refreshToken)
+	return args.Get(0).(entities.AuthToken), args.Error(1)
+}
+
+func (m *MockAuthUsecase) Logout(ctx context.Context, sessionID uint64) error {
+	args := m.Called(ctx, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) LogoutAll(ctx context.Context, userID uint64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthUsecase) Sessions(ctx context.Context, userID uint64) ([]entities.Session, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entities.Session), args.Error(1)
+}
+
+func (m *MockAuthUsecase) VerifyPassword(ctx context.Context, email string, password string, clientIP string) (entities.User, error) {
+	args := m.Called(ctx, email, password, clientIP)
+	return args.Get(0).(entities.User), args.Error(1)
+}
+
+func (m *MockAuthUsecase) LockStatus(ctx context.Context, userID uint64) (entities.LockStatus, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(entities.LockStatus), args.Error(1)
+}
+
+func (m *MockAuthUsecase) Unlock(ctx context.Context, userID uint64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestAuthHandler_Login(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockAuthUsecase{}
+
+	authHandler := handler.NewAuthHandler(mockUsecase, validation.New())
+
+	setupGinContext := func(t *testing.T, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		expiresAt := time.Now().Add(15 * time.Minute)
+		mockUsecase.On("Login", mock.Anything, "ali@gmail.com", "secret-password", entities.SessionClient{UserAgent: "curl/8.0", IPAddress: "192.0.2.1"}).
+			Return(entities.AuthToken{AccessToken: "signed", TokenType: "Bearer", ExpiresIn: 15 * time.Minute, ExpiresAt: expiresAt, RefreshToken: "opaque"}, nil).Once()
+
+		c, w := setupGinContext(t, `{"email":"ali@gmail.com","password":"secret-password"}`)
+		c.Request.Header.Set("User-Agent", "curl/8.0")
+
+		authHandler.Login(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+		var res struct {
+			httpresponse.APIResponse
+			Data struct {
+				AccessToken  string `json:"access_token"`
+				TokenType    string `json:"token_type"`
+				ExpiresIn    int    `json:"expires_in"`
+				RefreshToken string `json:"refresh_token"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, "signed", res.Data.AccessToken)
+		assert.Equal(t, "Bearer", res.Data.TokenType)
+		assert.Equal(t, 900, res.Data.ExpiresIn)
+		assert.Equal(t, "opaque", res.Data.RefreshToken)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidCredentials", func(t *testing.T) {
+		mockUsecase.On("Login", mock.Anything, "ali@gmail.com", "wrong", mock.Anything).
+			Return(entities.AuthToken{}, usecase.ErrMsgInvalidCredentials).Once()
+
+		c, w := setupGinContext(t, `{"email":"ali@gmail.com","password":"wrong"}`)
+
+		authHandler.Login(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		var res httpresponse.Problem
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, handler.CodeInvalidCredentials, res.Code)
+		assert.Empty(t, res.Detail)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		mockUsecase.On("Login", mock.Anything, "ali@gmail.com", "wrong", mock.Anything).
+			Return(entities.AuthToken{}, &usecase.LockedError{RetryAfter: 90*time.Second + time.Millisecond}).Once()
+
+		c, w := setupGinContext(t, `{"email":"ali@gmail.com","password":"wrong"}`)
+
+		authHandler.Login(c)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "91", w.Header().Get("Retry-After"))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("ValidationError", func(t *testing.T) {
+		c, w := setupGinContext(t, `{"email":"not-an-email"}`)
+
+		authHandler.Login(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("InternalError", func(t *testing.T) {
+		mockUsecase.On("Login", mock.Anything, "ali@gmail.com", "secret-password", mock.Anything).
+			Return(entities.AuthToken{}, errors.New("db down")).Once()
+
+		c, w := setupGinContext(t, `{"email":"ali@gmail.com","password":"secret-password"}`)
+
+		authHandler.Login(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestAuthHandler_VerifyPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockAuthUsecase{}
+
+	authHandler := handler.NewAuthHandler(mockUsecase, validation.New())
+
+	setupGinContext := func(t *testing.T, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/auth/verify-password", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase.On("VerifyPassword", mock.Anything, "ali@gmail.com", "secret-password", "198.51.100.7").
+			Return(entities.User{ID: 7}, nil).Once()
+
+		c, w := setupGinContext(t, `{"email":"ali@gmail.com","password":"secret-password","client_ip":"198.51.100.7"}`)
+
+		authHandler.VerifyPassword(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"user_id":7`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("ClientIPOfRequest", func(t *testing.T) {
+		mockUsecase.On("VerifyPassword", mock.Anything, "ali@gmail.com", "secret-password", "192.0.2.1").
+			Return(entities.User{ID: 7}, nil).Once()
+
+		c, w := setupGinContext(t, `{"email":"ali@gmail.com","password":"secret-password"}`)
+
+		authHandler.VerifyPassword(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidCredentials", func(t *testing.T) {
+		mockUsecase.On("VerifyPassword", mock.Anything, "ali@gmail.com", "wrong", mock.Anything).
+			Return(entities.User{}, usecase.ErrMsgInvalidCredentials).Once()
+
+		c, w := setupGinContext(t, `{"email":"ali@gmail.com","password":"wrong"}`)
+
+		authHandler.VerifyPassword(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var res httpresponse.Problem
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, handler.CodePasswordNotVerified, res.Code)
+		assert.Empty(t, res.Detail)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		mockUsecase.On("VerifyPassword", mock.Anything, "ali@gmail.com", "wrong", mock.Anything).
+			Return(entities.User{}, &usecase.LockedError{RetryAfter: time.Minute}).Once()
+
+		c, w := setupGinContext(t, `{"email":"ali@gmail.com","password":"wrong"}`)
+
+		authHandler.VerifyPassword(c)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase.On("VerifyPassword", mock.Anything, "ali@gmail.com", "secret-password", mock.Anything).
+			Return(entities.User{}, usecase.ErrMsgForbidden).Once()
+
+		c, w := setupGinContext(t, `{"email":"ali@gmail.com","password":"secret-password"}`)
+
+		authHandler.VerifyPassword(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidClientIP", func(t *testing.T) {
+		c, w := setupGinContext(t, `{"email":"ali@gmail.com","password":"secret-password","client_ip":"nowhere"}`)
+
+		authHandler.VerifyPassword(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
+
+func TestAuthHandler_Unlock(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockAuthUsecase{}
+
+	authHandler := handler.NewAuthHandler(mockUsecase, validation.New())
+
+	setupGinContext := func(t *testing.T, method string, id string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/user/"+id+"/lock", nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		return c, w
+	}
+
+	t.Run("Status", func(t *testing.T) {
+		until := time.Now().Add(time.Minute)
+		mockUsecase.On("LockStatus", mock.Anything, uint64(7)).
+			Return(entities.LockStatus{Locked: true, LockedUntil: &until, FailedAttempts: 0, Lockouts: 2}, nil).Once()
+
+		c, w := setupGinContext(t, http.MethodGet, "7")
+
+		authHandler.LockStatus(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"locked":true`)
+		assert.Contains(t, w.Body.String(), `"lockouts":2`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase.On("Unlock", mock.Anything, uint64(7)).Return(nil).Once()
+
+		c, w := setupGinContext(t, http.MethodDelete, "7")
+
+		authHandler.Unlock(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase.On("Unlock", mock.Anything, uint64(8)).Return(usecase.ErrMsgForbidden).Once()
+
+		c, w := setupGinContext(t, http.MethodDelete, "8")
+
+		authHandler.Unlock(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockUsecase.On("Unlock", mock.Anything, uint64(9)).Return(usecase.ErrMsgUserNotFound).Once()
+
+		c, w := setupGinContext(t, http.MethodDelete, "9")
+
+		authHandler.Unlock(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockAuthUsecase{}
+
+	authHandler := handler.NewAuthHandler(mockUsecase, validation.New())
+
+	setupGinContext := func(t *testing.T, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase.On("Refresh", mock.Anything, "old").
+			Return(entities.AuthToken{AccessToken: "signed", TokenType: "Bearer", RefreshToken: "new"}, nil).Once()
+
+		c, w := setupGinContext(t, `{"refresh_token":"old"}`)
+
+		authHandler.Refresh(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"refresh_token":"new"`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Reused", func(t *testing.T) {
+		mockUsecase.On("Refresh", mock.Anything, "old").
+			Return(entities.AuthToken{}, usecase.ErrMsgRefreshTokenReused).Once()
+
+		c, w := setupGinContext(t, `{"refresh_token":"old"}`)
+
+		authHandler.Refresh(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), handler.ErrMsgRefreshTokenReused.Error())
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		mockUsecase.On("Refresh", mock.Anything, "unknown").
+			Return(entities.AuthToken{}, usecase.ErrMsgInvalidRefreshToken).Once()
+
+		c, w := setupGinContext(t, `{"refresh_token":"unknown"}`)
+
+		authHandler.Refresh(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("MissingToken", func(t *testing.T) {
+		c, w := setupGinContext(t, `{}`)
+
+		authHandler.Refresh(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
+
+func TestAuthHandler_Logout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockAuthUsecase{}
+
+	authHandler := handler.NewAuthHandler(mockUsecase, validation.New())
+
+	caller := entities.Principal{UserID: 7, Email: "ali@gmail.com", SessionID: 3}
+
+	setupGinContext := func(t *testing.T, path string, authenticated bool) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, path, nil)
+		if authenticated {
+			c.Request = c.Request.WithContext(principal.WithPrincipal(c.Request.Context(), caller))
+		}
+		return c, w
+	}
+
+	t.Run("Logout", func(t *testing.T) {
+		mockUsecase.On("Logout", mock.Anything, uint64(3)).Return(nil).Once()
+
+		c, w := setupGinContext(t, "/auth/logout", true)
+
+		authHandler.Logout(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("LogoutAll", func(t *testing.T) {
+		mockUsecase.On("LogoutAll", mock.Anything, uint64(7)).Return(int64(2), nil).Once()
+
+		c, w := setupGinContext(t, "/auth/logout-all", true)
+
+		authHandler.LogoutAll(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"revoked":2`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		c, w := setupGinContext(t, "/auth/logout", false)
+
+		authHandler.Logout(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("NoSession", func(t *testing.T) {
+		for name, caller := range map[string]entities.Principal{
+			"APIKey": {APIKeyID: 1, ServiceAccount: "billing", Scopes: entities.Scopes{entities.ScopeUsersRead}},
+			"Header": {UserID: 7},
+		} {
+			t.Run(name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Request = httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+				c.Request = c.Request.WithContext(principal.WithPrincipal(c.Request.Context(), caller))
+
+				authHandler.Logout(c)
+
+				assert.Equal(t, http.StatusConflict, w.Code)
+				assert.Contains(t, w.Body.String(), `"code":"no_session"`)
+				assert.Contains(t, w.Body.String(), "revoke an api key")
+			})
+		}
+		mockUsecase.AssertNotCalled(t, "Logout", mock.Anything, uint64(0))
+	})
+
+	t.Run("LogoutAllAPIKey", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/auth/logout-all", nil)
+		c.Request = c.Request.WithContext(principal.WithPrincipal(c.Request.Context(), entities.Principal{APIKeyID: 1, ServiceAccount: "billing"}))
+
+		authHandler.LogoutAll(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"no_session"`)
+		mockUsecase.AssertNotCalled(t, "LogoutAll", mock.Anything, uint64(0))
+	})
+}
+
+func TestAuthHandler_Sessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockAuthUsecase{}
+
+	authHandler := handler.NewAuthHandler(mockUsecase, validation.New())
+
+	setupGinContext := func(t *testing.T, id string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/user/"+id+"/sessions", nil)
+		c.Request = c.Request.WithContext(principal.WithPrincipal(c.Request.Context(), entities.Principal{UserID: 7, SessionID: 3}))
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase.On("Sessions", mock.Anything, uint64(7)).
+			Return([]entities.Session{{ID: 3, UserID: 7}, {ID: 2, UserID: 7}}, nil).Once()
+
+		c, w := setupGinContext(t, "7")
+
+		authHandler.Sessions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res struct {
+			Data []struct {
+				ID      uint64 `json:"id"`
+				Current bool   `json:"current"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Len(t, res.Data, 2)
+		assert.True(t, res.Data[0].Current)
+		assert.False(t, res.Data[1].Current)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase.On("Sessions", mock.Anything, uint64(8)).Return([]entities.Session(nil), usecase.ErrMsgForbidden).Once()
+
+		c, w := setupGinContext(t, "8")
+
+		authHandler.Sessions(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		c, w := setupGinContext(t, "abc")
+
+		authHandler.Sessions(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/request"
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/response"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Definition Error
+var (
+	ErrMsgMFANotEnrolled    = errors.New("mfa is not enrolled")
+	ErrMsgMFANotEnabled     = errors.New("mfa is not enabled")
+	ErrMsgMFAAlreadyEnabled = errors.New("mfa is already enabled")
+	ErrMsgInvalidMFACode    = errors.New("invalid mfa code")
+
+	SuccessMsgFoundMFA     = "found mfa status successfully"
+	SuccessMsgEnrolledMFA  = "MFA Enrolled, Activate It With A Code"
+	SuccessMsgActivatedMFA = "MFA Activated Successfully"
+	SuccessMsgVerifiedMFA  = "MFA Code Verified Successfully"
+	SuccessMsgDisabledMFA  = "MFA Disabled Successfully"
+)
+
+// Definition Struct (Class)
+type MFAHandler struct {
+	usecase  usecase.MFAUsecase
+	validate *validator.Validate
+}
+
+// Definition Constructor
+func NewMFAHandler(usecase usecase.MFAUsecase, validate *validator.Validate) *MFAHandler {
+	return &MFAHandler{
+		usecase:  usecase,
+		validate: validate,
+	}
+}
+
+// Definition Implement Methods (Status, Enroll, Activate, Verify, Disable)
+func (h *MFAHandler) Status(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return
+	}
+
+	status, err := h.usecase.Status(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgFoundMFA, response.MFAStatusResponse{
+		Enabled:           status.Enabled,
+		EnabledAt:         status.EnabledAt,
+		RecoveryCodesLeft: status.RecoveryCodesLeft,
+	}))
+}
+
+// Definition Implement Methods (Status, Enroll, Activate, Verify, Disable)
+// Enroll starts a new enrollment and returns its secret, the only time it
+// leaves the server.
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return
+	}
+
+	enrollment, err := h.usecase.Enroll(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, httpresponse.Success(SuccessMsgEnrolledMFA, response.MFAEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	}))
+}
+
+// Definition Implement Methods (Status, Enroll, Activate, Verify, Disable)
+func (h *MFAHandler) Activate(c *gin.Context) {
+	id, code, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.usecase.Activate(c, id, code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgActivatedMFA, response.MFAActivationResponse{RecoveryCodes: codes}))
+}
+
+// Definition Implement Methods (Status, Enroll, Activate, Verify, Disable)
+// Verify lets other services check a code the user typed. A wrong or reused
+// code is answered with 422, so it can't be mistaken for the caller's own
+// credentials being rejected.
+func (h *MFAHandler) Verify(c *gin.Context) {
+	id, code, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	method, err := h.usecase.Verify(c, id, code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgVerifiedMFA, response.MFAVerifyResponse{Valid: true, Method: method}))
+}
+
+// Definition Implement Methods (Status, Enroll, Activate, Verify, Disable)
+func (h *MFAHandler) Disable(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return
+	}
+
+	var req request.DisableMFARequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		respondError(c, invalid(ErrMsgInvalidBody, err))
+		return
+	}
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return
+	}
+
+	err = h.usecase.Disable(c, id, req.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgDisabledMFA, nil))
+}
+
+// bindCode reads the user id from the path and the code from the body, or
+// responds with the error and returns false.
+func (h *MFAHandler) bindCode(c *gin.Context) (uint64, string, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return 0, "", false
+	}
+
+	var req request.MFACodeRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidBody, err))
+		return 0, "", false
+	}
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return 0, "", false
+	}
+
+	return id, req.Code, true
+}
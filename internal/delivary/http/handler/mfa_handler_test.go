@@ -0,0 +1,243 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMFAUsecase struct {
+	mock.Mock
+}
+
+func (m *MockMFAUsecase) Status(ctx context.Context, userID uint64) (entities.MFAStatus, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(entities.MFAStatus), args.Error(1)
+}
+
+func (m *MockMFAUsecase) Enroll(ctx context.Context, userID uint64) (entities.MFAEnrollment, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(entities.MFAEnrollment), args.Error(1)
+}
+
+func (m *MockMFAUsecase) Activate(ctx context.Context, userID uint64, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockMFAUsecase) Verify(ctx context.Context, userID uint64, code string) (string, error) {
+	args := m.Called(ctx, userID, code)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockMFAUsecase) Disable(ctx context.Context, userID uint64, code string) error {
+	args := m.Called(ctx, userID, code)
+	return args.Error(0)
+}
+
+func TestMFAHandler_Enroll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockMFAUsecase{}
+
+	mfaHandler := handler.NewMFAHandler(mockUsecase, validation.New())
+
+	setupGinContext := func(t *testing.T, id string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/user/"+id+"/mfa", nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase.On("Enroll", mock.Anything, uint64(7)).Return(entities.MFAEnrollment{
+			Secret:          "JBSWY3DPEHPK3PXP",
+			ProvisioningURI: "otpauth://totp/user-manager:ali@gmail.com?secret=JBSWY3DPEHPK3PXP",
+		}, nil).Once()
+
+		c, w := setupGinContext(t, "7")
+
+		mfaHandler.Enroll(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		var res struct {
+			Data struct {
+				Secret          string `json:"secret"`
+				ProvisioningURI string `json:"provisioning_uri"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, "JBSWY3DPEHPK3PXP", res.Data.Secret)
+		assert.Contains(t, res.Data.ProvisioningURI, "otpauth://totp/")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("AlreadyEnabled", func(t *testing.T) {
+		mockUsecase.On("Enroll", mock.Anything, uint64(7)).Return(entities.MFAEnrollment{}, usecase.ErrMsgMFAAlreadyEnabled).Once()
+
+		c, w := setupGinContext(t, "7")
+
+		mfaHandler.Enroll(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase.On("Enroll", mock.Anything, uint64(8)).Return(entities.MFAEnrollment{}, usecase.ErrMsgForbidden).Once()
+
+		c, w := setupGinContext(t, "8")
+
+		mfaHandler.Enroll(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestMFAHandler_Verify(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockMFAUsecase{}
+
+	mfaHandler := handler.NewMFAHandler(mockUsecase, validation.New())
+
+	setupGinContext := func(t *testing.T, id string, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/user/"+id+"/mfa/verify", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase.On("Verify", mock.Anything, uint64(7), "123456").Return(entities.MFAMethodTOTP, nil).Once()
+
+		c, w := setupGinContext(t, "7", `{"code":"123456"}`)
+
+		mfaHandler.Verify(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res struct {
+			Data struct {
+				Valid  bool   `json:"valid"`
+				Method string `json:"method"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.True(t, res.Data.Valid)
+		assert.Equal(t, "totp", res.Data.Method)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidCode", func(t *testing.T) {
+		mockUsecase.On("Verify", mock.Anything, uint64(7), "123456").Return("", usecase.ErrMsgInvalidMFACode).Once()
+
+		c, w := setupGinContext(t, "7", `{"code":"123456"}`)
+
+		mfaHandler.Verify(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var res httpresponse.Problem
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, handler.CodeInvalidMFACode, res.Code)
+		assert.Empty(t, res.Detail)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("NotEnabled", func(t *testing.T) {
+		mockUsecase.On("Verify", mock.Anything, uint64(7), "123456").Return("", usecase.ErrMsgMFANotEnabled).Once()
+
+		c, w := setupGinContext(t, "7", `{"code":"123456"}`)
+
+		mfaHandler.Verify(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("MissingCode", func(t *testing.T) {
+		c, w := setupGinContext(t, "7", `{}`)
+
+		mfaHandler.Verify(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("InternalError", func(t *testing.T) {
+		mockUsecase.On("Verify", mock.Anything, uint64(7), "123456").Return("", errors.New("db down")).Once()
+
+		c, w := setupGinContext(t, "7", `{"code":"123456"}`)
+
+		mfaHandler.Verify(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestMFAHandler_Disable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockMFAUsecase{}
+
+	mfaHandler := handler.NewMFAHandler(mockUsecase, validation.New())
+
+	setupGinContext := func(t *testing.T, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodDelete, "/user/7/mfa", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: "7"}}
+		return c, w
+	}
+
+	t.Run("WithCode", func(t *testing.T) {
+		mockUsecase.On("Disable", mock.Anything, uint64(7), "123456").Return(nil).Once()
+
+		c, w := setupGinContext(t, `{"code":"123456"}`)
+
+		mfaHandler.Disable(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("WithoutBody", func(t *testing.T) {
+		mockUsecase.On("Disable", mock.Anything, uint64(7), "").Return(nil).Once()
+
+		c, w := setupGinContext(t, "")
+
+		mfaHandler.Disable(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidCode", func(t *testing.T) {
+		mockUsecase.On("Disable", mock.Anything, uint64(7), "").Return(usecase.ErrMsgInvalidMFACode).Once()
+
+		c, w := setupGinContext(t, "")
+
+		mfaHandler.Disable(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
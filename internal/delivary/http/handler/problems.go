@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// Definition Problem Codes
+// Clients branch on these, so a code once published is never renamed.
+const (
+	CodeInvalidBody          = "invalid_body"
+	CodeInvalidID            = "invalid_id"
+	CodeInvalidQuery         = "invalid_query"
+	CodeInvalidSort          = "invalid_sort"
+	CodeInvalidCursor        = "invalid_cursor"
+	CodeInvalidColumns       = "invalid_columns"
+	CodeValidation           = "validation_failed"
+	CodePreconditionFailed   = "precondition_failed"
+	CodeVersionConflict      = "version_conflict"
+	CodeDuplicateUser        = "duplicate_user"
+	CodeUserNotFound         = "user_not_found"
+	CodeBulkAborted          = "bulk_aborted"
+	CodeUnauthenticated      = "unauthenticated"
+	CodeForbidden            = "forbidden"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodePasswordNotVerified  = "password_not_verified"
+	CodeTooManyAttempts      = "too_many_attempts"
+	CodeInvalidRefreshToken  = "invalid_refresh_token"
+	CodeRefreshTokenReused   = "refresh_token_reused"
+	CodeSessionNotFound      = "session_not_found"
+	CodeNoSession            = "no_session"
+	CodeRoleNotFound         = "role_not_found"
+	CodeRoleNotAssigned      = "role_not_assigned"
+	CodeLastAdmin            = "last_admin"
+	CodeMFANotEnrolled       = "mfa_not_enrolled"
+	CodeMFANotEnabled        = "mfa_not_enabled"
+	CodeMFAAlreadyEnabled    = "mfa_already_enabled"
+	CodeInvalidMFACode       = "invalid_mfa_code"
+	CodeInvalidOneTimeToken  = "invalid_one_time_token"
+	CodeEmailAlreadyVerified = "email_already_verified"
+	CodeInternal             = "internal_error"
+)
+
+// problemRule turns errors matching err into a problem titled with the text
+// of title. detail, when set, picks what of the error the client may see;
+// extend, when set, adds to the problem or the headers of the response.
+type problemRule struct {
+	err    error
+	status int
+	code   string
+	title  error
+	detail func(err error) string
+	extend func(c *gin.Context, err error, problem *httpresponse.Problem)
+}
+
+// problemRules is searched in order, so handler errors wrapping a usecase
+// error come before the rule of the usecase error.
+var problemRules = []problemRule{
+	// the request itself is malformed, the cause is about the input and safe to echo
+	{err: ErrMsgInvalidBody, status: http.StatusBadRequest, code: CodeInvalidBody, title: ErrMsgInvalidBody, detail: causeOf(ErrMsgInvalidBody)},
+	{err: ErrMsgInvalidId, status: http.StatusBadRequest, code: CodeInvalidID, title: ErrMsgInvalidId, detail: causeOf(ErrMsgInvalidId)},
+	{err: ErrMsgInvalidQuery, status: http.StatusBadRequest, code: CodeInvalidQuery, title: ErrMsgInvalidQuery, detail: causeOf(ErrMsgInvalidQuery)},
+	{err: ErrMsgInvalidSort, status: http.StatusBadRequest, code: CodeInvalidSort, title: ErrMsgInvalidSort, detail: causeOf(ErrMsgInvalidSort)},
+	{err: ErrMsgInvalidCursor, status: http.StatusBadRequest, code: CodeInvalidCursor, title: ErrMsgInvalidCursor, detail: causeOf(ErrMsgInvalidCursor)},
+	{err: ErrMsgInvalidColumns, status: http.StatusBadRequest, code: CodeInvalidColumns, title: ErrMsgInvalidColumns, detail: causeOf(ErrMsgInvalidColumns)},
+	{err: ErrMsgValidation, status: http.StatusUnprocessableEntity, code: CodeValidation, title: ErrMsgValidation, detail: causeOf(ErrMsgValidation), extend: fieldErrors},
+	{err: ErrMsgPreconditionFailed, status: http.StatusPreconditionFailed, code: CodePreconditionFailed, title: ErrMsgPreconditionFailed, detail: causeOf(ErrMsgPreconditionFailed)},
+	// 422, a 401 would read as the calling service failing to authenticate
+	{err: ErrMsgPasswordNotVerified, status: http.StatusUnprocessableEntity, code: CodePasswordNotVerified, title: ErrMsgPasswordNotVerified},
+	{err: ErrMsgNoSession, status: http.StatusConflict, code: CodeNoSession, title: ErrMsgNoSession, detail: causeOf(ErrMsgNoSession)},
+
+	{err: usecase.ErrMsgUnauthenticated, status: http.StatusUnauthorized, code: CodeUnauthenticated, title: ErrMsgUnauthenticated},
+	// the missing permission tells the caller what to ask for
+	{err: usecase.ErrMsgForbidden, status: http.StatusForbidden, code: CodeForbidden, title: ErrMsgForbidden, detail: errorText},
+	{err: usecase.ErrMsgTooManyAttempts, status: http.StatusTooManyRequests, code: CodeTooManyAttempts, title: ErrMsgTooManyAttempts, extend: retryAfter},
+
+	{err: usecase.ErrMsgDuplicateUser, status: http.StatusConflict, code: CodeDuplicateUser, title: ErrMsgDuplicateUser},
+	{err: usecase.ErrMsgUserNotFound, status: http.StatusNotFound, code: CodeUserNotFound, title: ErrMsgUserNotFound},
+	{err: usecase.ErrMsgVersionConflict, status: http.StatusConflict, code: CodeVersionConflict, title: ErrMsgVersionConflict},
+	{err: usecase.ErrMsgInvalidCursor, status: http.StatusBadRequest, code: CodeInvalidCursor, title: ErrMsgInvalidCursor},
+	// a password longer than bcrypt takes, from a caller that skipped max_bytes
+	{err: usecase.ErrMsgInvalidPassword, status: http.StatusUnprocessableEntity, code: CodeValidation, title: ErrMsgValidation, detail: causeOf(usecase.ErrMsgInvalidPassword)},
+
+	// don't echo the causes below, they tell a wrong password from an
+	// unknown email, or an unknown token or code from a used one
+	{err: usecase.ErrMsgInvalidCredentials, status: http.StatusUnauthorized, code: CodeInvalidCredentials, title: ErrMsgInvalidCredentials},
+	{err: usecase.ErrMsgRefreshTokenReused, status: http.StatusUnauthorized, code: CodeRefreshTokenReused, title: ErrMsgRefreshTokenReused},
+	{err: usecase.ErrMsgInvalidRefreshToken, status: http.StatusUnauthorized, code: CodeInvalidRefreshToken, title: ErrMsgInvalidRefreshToken},
+	{err: usecase.ErrMsgInvalidMFACode, status: http.StatusUnprocessableEntity, code: CodeInvalidMFACode, title: ErrMsgInvalidMFACode},
+	{err: usecase.ErrMsgInvalidOneTimeToken, status: http.StatusUnprocessableEntity, code: CodeInvalidOneTimeToken, title: ErrMsgInvalidOneTimeToken},
+	{err: usecase.ErrMsgSessionNotFound, status: http.StatusNotFound, code: CodeSessionNotFound, title: ErrMsgSessionNotFound},
+
+	{err: usecase.ErrMsgRoleNotFound, status: http.StatusNotFound, code: CodeRoleNotFound, title: ErrMsgRoleNotFound},
+	{err: usecase.ErrMsgRoleNotAssigned, status: http.StatusNotFound, code: CodeRoleNotAssigned, title: ErrMsgRoleNotAssigned},
+	{err: usecase.ErrMsgLastAdmin, status: http.StatusConflict, code: CodeLastAdmin, title: ErrMsgLastAdmin},
+
+	{err: usecase.ErrMsgMFANotEnrolled, status: http.StatusNotFound, code: CodeMFANotEnrolled, title: ErrMsgMFANotEnrolled},
+	{err: usecase.ErrMsgMFANotEnabled, status: http.StatusConflict, code: CodeMFANotEnabled, title: ErrMsgMFANotEnabled},
+	{err: usecase.ErrMsgMFAAlreadyEnabled, status: http.StatusConflict, code: CodeMFAAlreadyEnabled, title: ErrMsgMFAAlreadyEnabled},
+
+	{err: usecase.ErrMsgEmailAlreadyVerified, status: http.StatusConflict, code: CodeEmailAlreadyVerified, title: ErrMsgEmailAlreadyVerified},
+}
+
+// respondError answers err as a problem, found by the first rule err
+// matches. Any other error is a 500 whose text stays on the gin context,
+// the client learns nothing about it.
+func respondError(c *gin.Context, err error) {
+	for _, rule := range problemRules {
+		if !errors.Is(err, rule.err) {
+			continue
+		}
+
+		detail := ""
+		if rule.detail != nil {
+			detail = rule.detail(err)
+		}
+
+		problem := httpresponse.NewProblem(rule.status, rule.code, rule.title.Error(), detail)
+		if rule.extend != nil {
+			rule.extend(c, err, &problem)
+		}
+
+		httpresponse.AbortWithProblem(c, problem)
+		return
+	}
+
+	_ = c.Error(err)
+	httpresponse.AbortWithProblem(c, httpresponse.NewProblem(http.StatusInternalServerError, CodeInternal, ErrMsgInternalServerError.Error(), ""))
+}
+
+// errorText shows the whole error.
+func errorText(err error) string {
+	return err.Error()
+}
+
+// causeOf shows what sentinel was wrapped with, the text after its own.
+func causeOf(sentinel error) func(err error) string {
+	return func(err error) string {
+		text := strings.TrimPrefix(err.Error(), sentinel.Error())
+		return strings.TrimSpace(strings.TrimPrefix(text, ":"))
+	}
+}
+
+// retryAfter tells a locked out client when to try again.
+func retryAfter(c *gin.Context, err error, problem *httpresponse.Problem) {
+	var locked *usecase.LockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+	}
+}
+
+// fieldErrors lists the rules each field broke, in the language the request
+// asked for, in place of the text of the validator.
+func fieldErrors(c *gin.Context, err error, problem *httpresponse.Problem) {
+	fields := validation.Fields(err, validation.TranslatorFromContext(c.Request.Context()))
+	if fields == nil {
+		return
+	}
+
+	problem.Detail = ""
+	problem.Errors = fields
+}
+
+// invalid wraps the cause of a client error in the handler error the
+// problem rules know it by.
+func invalid(sentinel error, cause error) error {
+	return fmt.Errorf("%w:%w", sentinel, cause)
+}
@@ -0,0 +1,162 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/cursor"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/password"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockUserUsecase{}
+
+	userHandler := handler.NewUserHandler(mockUsecase, validation.New(), cursor.NewCodec("test-secret"))
+
+	setupGinContext := func(t *testing.T, method string, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/users/1", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request = c.Request.WithContext(requestid.WithRequestID(c.Request.Context(), "req-1"))
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		return c, w
+	}
+
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) httpresponse.Problem {
+		assert.Equal(t, httpresponse.ProblemContentType, w.Header().Get("Content-Type"))
+
+		var res httpresponse.Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, w.Code, res.Status)
+		assert.Equal(t, httpresponse.ProblemTypeBase+res.Code, res.Type)
+		assert.Equal(t, "/users/1", res.Instance)
+		assert.Equal(t, "req-1", res.RequestID)
+		return res
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		title  string
+		detail string
+	}{
+		{name: "NotFound", err: fmt.Errorf("%w:%w", usecase.ErrMsgUserNotFound, errors.New("record not found")), status: http.StatusNotFound, code: handler.CodeUserNotFound, title: handler.ErrMsgUserNotFound.Error()},
+		{name: "Duplicate", err: usecase.ErrMsgDuplicateUser, status: http.StatusConflict, code: handler.CodeDuplicateUser, title: handler.ErrMsgDuplicateUser.Error()},
+		{name: "Unauthenticated", err: usecase.ErrMsgUnauthenticated, status: http.StatusUnauthorized, code: handler.CodeUnauthenticated, title: handler.ErrMsgUnauthenticated.Error()},
+		{name: "Forbidden", err: fmt.Errorf("%w: %s", usecase.ErrMsgForbidden, entities.PermUsersRead), status: http.StatusForbidden, code: handler.CodeForbidden, title: handler.ErrMsgForbidden.Error(), detail: "forbidden: users:read"},
+		{name: "InvalidPassword", err: fmt.Errorf("%w:%w", usecase.ErrMsgInvalidPassword, password.ErrMsgTooLong), status: http.StatusUnprocessableEntity, code: handler.CodeValidation, title: handler.ErrMsgValidation.Error(), detail: password.ErrMsgTooLong.Error()},
+		{name: "Internal", err: fmt.Errorf("%w:%w", usecase.ErrMsgInternalServerError, errors.New("dial tcp: connection refused")), status: http.StatusInternalServerError, code: handler.CodeInternal, title: handler.ErrMsgInternalServerError.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase.On("FindByID", mock.Anything, uint64(1)).Return(entities.User{}, tt.err).Once()
+
+			c, w := setupGinContext(t, http.MethodGet, "")
+
+			userHandler.FindByID(c)
+
+			assert.Equal(t, tt.status, w.Code)
+			res := decode(t, w)
+			assert.Equal(t, tt.code, res.Code)
+			assert.Equal(t, tt.title, res.Title)
+			assert.Equal(t, tt.detail, res.Detail, "only safe causes are shown")
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		c, w := setupGinContext(t, http.MethodPatch, `{"name":`)
+
+		userHandler.Update(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		res := decode(t, w)
+		assert.Equal(t, handler.CodeInvalidBody, res.Code)
+		assert.Equal(t, "unexpected EOF", res.Detail)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		validate := validation.New()
+		translator, err := validation.NewTranslator(validate)
+		require.NoError(t, err)
+		userHandler := handler.NewUserHandler(mockUsecase, validate, cursor.NewCodec("test-secret"))
+
+		c, w := setupGinContext(t, http.MethodPost, `{"name":"A","family":"Test Family","email":"not-an-email","age":30}`)
+		c.Request = c.Request.WithContext(validation.WithTranslator(c.Request.Context(), translator.Match("fa-IR")))
+
+		userHandler.Create(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, httpresponse.ProblemContentType, w.Header().Get("Content-Type"))
+
+		var res struct {
+			httpresponse.Problem
+			Errors []validation.FieldError `json:"errors"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, handler.CodeValidation, res.Code)
+		assert.Empty(t, res.Detail)
+		require.Len(t, res.Errors, 2)
+		assert.Equal(t, validation.FieldError{Field: "name", JSONPath: "$.name", Rule: "min", Param: "2", Message: res.Errors[0].Message}, res.Errors[0])
+		assert.Equal(t, "email", res.Errors[1].Field)
+		assert.Equal(t, "email", res.Errors[1].Rule)
+		assert.Equal(t, "email باید یک ایمیل معتبر باشد", res.Errors[1].Message)
+	})
+
+	t.Run("PasswordBytes", func(t *testing.T) {
+		// 40 characters, 80 bytes, more than bcrypt takes
+		body := fmt.Sprintf(`{"name":"Ali","family":"Test Family","email":"ali@gmail.com","age":30,"password":%q}`, strings.Repeat("رمز", 13)+"ز")
+		c, w := setupGinContext(t, http.MethodPost, body)
+
+		userHandler.Create(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var res struct {
+			httpresponse.Problem
+			Errors []validation.FieldError `json:"errors"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, "password", res.Errors[0].Field)
+		assert.Equal(t, validation.RuleMaxBytes, res.Errors[0].Rule)
+		assert.Equal(t, "72", res.Errors[0].Param)
+	})
+
+	t.Run("StaleIfMatch", func(t *testing.T) {
+		version := 2
+		mockUsecase.On("Patch", mock.Anything, uint64(1), entities.UserPatch{Versions: []int{version}}).
+			Return(entities.User{}, usecase.ErrMsgVersionConflict).Once()
+
+		c, w := setupGinContext(t, http.MethodPatch, `{}`)
+		c.Request.Header.Set("If-Match", `"2"`)
+
+		userHandler.Update(c)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		res := decode(t, w)
+		assert.Equal(t, handler.CodePreconditionFailed, res.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
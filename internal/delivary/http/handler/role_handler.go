@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/response"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// Definition Error
+var (
+	ErrMsgRoleNotFound    = errors.New("role not found")
+	ErrMsgRoleNotAssigned = errors.New("role not assigned to user")
+	ErrMsgLastAdmin       = errors.New("the last admin can not lose the admin role")
+
+	SuccessMsgFoundRoles    = "found roles successfully"
+	SuccessMsgFoundUserRole = "found user roles successfully"
+	SuccessMsgAssignedRole  = "Role Assigned Successfully"
+	SuccessMsgRevokedRole   = "Role Revoked Successfully"
+)
+
+// Definition Struct (Class)
+type RoleHandler struct {
+	usecase usecase.RoleUsecase
+}
+
+// Definition Constructor
+func NewRoleHandler(usecase usecase.RoleUsecase) *RoleHandler {
+	return &RoleHandler{
+		usecase: usecase,
+	}
+}
+
+// Definition Implement Methods (List, UserRoles, Assign, Revoke)
+func (h *RoleHandler) List(c *gin.Context) {
+	roles, err := h.usecase.List(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgFoundRoles, roleResponses(roles)))
+}
+
+// Definition Implement Methods (List, UserRoles, Assign, Revoke)
+func (h *RoleHandler) UserRoles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return
+	}
+
+	roles, err := h.usecase.UserRoles(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgFoundUserRole, response.UserRolesResponse{
+		UserID: id,
+		Roles:  roleResponses(roles),
+	}))
+}
+
+// Definition Implement Methods (List, UserRoles, Assign, Revoke)
+// Assign gives the user in the path the role in the path. It is idempotent,
+// like any PUT.
+func (h *RoleHandler) Assign(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return
+	}
+
+	err = h.usecase.Assign(c, id, c.Param("role"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgAssignedRole, nil))
+}
+
+// Definition Implement Methods (List, UserRoles, Assign, Revoke)
+func (h *RoleHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return
+	}
+
+	err = h.usecase.Revoke(c, id, c.Param("role"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgRevokedRole, nil))
+}
+
+// roleResponses maps roles to their responses.
+func roleResponses(roles []entities.Role) []response.RoleResponse {
+	res := make([]response.RoleResponse, len(roles))
+	for i, role := range roles {
+		permissions := make([]string, len(role.Permissions))
+		for j, permission := range role.Permissions {
+			permissions[j] = string(permission)
+		}
+
+		res[i] = response.RoleResponse{
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: permissions,
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,162 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRoleUsecase struct {
+	mock.Mock
+}
+
+func (m *MockRoleUsecase) List(ctx context.Context) ([]entities.Role, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entities.Role), args.Error(1)
+}
+
+func (m *MockRoleUsecase) UserRoles(ctx context.Context, userID uint64) ([]entities.Role, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entities.Role), args.Error(1)
+}
+
+func (m *MockRoleUsecase) Assign(ctx context.Context, userID uint64, role string) error {
+	args := m.Called(ctx, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockRoleUsecase) Revoke(ctx context.Context, userID uint64, role string) error {
+	args := m.Called(ctx, userID, role)
+	return args.Error(0)
+}
+
+func TestRoleHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockRoleUsecase{}
+
+	roleHandler := handler.NewRoleHandler(mockUsecase)
+
+	setupGinContext := func(t *testing.T) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/roles", nil)
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase.On("List", mock.Anything).Return([]entities.Role{
+			{ID: 2, Name: entities.RoleSupport, Permissions: []entities.Permission{entities.PermUsersRead, entities.PermUsersUpdate}},
+		}, nil).Once()
+
+		c, w := setupGinContext(t)
+
+		roleHandler.List(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res struct {
+			Data []struct {
+				Name        string   `json:"name"`
+				Permissions []string `json:"permissions"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Len(t, res.Data, 1)
+		assert.Equal(t, "support", res.Data[0].Name)
+		assert.Equal(t, []string{"users:read", "users:update"}, res.Data[0].Permissions)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase.On("List", mock.Anything).Return([]entities.Role(nil), usecase.ErrMsgForbidden).Once()
+
+		c, w := setupGinContext(t)
+
+		roleHandler.List(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestRoleHandler_AssignAndRevoke(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockRoleUsecase{}
+
+	roleHandler := handler.NewRoleHandler(mockUsecase)
+
+	setupGinContext := func(t *testing.T, method string, id string, role string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/user/"+id+"/roles/"+role, nil)
+		c.Params = gin.Params{{Key: "id", Value: id}, {Key: "role", Value: role}}
+		return c, w
+	}
+
+	t.Run("Assign", func(t *testing.T) {
+		mockUsecase.On("Assign", mock.Anything, uint64(7), "support").Return(nil).Once()
+
+		c, w := setupGinContext(t, http.MethodPut, "7", "support")
+
+		roleHandler.Assign(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res httpresponse.APIResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, handler.SuccessMsgAssignedRole, res.Message)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("UnknownRole", func(t *testing.T) {
+		mockUsecase.On("Assign", mock.Anything, uint64(7), "owner").Return(usecase.ErrMsgRoleNotFound).Once()
+
+		c, w := setupGinContext(t, http.MethodPut, "7", "owner")
+
+		roleHandler.Assign(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("LastAdmin", func(t *testing.T) {
+		mockUsecase.On("Revoke", mock.Anything, uint64(1), "admin").Return(usecase.ErrMsgLastAdmin).Once()
+
+		c, w := setupGinContext(t, http.MethodDelete, "1", "admin")
+
+		roleHandler.Revoke(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InternalError", func(t *testing.T) {
+		mockUsecase.On("Revoke", mock.Anything, uint64(1), "support").Return(errors.New("db down")).Once()
+
+		c, w := setupGinContext(t, http.MethodDelete, "1", "support")
+
+		roleHandler.Revoke(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		c, w := setupGinContext(t, http.MethodPut, "abc", "support")
+
+		roleHandler.Assign(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/request"
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/response"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// Definition Bulk Item Status
+const (
+	bulkStatusCreated  = "created"
+	bulkStatusUpdated  = "updated"
+	bulkStatusDeleted  = "deleted"
+	bulkStatusInvalid  = "invalid"
+	bulkStatusConflict = "conflict"
+	bulkStatusNotFound = "not_found"
+	bulkStatusAborted  = "aborted"
+	bulkStatusFailed   = "failed"
+)
+
+// Definition Implement Methods (BulkCreate, BulkUpdate, BulkDelete)
+// BulkCreate validates every user on its own; the valid ones are created in
+// batches. In atomic mode a single invalid or conflicting user creates none.
+func (h *UserHandler) BulkCreate(c *gin.Context) {
+	var req request.BulkCreateUserRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidBody, err))
+		return
+	}
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return
+	}
+
+	err = checkBulkPasswords(req.Users)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return
+	}
+
+	mode := bulkMode(req.Mode)
+	results := make([]response.BulkUserResult, len(req.Users))
+
+	users := make([]entities.User, 0, len(req.Users))
+	indexes := make([]int, 0, len(req.Users))
+	for i, item := range req.Users {
+		err = h.validate.Struct(item)
+		if err != nil {
+			results[i] = response.BulkUserResult{Index: i, Status: bulkStatusInvalid, Error: err.Error()}
+			continue
+		}
+
+		users = append(users, entities.User{
+			Name:     item.Name,
+			Family:   item.Family,
+			Email:    item.Email,
+			Age:      item.Age,
+			Password: item.Password,
+		})
+		indexes = append(indexes, i)
+	}
+
+	if mode == entities.BulkAtomic && len(indexes) < len(req.Users) {
+		abortBulk(results, indexes)
+		bulkRespond(c, mode, results, http.StatusUnprocessableEntity)
+		return
+	}
+
+	created, err := h.usecase.CreateBatch(c, users, mode)
+	if err != nil && !errors.Is(err, usecase.ErrMsgBulkAborted) {
+		respondError(c, err)
+		return
+	}
+
+	for j, i := range indexes {
+		results[i] = bulkResult(i, created[j], bulkStatusCreated)
+	}
+
+	bulkRespond(c, mode, results, bulkErrorStatus(err))
+}
+
+// checkBulkPasswords refuses a batch with more than request.MaxBulkPasswords
+// users sent with a password.
+func checkBulkPasswords(users []request.CreateUserRequest) error {
+	count := 0
+	for _, user := range users {
+		if user.Password != "" {
+			count++
+		}
+	}
+
+	if count > request.MaxBulkPasswords {
+		return fmt.Errorf("%d users have a password, at most %d may", count, request.MaxBulkPasswords)
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (BulkCreate, BulkUpdate, BulkDelete)
+// BulkUpdate patches every listed user like PATCH /:id; a version, when
+// given, plays the role of If-Match.
+func (h *UserHandler) BulkUpdate(c *gin.Context) {
+	var req request.BulkUpdateUserRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidBody, err))
+		return
+	}
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return
+	}
+
+	mode := bulkMode(req.Mode)
+	results := make([]response.BulkUserResult, len(req.Users))
+
+	patches := make([]entities.UserBatchPatch, 0, len(req.Users))
+	indexes := make([]int, 0, len(req.Users))
+	for i, item := range req.Users {
+		err = h.validate.Struct(item)
+		if err != nil {
+			results[i] = response.BulkUserResult{Index: i, Status: bulkStatusInvalid, ID: item.ID, Error: err.Error()}
+			continue
+		}
+
+		var versions []int
+		if item.Version != nil {
+			versions = []int{*item.Version}
+		}
+
+		patches = append(patches, entities.UserBatchPatch{
+			ID: item.ID,
+			Patch: entities.UserPatch{
+				Name:     item.Name,
+				Family:   item.Family,
+				Email:    item.Email,
+				Age:      item.Age,
+				Versions: versions,
+			},
+		})
+		indexes = append(indexes, i)
+	}
+
+	if mode == entities.BulkAtomic && len(indexes) < len(req.Users) {
+		abortBulk(results, indexes)
+		bulkRespond(c, mode, results, http.StatusUnprocessableEntity)
+		return
+	}
+
+	updated, err := h.usecase.PatchBatch(c, patches, mode)
+	if err != nil && !errors.Is(err, usecase.ErrMsgBulkAborted) {
+		respondError(c, err)
+		return
+	}
+
+	for j, i := range indexes {
+		results[i] = bulkResult(i, updated[j], bulkStatusUpdated)
+		results[i].ID = patches[j].ID
+	}
+
+	bulkRespond(c, mode, results, bulkErrorStatus(err))
+}
+
+// Definition Implement Methods (BulkCreate, BulkUpdate, BulkDelete)
+func (h *UserHandler) BulkDelete(c *gin.Context) {
+	var req request.BulkDeleteUserRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidBody, err))
+		return
+	}
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return
+	}
+
+	mode := bulkMode(req.Mode)
+
+	deleted, err := h.usecase.DeleteBatch(c, req.IDs, mode)
+	if err != nil && !errors.Is(err, usecase.ErrMsgBulkAborted) {
+		respondError(c, err)
+		return
+	}
+
+	results := make([]response.BulkUserResult, len(req.IDs))
+	for i, result := range deleted {
+		results[i] = bulkResult(i, result, bulkStatusDeleted)
+		results[i].ID = req.IDs[i]
+	}
+
+	bulkRespond(c, mode, results, bulkErrorStatus(err))
+}
+
+// bulkMode defaults an empty mode to atomic.
+func bulkMode(mode string) entities.BulkMode {
+	if mode == "" {
+		return entities.BulkAtomic
+	}
+
+	return entities.BulkMode(mode)
+}
+
+// bulkResult describes a usecase result in the response, status being what a
+// successful item reports.
+func bulkResult(index int, result entities.BulkResult, status string) response.BulkUserResult {
+	if result.Err == nil {
+		return response.BulkUserResult{
+			Index:   index,
+			Status:  status,
+			ID:      result.User.ID,
+			Version: result.User.Version,
+		}
+	}
+
+	res := response.BulkUserResult{Index: index}
+	switch {
+	case errors.Is(result.Err, usecase.ErrMsgBulkRolledBack):
+		res.Status, res.Error = bulkStatusAborted, usecase.ErrMsgBulkRolledBack.Error()
+	case errors.Is(result.Err, usecase.ErrMsgDuplicateUser):
+		res.Status, res.Error = bulkStatusConflict, ErrMsgDuplicateUser.Error()
+	case errors.Is(result.Err, usecase.ErrMsgVersionConflict):
+		res.Status, res.Error = bulkStatusConflict, ErrMsgVersionConflict.Error()
+	case errors.Is(result.Err, usecase.ErrMsgUserNotFound):
+		res.Status, res.Error = bulkStatusNotFound, ErrMsgUserNotFound.Error()
+	case errors.Is(result.Err, usecase.ErrMsgInvalidPassword):
+		res.Status, res.Error = bulkStatusInvalid, result.Err.Error()
+	default:
+		res.Status, res.Error = bulkStatusFailed, ErrMsgInternalServerError.Error()
+	}
+
+	return res
+}
+
+// abortBulk marks the items at indexes as not applied because others failed.
+func abortBulk(results []response.BulkUserResult, indexes []int) {
+	for _, i := range indexes {
+		results[i] = response.BulkUserResult{Index: i, Status: bulkStatusAborted, Error: usecase.ErrMsgBulkRolledBack.Error()}
+	}
+}
+
+// bulkErrorStatus is the HTTP status of an aborted atomic batch, chosen from
+// the error that aborted it.
+func bulkErrorStatus(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, usecase.ErrMsgDuplicateUser), errors.Is(err, usecase.ErrMsgVersionConflict):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrMsgUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrMsgInvalidPassword):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bulkRespond writes the per-item results: 200 when every item succeeded,
+// 207 when a best-effort batch partly failed and failureStatus when an
+// atomic batch was aborted.
+func bulkRespond(c *gin.Context, mode entities.BulkMode, results []response.BulkUserResult, failureStatus int) {
+	res := response.BulkUserResponse{Mode: string(mode), Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case bulkStatusCreated, bulkStatusUpdated, bulkStatusDeleted:
+			res.Succeeded++
+		default:
+			res.Failed++
+		}
+	}
+
+	switch {
+	case res.Failed == 0:
+		c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgBulkUsers, res))
+	case mode == entities.BulkAtomic:
+		problem := httpresponse.NewProblem(failureStatus, CodeBulkAborted, ErrMsgBulkAborted.Error(), "")
+		problem.Result = res
+		httpresponse.AbortWithProblem(c, problem)
+	default:
+		c.JSON(http.StatusMultiStatus, httpresponse.Success(SuccessMsgBulkUsersPartly, res))
+	}
+}
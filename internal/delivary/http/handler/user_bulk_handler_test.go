@@ -0,0 +1,232 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/request"
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/response"
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/cursor"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// bulkResponse reads the bulk payload of a success response, where it sits
+// in data, and of the problem of an aborted atomic batch, where it sits in
+// result.
+type bulkResponse struct {
+	Success bool                       `json:"success"`
+	Message string                     `json:"message"`
+	Code    string                     `json:"code"`
+	Data    *response.BulkUserResponse `json:"data"`
+	Result  *response.BulkUserResponse `json:"result"`
+}
+
+func (r bulkResponse) body() *response.BulkUserResponse {
+	if r.Data != nil {
+		return r.Data
+	}
+
+	return r.Result
+}
+
+func TestUserHandler_BulkCreate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockUserUsecase{}
+
+	userHandler := handler.NewUserHandler(mockUsecase, validation.New(), cursor.NewCodec("test-secret"))
+
+	setupGinContext := func(t *testing.T, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/users/bulk", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c, w
+	}
+
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) *response.BulkUserResponse {
+		var res bulkResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		require.NotNil(t, res.body())
+		return res.body()
+	}
+
+	userJSON := func(email string) string {
+		return fmt.Sprintf(`{"name":"Test User","family":"Test Family","email":%q,"age":30}`, email)
+	}
+	user := func(email string) entities.User {
+		return entities.User{Name: "Test User", Family: "Test Family", Email: email, Age: 30}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase.On("CreateBatch", mock.Anything, []entities.User{user("a@gmail.com"), user("b@gmail.com")}, entities.BulkAtomic).
+			Return([]entities.BulkResult{{User: entities.User{ID: 1, Version: 1}}, {User: entities.User{ID: 2, Version: 1}}}, nil).Once()
+
+		c, w := setupGinContext(t, `{"users":[`+userJSON("a@gmail.com")+`,`+userJSON("b@gmail.com")+`]}`)
+
+		userHandler.BulkCreate(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		res := decode(t, w)
+		assert.Equal(t, "atomic", res.Mode)
+		assert.Equal(t, 2, res.Succeeded)
+		assert.Equal(t, []response.BulkUserResult{
+			{Index: 0, Status: "created", ID: 1, Version: 1},
+			{Index: 1, Status: "created", ID: 2, Version: 1},
+		}, res.Results)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("TooManyPasswords", func(t *testing.T) {
+		users := make([]string, request.MaxBulkPasswords+1)
+		for i := range users {
+			users[i] = fmt.Sprintf(`{"name":"Test User","family":"Test Family","email":"u%d@gmail.com","age":30,"password":"secret-password"}`, i)
+		}
+
+		c, w := setupGinContext(t, `{"users":[`+strings.Join(users, ",")+`]}`)
+
+		userHandler.BulkCreate(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "at most 100 may")
+	})
+
+	t.Run("AtomicInvalidItem", func(t *testing.T) {
+		c, w := setupGinContext(t, `{"users":[`+userJSON("a@gmail.com")+`,`+userJSON("not-an-email")+`]}`)
+
+		userHandler.BulkCreate(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		res := decode(t, w)
+		assert.Equal(t, 0, res.Succeeded)
+		assert.Equal(t, "aborted", res.Results[0].Status)
+		assert.Equal(t, "invalid", res.Results[1].Status)
+		assert.Contains(t, res.Results[1].Error, "'email'")
+	})
+
+	t.Run("AtomicDuplicate", func(t *testing.T) {
+		mockUsecase.On("CreateBatch", mock.Anything, mock.Anything, entities.BulkAtomic).
+			Return([]entities.BulkResult{{Err: usecase.ErrMsgBulkRolledBack}, {Err: usecase.ErrMsgDuplicateUser}},
+				fmt.Errorf("%w:%w", usecase.ErrMsgBulkAborted, usecase.ErrMsgDuplicateUser)).Once()
+
+		c, w := setupGinContext(t, `{"users":[`+userJSON("a@gmail.com")+`,`+userJSON("b@gmail.com")+`]}`)
+
+		userHandler.BulkCreate(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, httpresponse.ProblemContentType, w.Header().Get("Content-Type"))
+		res := decode(t, w)
+		assert.Equal(t, "aborted", res.Results[0].Status)
+		assert.Equal(t, "conflict", res.Results[1].Status)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("BestEffortPartial", func(t *testing.T) {
+		mockUsecase.On("CreateBatch", mock.Anything, []entities.User{user("a@gmail.com"), user("c@gmail.com")}, entities.BulkBestEffort).
+			Return([]entities.BulkResult{{User: entities.User{ID: 1, Version: 1}}, {Err: usecase.ErrMsgDuplicateUser}}, nil).Once()
+
+		c, w := setupGinContext(t, `{"mode":"best_effort","users":[`+userJSON("a@gmail.com")+`,`+userJSON("bad")+`,`+userJSON("c@gmail.com")+`]}`)
+
+		userHandler.BulkCreate(c)
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		res := decode(t, w)
+		assert.Equal(t, 1, res.Succeeded)
+		assert.Equal(t, 2, res.Failed)
+		assert.Equal(t, "created", res.Results[0].Status)
+		assert.Equal(t, "invalid", res.Results[1].Status)
+		assert.Equal(t, 2, res.Results[2].Index, "results keep the request index")
+		assert.Equal(t, "conflict", res.Results[2].Status)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidMode", func(t *testing.T) {
+		c, w := setupGinContext(t, `{"mode":"sometimes","users":[`+userJSON("a@gmail.com")+`]}`)
+
+		userHandler.BulkCreate(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
+
+func TestUserHandler_BulkUpdate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockUserUsecase{}
+
+	userHandler := handler.NewUserHandler(mockUsecase, validation.New(), cursor.NewCodec("test-secret"))
+
+	t.Run("Success", func(t *testing.T) {
+		age, version := 40, 2
+		mockUsecase.On("PatchBatch", mock.Anything, []entities.UserBatchPatch{
+			{ID: 7, Patch: entities.UserPatch{Age: &age, Versions: []int{version}}},
+		}, entities.BulkAtomic).Return([]entities.BulkResult{{User: entities.User{ID: 7, Version: 3}}}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPatch, "/users/bulk", strings.NewReader(`{"users":[{"id":7,"version":2,"age":40}]}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		userHandler.BulkUpdate(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res bulkResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, []response.BulkUserResult{{Index: 0, Status: "updated", ID: 7, Version: 3}}, res.body().Results)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestUserHandler_BulkDelete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockUserUsecase{}
+
+	userHandler := handler.NewUserHandler(mockUsecase, validation.New(), cursor.NewCodec("test-secret"))
+
+	setupGinContext := func(t *testing.T, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodDelete, "/users/bulk", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c, w
+	}
+
+	t.Run("BestEffortMissingID", func(t *testing.T) {
+		mockUsecase.On("DeleteBatch", mock.Anything, []uint64{1, 2}, entities.BulkBestEffort).
+			Return([]entities.BulkResult{{User: entities.User{ID: 1}}, {User: entities.User{ID: 2}, Err: usecase.ErrMsgUserNotFound}}, nil).Once()
+
+		c, w := setupGinContext(t, `{"mode":"best_effort","ids":[1,2]}`)
+
+		userHandler.BulkDelete(c)
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		var res bulkResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, []response.BulkUserResult{
+			{Index: 0, Status: "deleted", ID: 1},
+			{Index: 1, Status: "not_found", ID: 2, Error: handler.ErrMsgUserNotFound.Error()},
+		}, res.body().Results)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("RepeatedIDs", func(t *testing.T) {
+		c, w := setupGinContext(t, `{"ids":[1,1]}`)
+
+		userHandler.BulkDelete(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/request"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/userfile"
+	"github.com/gin-gonic/gin"
+)
+
+// Definition Implement Methods (Export)
+// Export streams every matching user as a csv (the default), ndjson or xlsx
+// download, flushing after each batch so the table is never buffered. Once
+// the first bytes are out the status can't change, so a failure mid-stream
+// leaves a truncated file and is only recorded on the gin context.
+func (h *UserHandler) Export(c *gin.Context) {
+	var req request.ExportUserRequest
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidQuery, err))
+		return
+	}
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return
+	}
+
+	if req.MinAge != nil && req.MaxAge != nil && *req.MinAge > *req.MaxAge {
+		respondError(c, fmt.Errorf("%w: min_age must not be greater than max_age", ErrMsgValidation))
+		return
+	}
+
+	columns, err := userfile.ParseColumns(req.Columns)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidColumns, err))
+		return
+	}
+
+	format := userfile.Format(req.Format)
+	if format == "" {
+		format = userfile.FormatCSV
+	}
+
+	filter := entities.UserFilter{
+		Name:   req.Name,
+		Family: req.Family,
+		Email:  req.Email,
+		MinAge: req.MinAge,
+		MaxAge: req.MaxAge,
+	}
+
+	c.Header("Content-Type", userfile.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="users.%s"`, format))
+	c.Status(http.StatusOK)
+
+	writer, err := userfile.NewWriter(c.Writer, format, columns)
+	if err == nil {
+		err = h.usecase.Stream(c, filter, func(users []entities.User) error {
+			for _, user := range users {
+				err := writer.Write(user)
+				if err != nil {
+					return err
+				}
+			}
+
+			err := writer.Flush()
+			c.Writer.Flush()
+			return err
+		})
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if streamAuthzError(c, err) {
+			return
+		}
+
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,82 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/cursor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUserHandler_Export(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUsecase := &MockUserUsecase{}
+
+	userHandler := handler.NewUserHandler(mockUsecase, validation.New(), cursor.NewCodec("test-secret"))
+
+	setupGinContext := func(t *testing.T, url string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, url, nil)
+		return c, w
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		minAge := 20
+		filter := entities.UserFilter{Email: "gmail", MinAge: &minAge}
+		mockUsecase.On("Stream", mock.Anything, filter, mock.Anything).Run(func(args mock.Arguments) {
+			fn := args.Get(2).(func(users []entities.User) error)
+			_ = fn([]entities.User{{ID: 1, Name: "Ali", Email: "ali@gmail.com"}})
+			_ = fn([]entities.User{{ID: 2, Name: "Sara", Email: "sara@gmail.com"}})
+		}).Return(nil).Once()
+
+		c, w := setupGinContext(t, "/users/export?email=gmail&min_age=20&columns=id,email")
+
+		userHandler.Export(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="users.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "id,email\n1,ali@gmail.com\n2,sara@gmail.com\n", w.Body.String())
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		mockUsecase.On("Stream", mock.Anything, entities.UserFilter{}, mock.Anything).Run(func(args mock.Arguments) {
+			fn := args.Get(2).(func(users []entities.User) error)
+			_ = fn([]entities.User{{ID: 1, Name: "Ali"}})
+		}).Return(nil).Once()
+
+		c, w := setupGinContext(t, "/users/export?format=ndjson&columns=name")
+
+		userHandler.Export(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, `{"name":"Ali"}`+"\n", w.Body.String())
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("UnknownColumn", func(t *testing.T) {
+		c, w := setupGinContext(t, "/users/export?columns=id,password")
+
+		userHandler.Export(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		c, w := setupGinContext(t, "/users/export?format=pdf")
+
+		userHandler.Export(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
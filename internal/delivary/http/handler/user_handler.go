@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/request"
 	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/response"
 	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/cursor"
 	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
 	"github.com/alirezaghasemi/user-manager/internal/usecase"
 	"github.com/gin-gonic/gin"
@@ -23,25 +27,41 @@ var (
 	ErrMsgUserNotFound        = errors.New("user not found")
 	ErrMsgInvalidId           = errors.New("invalid id")
 	ErrMsgValidation          = errors.New("validation error")
+	ErrMsgInvalidQuery        = errors.New("invalid query parameters")
+	ErrMsgInvalidSort         = errors.New("invalid sort parameter")
+	ErrMsgInvalidCursor       = errors.New("invalid cursor")
+	ErrMsgVersionConflict     = errors.New("user was modified concurrently")
+	ErrMsgPreconditionFailed  = errors.New("precondition failed")
+	ErrMsgInvalidBody         = errors.New("invalid request body")
+	ErrMsgInvalidColumns      = errors.New("invalid columns parameter")
+	ErrMsgBulkAborted         = errors.New("bulk operation aborted, nothing was applied")
 
 	SuccessMsgCreatedUser   = "User Created Successfully"
 	SuccessMsgFoundUserById = "found user successfully"
 	SuccessMsgUpdatedUser   = "User Updated Successfully"
 	SuccessMsgFoundAllUser  = "found users successfully"
 	SuccessMsgDeletedUser   = "User Deleted Successfully"
+	SuccessMsgRestoredUser  = "User Restored Successfully"
+
+	SuccessMsgBulkUsers       = "Bulk Operation Completed Successfully"
+	SuccessMsgBulkUsersPartly = "Bulk Operation Completed With Errors"
 )
 
+const ndjsonContentType = "application/x-ndjson"
+
 // Definition Struct (Class)
 type UserHandler struct {
 	usecase  usecase.UserUsecase
 	validate *validator.Validate
+	cursors  *cursor.Codec
 }
 
 // Definition Constructor
-func NewUserHandler(usecase usecase.UserUsecase, validate *validator.Validate) *UserHandler {
+func NewUserHandler(usecase usecase.UserUsecase, validate *validator.Validate, cursors *cursor.Codec) *UserHandler {
 	return &UserHandler{
 		usecase:  usecase,
 		validate: validate,
+		cursors:  cursors,
 	}
 }
 
@@ -51,43 +71,41 @@ func (h *UserHandler) Create(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, httpresponse.Error(ErrMsgInternalServerError.Error(), err))
+		respondError(c, invalid(ErrMsgInvalidBody, err))
 		return
 	}
 
 	err = h.validate.Struct(req)
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, httpresponse.Error(ErrMsgValidation.Error(), err))
+		respondError(c, invalid(ErrMsgValidation, err))
 		return
 	}
 
 	userCreate, err := h.usecase.Create(c, entities.User{
-		Name:   req.Name,
-		Family: req.Family,
-		Email:  req.Email,
-		Age:    req.Age,
+		Name:     req.Name,
+		Family:   req.Family,
+		Email:    req.Email,
+		Age:      req.Age,
+		Password: req.Password,
 	})
 
 	if err != nil {
-		switch {
-		case errors.Is(err, usecase.ErrMsgDuplicateUser):
-			c.JSON(http.StatusConflict, httpresponse.Error(ErrMsgDuplicateUser.Error(), err))
-			return
-		case errors.Is(err, usecase.ErrMsgFailedToSaveUser):
-			c.JSON(http.StatusInternalServerError, httpresponse.Error(ErrMsgFailedToSaveUser.Error(), err))
-			return
-		default:
-			c.JSON(http.StatusInternalServerError, httpresponse.Error(ErrMsgInternalServerError.Error(), err))
-			return
-		}
+		respondError(c, err)
+		return
 	}
 
 	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgCreatedUser, response.CreatedUserResponse{
-		ID:     userCreate.ID,
-		Name:   userCreate.Name,
-		Family: userCreate.Family,
-		Email:  userCreate.Email,
-		Age:    userCreate.Age,
+		ID:              userCreate.ID,
+		Name:            userCreate.Name,
+		Family:          userCreate.Family,
+		Email:           userCreate.Email,
+		Age:             userCreate.Age,
+		Version:         userCreate.Version,
+		CreatedAt:       userCreate.CreatedAt,
+		UpdatedAt:       userCreate.UpdatedAt,
+		CreatedBy:       userCreate.CreatedBy,
+		UpdatedBy:       userCreate.UpdatedBy,
+		EmailVerifiedAt: userCreate.EmailVerifiedAt,
 	}))
 }
 
@@ -95,27 +113,35 @@ func (h *UserHandler) Create(c *gin.Context) {
 func (h *UserHandler) FindByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, httpresponse.Error(ErrMsgInvalidId.Error(), err))
+		respondError(c, invalid(ErrMsgInvalidId, err))
 		return
 	}
 
 	user, err := h.usecase.FindByID(c, id)
 	if err != nil {
-		if errors.Is(err, usecase.ErrMsgUserNotFound) {
-			c.JSON(http.StatusNotFound, httpresponse.Error(ErrMsgUserNotFound.Error(), err))
-			return
-		}
+		respondError(c, err)
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, httpresponse.Error(ErrMsgInternalServerError.Error(), err))
+	tag := userETag(user)
+	c.Header("ETag", tag)
+	if etagMatches(c.GetHeader("If-None-Match"), tag, true) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
 	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgFoundUserById, response.FindUserByIDResponse{
-		ID:     user.ID,
-		Name:   user.Name,
-		Family: user.Family,
-		Email:  user.Email,
-		Age:    user.Age,
+		ID:              user.ID,
+		Name:            user.Name,
+		Family:          user.Family,
+		Email:           user.Email,
+		Age:             user.Age,
+		Version:         user.Version,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		CreatedBy:       user.CreatedBy,
+		UpdatedBy:       user.UpdatedBy,
+		EmailVerifiedAt: user.EmailVerifiedAt,
 	}))
 }
 
@@ -123,110 +149,193 @@ func (h *UserHandler) FindByID(c *gin.Context) {
 func (h *UserHandler) Update(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, httpresponse.Error(ErrMsgInvalidId.Error(), err))
+		respondError(c, invalid(ErrMsgInvalidId, err))
 		return
 	}
 
 	var req request.UpdateUserRequest
 	err = c.ShouldBindJSON(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, httpresponse.Error(ErrMsgInternalServerError.Error(), err))
+		respondError(c, invalid(ErrMsgInvalidBody, err))
 		return
 	}
 
 	err = h.validate.Struct(req)
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, httpresponse.Error(ErrMsgValidation.Error(), err))
+		respondError(c, invalid(ErrMsgValidation, err))
 		return
 	}
 
-	// check exists user for update
-	existingUser, err := h.usecase.FindByID(c, id)
+	// the client must have based its change on the current representation
+	ifMatch := c.GetHeader("If-Match")
+	updatedUser, err := h.usecase.Patch(c, id, entities.UserPatch{
+		Name:     req.Name,
+		Family:   req.Family,
+		Email:    req.Email,
+		Age:      req.Age,
+		Versions: ifMatchVersions(ifMatch),
+	})
 	if err != nil {
-		if errors.Is(err, usecase.ErrMsgUserNotFound) {
-			c.JSON(http.StatusNotFound, httpresponse.Error(ErrMsgUserNotFound.Error(), err))
-			return
+		// a stale If-Match, or someone else wrote between our read and write
+		if errors.Is(err, usecase.ErrMsgVersionConflict) && ifMatch != "" {
+			var conflict *usecase.VersionConflictError
+			if errors.As(err, &conflict) {
+				c.Header("ETag", versionETag(conflict.Current))
+			}
+
+			err = fmt.Errorf("%w: If-Match does not match the current version", ErrMsgPreconditionFailed)
 		}
 
-		c.JSON(http.StatusInternalServerError, httpresponse.Error(ErrMsgInternalServerError.Error(), err))
+		respondError(c, err)
 		return
 	}
 
-	if req.Name != nil {
-		existingUser.Name = *req.Name
-	}
-	if req.Family != nil {
-		existingUser.Family = *req.Family
+	c.Header("ETag", userETag(updatedUser))
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgUpdatedUser, response.UpdatedUserResponse{
+		ID:              updatedUser.ID,
+		Name:            updatedUser.Name,
+		Family:          updatedUser.Family,
+		Email:           updatedUser.Email,
+		Age:             updatedUser.Age,
+		Version:         updatedUser.Version,
+		CreatedAt:       updatedUser.CreatedAt,
+		UpdatedAt:       updatedUser.UpdatedAt,
+		CreatedBy:       updatedUser.CreatedBy,
+		UpdatedBy:       updatedUser.UpdatedBy,
+		EmailVerifiedAt: updatedUser.EmailVerifiedAt,
+	}))
+
+}
+
+func (h *UserHandler) FindAll(c *gin.Context) {
+	var req request.FindAllUserRequest
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidQuery, err))
+		return
 	}
-	if req.Email != nil {
-		existingUser.Email = *req.Email
+
+	err = h.validate.Struct(req)
+	if err != nil {
+		respondError(c, invalid(ErrMsgValidation, err))
+		return
 	}
-	if req.Age != nil {
-		existingUser.Age = *req.Age
+
+	if req.MinAge != nil && req.MaxAge != nil && *req.MinAge > *req.MaxAge {
+		respondError(c, fmt.Errorf("%w: min_age must not be greater than max_age", ErrMsgValidation))
+		return
 	}
 
-	updatedUser, err := h.usecase.Update(c, existingUser)
+	sorts, err := parseUserSort(req.Sort)
 	if err != nil {
-		if errors.Is(err, usecase.ErrMsgDuplicateUser) {
-			c.JSON(http.StatusConflict, httpresponse.Error(ErrMsgDuplicateUser.Error(), err))
-			return
-		}
+		respondError(c, invalid(ErrMsgInvalidSort, err))
+		return
+	}
 
-		if errors.Is(err, usecase.ErrMsgUserNotFound) {
-			c.JSON(http.StatusNotFound, httpresponse.Error(ErrMsgUserNotFound.Error(), err))
-			return
-		}
+	filter := entities.UserFilter{
+		Name:           req.Name,
+		Family:         req.Family,
+		Email:          req.Email,
+		MinAge:         req.MinAge,
+		MaxAge:         req.MaxAge,
+		IncludeDeleted: req.IncludeDeleted,
+	}
 
-		c.JSON(http.StatusInternalServerError, httpresponse.Error(ErrMsgInternalServerError.Error(), err))
+	if strings.Contains(c.GetHeader("Accept"), ndjsonContentType) {
+		h.streamUsers(c, filter)
 		return
 	}
 
-	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgUpdatedUser, response.UpdatedUserResponse{
-		ID:     updatedUser.ID,
-		Name:   updatedUser.Name,
-		Family: updatedUser.Family,
-		Email:  updatedUser.Email,
-		Age:    updatedUser.Age,
-	}))
+	query := entities.UserListQuery{
+		Filter: filter,
+		Sort:   sorts,
+		Limit:  listLimit(req),
+		Offset: listOffset(req),
+	}
 
-}
+	// the presence of cursor (even empty, for the first page) selects keyset paging
+	token, keyset := c.GetQuery("cursor")
+	if keyset {
+		query.Keyset = true
+		if token != "" {
+			var after entities.UserCursor
+			err = h.cursors.Decode(token, &after)
+			if err != nil || after.Field != query.KeysetSort().Field || after.Direction != query.KeysetSort().Direction {
+				respondError(c, fmt.Errorf("%w: cursor is malformed or does not match the sort", ErrMsgInvalidCursor))
+				return
+			}
+			query.After = &after
+		}
+	}
 
-func (h *UserHandler) FindAll(c *gin.Context) {
-	users, err := h.usecase.FindAll(c)
+	page, err := h.usecase.FindAll(c, query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, httpresponse.Error(ErrMsgInternalServerError.Error(), err))
+		respondError(c, err)
 		return
 	}
 
-	var res []response.FindAllUserResponse
-	for _, user := range users {
-		res = append(res, response.FindAllUserResponse{
-			ID:     user.ID,
-			Name:   user.Name,
-			Family: user.Family,
-			Email:  user.Email,
-			Age:    user.Age,
-		})
+	res := make([]response.FindAllUserResponse, 0, len(page.Users))
+	for _, user := range page.Users {
+		res = append(res, findAllUserResponse(user))
+	}
+
+	if keyset {
+		meta := httpresponse.Cursor{PerPage: page.Limit, HasMore: page.Next != nil}
+		if page.Next != nil {
+			meta.NextCursor, err = h.cursors.Encode(page.Next)
+			if err != nil {
+				respondError(c, err)
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, httpresponse.CursorPaginated(SuccessMsgFoundAllUser, res, meta))
+		return
 	}
 
-	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgFoundAllUser, res))
+	c.JSON(http.StatusOK, httpresponse.Paginated(SuccessMsgFoundAllUser, res, httpresponse.NewPagination(page.Total, page.Limit, page.Offset)))
+}
+
+// streamUsers writes every matching user as one JSON object per line, flushing
+// after each batch so the response is never buffered as a whole. Once the
+// first line is out the status can't change, so a failure mid-stream is
+// reported as a trailing {"error": ...} line.
+func (h *UserHandler) streamUsers(c *gin.Context, filter entities.UserFilter) {
+	c.Header("Content-Type", ndjsonContentType)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	err := h.usecase.Stream(c, filter, func(users []entities.User) error {
+		for _, user := range users {
+			err := encoder.Encode(findAllUserResponse(user))
+			if err != nil {
+				return err
+			}
+		}
+
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		if streamAuthzError(c, err) {
+			return
+		}
+
+		_ = encoder.Encode(gin.H{"error": ErrMsgInternalServerError.Error()})
+		c.Writer.Flush()
+	}
 }
 
 func (h *UserHandler) Delete(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, httpresponse.Error(ErrMsgInvalidId.Error(), err))
+		respondError(c, invalid(ErrMsgInvalidId, err))
 		return
 	}
 
 	err = h.usecase.Delete(c, id)
 	if err != nil {
-		if errors.Is(err, usecase.ErrMsgUserNotFound) {
-			c.JSON(http.StatusNotFound, httpresponse.Error(ErrMsgUserNotFound.Error(), err))
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, httpresponse.Error(ErrMsgInternalServerError.Error(), err))
+		respondError(c, err)
 		return
 	}
 
@@ -234,3 +343,185 @@ func (h *UserHandler) Delete(c *gin.Context) {
 		ID: id,
 	}))
 }
+
+// Restore brings back a soft-deleted user.
+func (h *UserHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, invalid(ErrMsgInvalidId, err))
+		return
+	}
+
+	user, err := h.usecase.Restore(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("ETag", userETag(user))
+	c.JSON(http.StatusOK, httpresponse.Success(SuccessMsgRestoredUser, response.RestoredUserResponse{
+		ID:              user.ID,
+		Name:            user.Name,
+		Family:          user.Family,
+		Email:           user.Email,
+		Age:             user.Age,
+		Version:         user.Version,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		CreatedBy:       user.CreatedBy,
+		UpdatedBy:       user.UpdatedBy,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+	}))
+}
+
+// userETag is the strong entity tag of a user representation, derived from
+// its version.
+func userETag(user entities.User) string {
+	return versionETag(user.Version)
+}
+
+// versionETag is the entity tag of the representation of a user at version.
+func versionETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// etagMatches reports whether an If-Match / If-None-Match header value matches
+// tag. Weak comparison, used for If-None-Match, ignores the W/ prefix.
+func etagMatches(header string, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+
+		if candidate == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ifMatchVersions turns an If-Match header into the versions an update may
+// apply to: nil for an absent header or "*", otherwise the versions the
+// header lists as strong tags. Weak tags never match, If-Match comparing
+// strongly, so a header listing none of them leaves no version to apply to.
+func ifMatchVersions(header string) []int {
+	if strings.TrimSpace(header) == "" || etagMatches(header, "*", false) {
+		return nil
+	}
+
+	versions := []int{}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if len(candidate) < 2 || candidate[0] != '"' || candidate[len(candidate)-1] != '"' {
+			continue
+		}
+
+		version, err := strconv.Atoi(candidate[1 : len(candidate)-1])
+		if err != nil {
+			continue
+		}
+
+		if etagMatches(header, versionETag(version), false) {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions
+}
+
+func findAllUserResponse(user entities.User) response.FindAllUserResponse {
+	res := response.FindAllUserResponse{
+		ID:              user.ID,
+		Name:            user.Name,
+		Family:          user.Family,
+		Email:           user.Email,
+		Age:             user.Age,
+		Version:         user.Version,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		CreatedBy:       user.CreatedBy,
+		UpdatedBy:       user.UpdatedBy,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+	}
+	if user.DeletedAt.Valid {
+		deletedAt := user.DeletedAt.Time
+		res.DeletedAt = &deletedAt
+	}
+
+	return res
+}
+
+// listLimit resolves the page size from limit or per_page, zero means default.
+func listLimit(req request.FindAllUserRequest) int {
+	if req.Limit != nil {
+		return *req.Limit
+	}
+
+	return req.PerPage
+}
+
+// listOffset resolves the offset from offset or page.
+func listOffset(req request.FindAllUserRequest) int {
+	if req.Offset != nil {
+		return *req.Offset
+	}
+
+	if req.Page <= 1 {
+		return 0
+	}
+
+	perPage := req.PerPage
+	if perPage <= 0 {
+		perPage = entities.DefaultUserListLimit
+	}
+
+	return (req.Page - 1) * perPage
+}
+
+// parseUserSort parses "name:asc,age:desc" (a leading "-" also means desc)
+// into the typed sort spec.
+func parseUserSort(raw string) ([]entities.UserSort, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var sorts []entities.UserSort
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		direction := entities.SortAsc
+		field := part
+		if strings.HasPrefix(part, "-") {
+			direction = entities.SortDesc
+			field = part[1:]
+		} else if name, dir, ok := strings.Cut(part, ":"); ok {
+			field = name
+			switch entities.SortDirection(strings.ToLower(dir)) {
+			case entities.SortAsc:
+				direction = entities.SortAsc
+			case entities.SortDesc:
+				direction = entities.SortDesc
+			default:
+				return nil, fmt.Errorf("unknown sort direction %q", dir)
+			}
+		}
+
+		sortField := entities.UserSortField(strings.ToLower(field))
+		if !sortField.IsValid() {
+			return nil, fmt.Errorf("unknown sort field %q", field)
+		}
+
+		sorts = append(sorts, entities.UserSort{Field: sortField, Direction: direction})
+	}
+
+	return sorts, nil
+}
@@ -7,17 +7,21 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alirezaghasemi/user-manager/internal/delivary/http/dto/response"
 	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
 	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/cursor"
 	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
 	"github.com/alirezaghasemi/user-manager/internal/usecase"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockUserUsecase struct {
@@ -39,9 +43,43 @@ func (m *MockUserUsecase) Update(ctx context.Context, user entities.User) (entit
 	return args.Get(0).(entities.User), args.Error(1)
 }
 
-func (m *MockUserUsecase) FindAll(ctx context.Context) ([]entities.User, error) {
-	args := m.Called(ctx)
-	return args.Get(0).([]entities.User), args.Error(1)
+func (m *MockUserUsecase) Patch(ctx context.Context, id uint64, patch entities.UserPatch) (entities.User, error) {
+	args := m.Called(ctx, id, patch)
+	return args.Get(0).(entities.User), args.Error(1)
+}
+
+func (m *MockUserUsecase) CreateBatch(ctx context.Context, users []entities.User, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	args := m.Called(ctx, users, mode)
+	results, _ := args.Get(0).([]entities.BulkResult)
+	return results, args.Error(1)
+}
+
+func (m *MockUserUsecase) PatchBatch(ctx context.Context, patches []entities.UserBatchPatch, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	args := m.Called(ctx, patches, mode)
+	results, _ := args.Get(0).([]entities.BulkResult)
+	return results, args.Error(1)
+}
+
+func (m *MockUserUsecase) DeleteBatch(ctx context.Context, ids []uint64, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	args := m.Called(ctx, ids, mode)
+	results, _ := args.Get(0).([]entities.BulkResult)
+	return results, args.Error(1)
+}
+
+func (m *MockUserUsecase) Import(ctx context.Context, users []entities.User, options entities.ImportOptions) ([]entities.ImportResult, error) {
+	args := m.Called(ctx, users, options)
+	results, _ := args.Get(0).([]entities.ImportResult)
+	return results, args.Error(1)
+}
+
+func (m *MockUserUsecase) FindAll(ctx context.Context, query entities.UserListQuery) (entities.UserPage, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(entities.UserPage), args.Error(1)
+}
+
+func (m *MockUserUsecase) Stream(ctx context.Context, filter entities.UserFilter, fn func(users []entities.User) error) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
 }
 
 func (m *MockUserUsecase) Delete(ctx context.Context, id uint64) error {
@@ -49,14 +87,24 @@ func (m *MockUserUsecase) Delete(ctx context.Context, id uint64) error {
 	return args.Error(0)
 }
 
+func (m *MockUserUsecase) Restore(ctx context.Context, id uint64) (entities.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.User), args.Error(1)
+}
+
+func (m *MockUserUsecase) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	args := m.Called(ctx, olderThan)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestUserHandler_FindByID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	validate := validator.New()
+	validate := validation.New()
 
 	mockUsecase := &MockUserUsecase{}
 
-	userHandler := handler.NewUserHandler(mockUsecase, validate)
+	userHandler := handler.NewUserHandler(mockUsecase, validate, cursor.NewCodec("test-secret"))
 
 	// ctx := context.Background()
 	userID := uint64(1)
@@ -118,7 +166,36 @@ func TestUserHandler_FindByID(t *testing.T) {
 		}
 
 		assert.Equal(t, expectedResponse, actualResponse)
-		assert.Nil(t, res.Errors, "errors should be nil")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("ETag", func(t *testing.T) {
+		versioned := expectedUser
+		versioned.Version = 3
+		mockUsecase.On("FindByID", mock.Anything, userID).Return(versioned, nil).Once()
+
+		c, w := setupGinContext(t, fmt.Sprintf("/users/%d", userID), gin.Params{{Key: "id", Value: fmt.Sprintf("%d", userID)}})
+
+		userHandler.FindByID(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("NotModified", func(t *testing.T) {
+		versioned := expectedUser
+		versioned.Version = 3
+		mockUsecase.On("FindByID", mock.Anything, userID).Return(versioned, nil).Once()
+
+		c, w := setupGinContext(t, fmt.Sprintf("/users/%d", userID), gin.Params{{Key: "id", Value: fmt.Sprintf("%d", userID)}})
+		c.Request.Header.Set("If-None-Match", `"2", W/"3"`)
+
+		userHandler.FindByID(c)
+		c.Writer.WriteHeaderNow()
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
 		mockUsecase.AssertExpectations(t)
 	})
 
@@ -128,14 +205,16 @@ func TestUserHandler_FindByID(t *testing.T) {
 		userHandler.FindByID(c)
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
-		var res httpresponse.APIResponse
+		assert.Equal(t, httpresponse.ProblemContentType, w.Header().Get("Content-Type"))
+		var res httpresponse.Problem
 		err := json.NewDecoder(w.Body).Decode(&res)
 		assert.NoError(t, err)
-		assert.False(t, res.Success, "response should not be successful")
-		assert.Equal(t, handler.ErrMsgInvalidId.Error(), res.Message)
-		assert.NotNil(t, res.Errors, "errors should not be nil")
-		// assert.Contains(t, fmt.Sprintf("%v", res.Errors), "invalid id")
-		assert.Nil(t, res.Data, "data should be nil")
+		assert.Equal(t, handler.CodeInvalidID, res.Code)
+		assert.Equal(t, httpresponse.ProblemTypeBase+handler.CodeInvalidID, res.Type)
+		assert.Equal(t, handler.ErrMsgInvalidId.Error(), res.Title)
+		assert.Equal(t, http.StatusBadRequest, res.Status)
+		assert.Contains(t, res.Detail, "invalid syntax")
+		assert.Equal(t, "/users/invalid", res.Instance)
 		mockUsecase.AssertNotCalled(t, "FindByID", mock.Anything, "invalid")
 	})
 
@@ -151,14 +230,11 @@ func TestUserHandler_FindByID(t *testing.T) {
 
 		// بررسی نتایج
 		assert.Equal(t, http.StatusNotFound, w.Code)
-		var res httpresponse.APIResponse
+		var res httpresponse.Problem
 		err := json.NewDecoder(w.Body).Decode(&res)
 		assert.NoError(t, err)
-		assert.False(t, res.Success, "response should not be successful")
-		assert.Equal(t, handler.ErrMsgUserNotFound.Error(), res.Message)
-		assert.NotNil(t, res.Errors, "errors should not be nil")
-		// assert.Contains(t, fmt.Sprintf("%v", res.Errors), "user not found")
-		assert.Nil(t, res.Data, "data should be nil")
+		assert.Equal(t, handler.CodeUserNotFound, res.Code)
+		assert.Equal(t, handler.ErrMsgUserNotFound.Error(), res.Title)
 		mockUsecase.AssertExpectations(t)
 	})
 
@@ -177,14 +253,411 @@ func TestUserHandler_FindByID(t *testing.T) {
 
 		// بررسی نتایج
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		var res httpresponse.Problem
+		err := json.NewDecoder(w.Body).Decode(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.CodeInternal, res.Code)
+		assert.Equal(t, handler.ErrMsgInternalServerError.Error(), res.Title)
+		assert.Empty(t, res.Detail, "the cause of an internal error stays private")
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestUserHandler_FindAll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validate := validation.New()
+
+	mockUsecase := &MockUserUsecase{}
+
+	userHandler := handler.NewUserHandler(mockUsecase, validate, cursor.NewCodec("test-secret"))
+
+	setupGinContext := func(t *testing.T, target string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		minAge := 20
+		expectedQuery := entities.UserListQuery{
+			Filter: entities.UserFilter{Name: "ali", MinAge: &minAge},
+			Sort: []entities.UserSort{
+				{Field: entities.UserSortByAge, Direction: entities.SortDesc},
+				{Field: entities.UserSortByName, Direction: entities.SortAsc},
+			},
+			Limit:  10,
+			Offset: 20,
+		}
+
+		mockUsecase.On("FindAll", mock.Anything, expectedQuery).Return(entities.UserPage{
+			Users:  []entities.User{{ID: 21, Name: "Ali", Family: "Test Family", Email: "ali@gmail.com", Age: 30}},
+			Total:  21,
+			Limit:  10,
+			Offset: 20,
+		}, nil).Once()
+
+		c, w := setupGinContext(t, "/users?page=3&per_page=10&name=ali&min_age=20&sort=age:desc,name")
+
+		userHandler.FindAll(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res httpresponse.APIResponse
+		err := json.NewDecoder(w.Body).Decode(&res)
+		assert.NoError(t, err)
+		assert.True(t, res.Success, "response should be successful")
+		assert.Equal(t, handler.SuccessMsgFoundAllUser, res.Message)
+		assert.Equal(t, &httpresponse.Pagination{Page: 3, PerPage: 10, Offset: 20, Total: 21, TotalPages: 3}, res.Pagination)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("LimitOffset", func(t *testing.T) {
+		expectedQuery := entities.UserListQuery{
+			Sort:   []entities.UserSort{{Field: entities.UserSortByEmail, Direction: entities.SortDesc}},
+			Limit:  5,
+			Offset: 7,
+		}
+		mockUsecase.On("FindAll", mock.Anything, expectedQuery).Return(entities.UserPage{Users: []entities.User{}, Limit: 5, Offset: 7}, nil).Once()
+
+		c, w := setupGinContext(t, "/users?limit=5&offset=7&sort=-email")
+
+		userHandler.FindAll(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("KeysetFirstPage", func(t *testing.T) {
+		sort := entities.UserSort{Field: entities.UserSortByName, Direction: entities.SortAsc}
+		expectedQuery := entities.UserListQuery{Sort: []entities.UserSort{sort}, Limit: 2, Keyset: true}
+		mockUsecase.On("FindAll", mock.Anything, expectedQuery).Return(entities.UserPage{
+			Users: []entities.User{{ID: 4, Name: "Ali"}, {ID: 2, Name: "Reza"}},
+			Limit: 2,
+			Next:  &entities.UserCursor{ID: 2, Field: entities.UserSortByName, Direction: entities.SortAsc, Value: "Reza"},
+		}, nil).Once()
+
+		c, w := setupGinContext(t, "/users?cursor=&per_page=2&sort=name")
+
+		userHandler.FindAll(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
 		var res httpresponse.APIResponse
 		err := json.NewDecoder(w.Body).Decode(&res)
 		assert.NoError(t, err)
-		assert.False(t, res.Success, "response should not be successful")
-		assert.Equal(t, handler.ErrMsgInternalServerError.Error(), res.Message)
-		assert.NotNil(t, res.Errors, "errors should not be nil")
-		// assert.Contains(t, fmt.Sprintf("%v", res.Errors), "database error")
-		assert.Nil(t, res.Data, "data should be nil")
+		assert.Nil(t, res.Pagination)
+		assert.NotNil(t, res.Cursor)
+		assert.True(t, res.Cursor.HasMore)
+		assert.NotEmpty(t, res.Cursor.NextCursor)
+		mockUsecase.AssertExpectations(t)
+
+		// the returned cursor positions the next page
+		after := &entities.UserCursor{ID: 2, Field: entities.UserSortByName, Direction: entities.SortAsc, Value: "Reza"}
+		nextQuery := entities.UserListQuery{Sort: []entities.UserSort{sort}, Limit: 2, Keyset: true, After: after}
+		mockUsecase.On("FindAll", mock.Anything, nextQuery).Return(entities.UserPage{Users: []entities.User{}, Limit: 2}, nil).Once()
+
+		c, w = setupGinContext(t, "/users?per_page=2&sort=name&cursor="+res.Cursor.NextCursor)
+
+		userHandler.FindAll(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("KeysetCursorForOtherSort", func(t *testing.T) {
+		token, err := cursor.NewCodec("test-secret").Encode(entities.UserCursor{ID: 2, Field: entities.UserSortByAge, Direction: entities.SortAsc, Value: "30"})
+		assert.NoError(t, err)
+
+		c, w := setupGinContext(t, "/users?sort=name&cursor="+token)
+
+		userHandler.FindAll(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("KeysetForgedCursor", func(t *testing.T) {
+		token, err := cursor.NewCodec("other-secret").Encode(entities.UserCursor{ID: 2, Field: entities.UserSortByID, Direction: entities.SortAsc, Value: "2"})
+		assert.NoError(t, err)
+
+		c, w := setupGinContext(t, "/users?cursor="+token)
+
+		userHandler.FindAll(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("StreamNDJSON", func(t *testing.T) {
+		filter := entities.UserFilter{Family: "test"}
+		mockUsecase.On("Stream", mock.Anything, filter, mock.Anything).Run(func(args mock.Arguments) {
+			fn := args.Get(2).(func(users []entities.User) error)
+			_ = fn([]entities.User{{ID: 1, Name: "Ali"}, {ID: 2, Name: "Reza"}})
+			_ = fn([]entities.User{{ID: 3, Name: "Sara"}})
+		}).Return(nil).Once()
+
+		c, w := setupGinContext(t, "/users?family=test")
+		c.Request.Header.Set("Accept", "application/x-ndjson")
+
+		userHandler.FindAll(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		assert.Len(t, lines, 3)
+		var last response.FindAllUserResponse
+		assert.NoError(t, json.Unmarshal([]byte(lines[2]), &last))
+		assert.Equal(t, uint64(3), last.ID)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("IncludeDeleted", func(t *testing.T) {
+		deletedAt := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+		expectedQuery := entities.UserListQuery{Filter: entities.UserFilter{IncludeDeleted: true}}
+		mockUsecase.On("FindAll", mock.Anything, expectedQuery).Return(entities.UserPage{
+			Users: []entities.User{{ID: 7, Name: "Ali", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}}},
+			Total: 1,
+			Limit: entities.DefaultUserListLimit,
+		}, nil).Once()
+
+		c, w := setupGinContext(t, "/users?include_deleted=true")
+
+		userHandler.FindAll(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var res struct {
+			Data []response.FindAllUserResponse `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Len(t, res.Data, 1)
+		assert.NotNil(t, res.Data[0].DeletedAt)
+		assert.True(t, deletedAt.Equal(*res.Data[0].DeletedAt))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidSort", func(t *testing.T) {
+		c, w := setupGinContext(t, "/users?sort=password:asc")
+
+		userHandler.FindAll(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var res httpresponse.Problem
+		err := json.NewDecoder(w.Body).Decode(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.CodeInvalidSort, res.Code)
+		assert.Equal(t, `unknown sort field "password"`, res.Detail)
+	})
+
+	t.Run("InvalidAgeRange", func(t *testing.T) {
+		c, w := setupGinContext(t, "/users?min_age=40&max_age=30")
+
+		userHandler.FindAll(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("PerPageTooLarge", func(t *testing.T) {
+		c, w := setupGinContext(t, "/users?per_page=1000")
+
+		userHandler.FindAll(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
+
+func TestUserHandler_Restore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validate := validation.New()
+
+	mockUsecase := &MockUserUsecase{}
+
+	userHandler := handler.NewUserHandler(mockUsecase, validate, cursor.NewCodec("test-secret"))
+
+	userID := uint64(1)
+
+	setupGinContext := func(t *testing.T, id string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/users/"+id+"/restore", nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		verifiedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+		mockUsecase.On("Restore", mock.Anything, userID).Return(entities.User{ID: userID, Name: "Test User", Version: 4, EmailVerifiedAt: &verifiedAt}, nil).Once()
+
+		c, w := setupGinContext(t, "1")
+
+		userHandler.Restore(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), `"email_verified_at":"2026-10-17T12:00:00Z"`)
+		var res httpresponse.APIResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, handler.SuccessMsgRestoredUser, res.Message)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("UserNotFound", func(t *testing.T) {
+		mockUsecase.On("Restore", mock.Anything, userID).Return(entities.User{}, usecase.ErrMsgUserNotFound).Once()
+
+		c, w := setupGinContext(t, "1")
+
+		userHandler.Restore(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("EmailTaken", func(t *testing.T) {
+		mockUsecase.On("Restore", mock.Anything, userID).Return(entities.User{}, usecase.ErrMsgDuplicateUser).Once()
+
+		c, w := setupGinContext(t, "1")
+
+		userHandler.Restore(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase.On("Restore", mock.Anything, userID).Return(entities.User{}, fmt.Errorf("%w: %s", usecase.ErrMsgForbidden, entities.PermUsersDelete)).Once()
+
+		c, w := setupGinContext(t, "1")
+
+		userHandler.Restore(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var res httpresponse.Problem
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, handler.CodeForbidden, res.Code)
+		assert.Equal(t, "forbidden: users:delete", res.Detail)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		mockUsecase.On("Restore", mock.Anything, userID).Return(entities.User{}, usecase.ErrMsgUnauthenticated).Once()
+
+		c, w := setupGinContext(t, "1")
+
+		userHandler.Restore(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestUserHandler_Update(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validate := validation.New()
+
+	mockUsecase := &MockUserUsecase{}
+
+	userHandler := handler.NewUserHandler(mockUsecase, validate, cursor.NewCodec("test-secret"))
+
+	userID := uint64(1)
+	existingUser := entities.User{
+		ID:      userID,
+		Name:    "Test User",
+		Family:  "Test Family",
+		Email:   "test@gmail.com",
+		Age:     31,
+		Version: 2,
+	}
+
+	setupGinContext := func(t *testing.T, body string, ifMatch string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			c.Request.Header.Set("If-Match", ifMatch)
+		}
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		verifiedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+		updated := existingUser
+		updated.Name = "New Name"
+		updated.Version = 3
+		updated.EmailVerifiedAt = &verifiedAt
+
+		name, version := "New Name", 2
+		mockUsecase.On("Patch", mock.Anything, userID, entities.UserPatch{Name: &name, Versions: []int{version}}).Return(updated, nil).Once()
+
+		c, w := setupGinContext(t, `{"name":"New Name"}`, `"2"`)
+
+		userHandler.Update(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), `"email_verified_at":"2026-10-17T12:00:00Z"`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("AnyIfMatch", func(t *testing.T) {
+		age := 40
+		mockUsecase.On("Patch", mock.Anything, userID, entities.UserPatch{Age: &age}).Return(existingUser, nil).Once()
+
+		c, w := setupGinContext(t, `{"age":40}`, "*")
+
+		userHandler.Update(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("IfMatchList", func(t *testing.T) {
+		name := "New Name"
+		mockUsecase.On("Patch", mock.Anything, userID, entities.UserPatch{Name: &name, Versions: []int{1, 2}}).Return(existingUser, nil).Once()
+
+		c, w := setupGinContext(t, `{"name":"New Name"}`, `"1", W/"3", "2"`)
+
+		userHandler.Update(c)
+
+		assert.Equal(t, http.StatusOK, w.Code, "any strong tag of the list may match")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("StaleIfMatch", func(t *testing.T) {
+		name, version := "New Name", 1
+		mockUsecase.On("Patch", mock.Anything, userID, entities.UserPatch{Name: &name, Versions: []int{version}}).Return(entities.User{}, &usecase.VersionConflictError{Current: 2}).Once()
+
+		c, w := setupGinContext(t, `{"name":"New Name"}`, `"1"`)
+
+		userHandler.Update(c)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"), "the client learns the current version")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("WeakIfMatch", func(t *testing.T) {
+		name := "New Name"
+		mockUsecase.On("Patch", mock.Anything, userID, entities.UserPatch{Name: &name, Versions: []int{}}).Return(entities.User{}, &usecase.VersionConflictError{Current: 2}).Once()
+
+		c, w := setupGinContext(t, `{"name":"New Name"}`, `W/"2"`)
+
+		userHandler.Update(c)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code, "If-Match compares strongly")
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("ConcurrentWriteWithoutIfMatch", func(t *testing.T) {
+		mockUsecase.On("Patch", mock.Anything, userID, mock.Anything).Return(entities.User{}, usecase.ErrMsgVersionConflict).Once()
+
+		c, w := setupGinContext(t, `{"age":40}`, "")
+
+		userHandler.Update(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
 		mockUsecase.AssertExpectations(t)
 	})
 }
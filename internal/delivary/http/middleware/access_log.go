@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// RouteUnmatched is logged as the route of requests no route matched.
+const RouteUnmatched = "unmatched"
+
+// AccessLog writes one record per request to logger once it was served. The
+// route is the template, e.g. /api/v1/user/:id, so requests for different
+// users group together. Server errors are logged at error level, client
+// errors at warn level, with the errors handlers put on the gin context.
+func AccessLog(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = RouteUnmatched
+		}
+
+		status := c.Writer.Status()
+		// gin reports -1 until something was written
+		size := max(c.Writer.Size(), 0)
+		attrs := []slog.Attr{
+			slog.String("request_id", requestid.FromContext(c.Request.Context())),
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", size),
+			slog.String("user_agent", c.Request.UserAgent()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", strings.Join(c.Errors.Errors(), "; ")))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "http request", attrs...)
+	}
+}
@@ -0,0 +1,79 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, nil))
+
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.AccessLog(logger))
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	router.GET("/broken", func(c *gin.Context) {
+		_ = c.Error(errors.New("dial tcp: connection refused"))
+		c.Status(http.StatusInternalServerError)
+	})
+
+	serve := func(t *testing.T, path string) map[string]any {
+		t.Helper()
+		out.Reset()
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(middleware.RequestIDHeader, "req-1")
+		req.Header.Set("User-Agent", "curl/8.0")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+		return record
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		record := serve(t, "/users/42")
+
+		assert.Equal(t, "INFO", record["level"])
+		assert.Equal(t, "http request", record["msg"])
+		assert.Equal(t, "req-1", record["request_id"])
+		assert.Equal(t, http.MethodGet, record["method"])
+		assert.Equal(t, "/users/:id", record["route"])
+		assert.Equal(t, "/users/42", record["path"])
+		assert.EqualValues(t, http.StatusOK, record["status"])
+		assert.EqualValues(t, len("hello"), record["bytes"])
+		assert.Equal(t, "curl/8.0", record["user_agent"])
+		assert.Contains(t, record, "latency_ms")
+		assert.Contains(t, record, "client_ip")
+		assert.NotContains(t, record, "error")
+	})
+
+	t.Run("ServerError", func(t *testing.T) {
+		record := serve(t, "/broken")
+
+		assert.Equal(t, "ERROR", record["level"])
+		assert.Equal(t, "dial tcp: connection refused", record["error"])
+	})
+
+	t.Run("Unmatched", func(t *testing.T) {
+		record := serve(t, "/nowhere")
+
+		assert.Equal(t, "WARN", record["level"])
+		assert.Equal(t, middleware.RouteUnmatched, record["route"])
+		assert.EqualValues(t, http.StatusNotFound, record["status"])
+	})
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/token"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries an API key as an alternative to "Authorization: Bearer".
+const APIKeyHeader = "X-API-Key"
+
+// Definition Error Message
+var (
+	ErrMsgInvalidAPIKey = errors.New("invalid api key")
+)
+
+// AuthenticateAPIKey accepts an API key from the X-API-Key header or as an
+// "Authorization: Bearer" value, and hands every other request to fallback.
+// Unknown, expired and revoked keys are rejected with 401; accepted requests
+// carry the service account as principal and actor.
+func AuthenticateAPIKey(keys usecase.APIKeyUsecase, fallback gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := strings.TrimSpace(c.GetHeader(APIKeyHeader))
+		if raw == "" {
+			scheme, bearer, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+			bearer = strings.TrimSpace(bearer)
+			if ok && strings.EqualFold(scheme, "Bearer") && token.IsAPIKey(bearer) {
+				raw = bearer
+			}
+		}
+
+		if raw == "" {
+			fallback(c)
+			return
+		}
+
+		caller, err := keys.Authenticate(c.Request.Context(), raw)
+		if err != nil {
+			if errors.Is(err, usecase.ErrMsgInvalidAPIKey) {
+				unauthorized(c, ErrMsgInvalidAPIKey, `Bearer error="invalid_token"`)
+				return
+			}
+
+			_ = c.Error(err)
+			reject(c, http.StatusInternalServerError, usecase.ErrMsgInternalServerError)
+			return
+		}
+
+		ctx := principal.WithPrincipal(c.Request.Context(), caller)
+		ctx = actor.WithActor(ctx, caller.Actor())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,91 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/middleware"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/token"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := clock.Fixed(time.Now().UTC())
+	tokens := token.NewManager(token.Options{Secret: "test-secret", Issuer: "user-manager", TTL: time.Minute}, now)
+	keys := usecase.NewAPIKeyUsecase(repository.NewMemoryAPIKeyRepository(now), usecase.AllowAll(), now)
+
+	key, raw, err := keys.Create(context.Background(), "billing", entities.Scopes{entities.ScopeUsersRead}, nil)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(middleware.AuthenticateAPIKey(keys, middleware.Authenticate(tokens)))
+
+	var seen entities.Principal
+	var seenActor string
+	router.GET("/", func(c *gin.Context) {
+		seen, _ = principal.FromContext(c)
+		seenActor = actor.FromContext(c)
+	})
+
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		seen = entities.Principal{}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("APIKeyHeader", func(t *testing.T) {
+		w := serve(middleware.APIKeyHeader, raw)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, key.ID, seen.APIKeyID)
+		assert.Equal(t, "service:billing", seenActor)
+	})
+
+	t.Run("Bearer", func(t *testing.T) {
+		w := serve("Authorization", "Bearer "+raw)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, key.ID, seen.APIKeyID)
+	})
+
+	t.Run("InvalidKey", func(t *testing.T) {
+		w := serve(middleware.APIKeyHeader, raw+"x")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), middleware.ErrMsgInvalidAPIKey.Error())
+	})
+
+	t.Run("FallsBackToAccessToken", func(t *testing.T) {
+		issued, err := tokens.Issue(entities.Principal{UserID: 7})
+		require.NoError(t, err)
+
+		w := serve("Authorization", "Bearer "+issued.AccessToken)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, uint64(7), seen.UserID)
+
+		w = serve("", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), middleware.ErrMsgMissingToken.Error())
+	})
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/token"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// Definition Error Message
+var (
+	ErrMsgMissingToken = errors.New("missing access token")
+	ErrMsgInvalidToken = errors.New("invalid access token")
+	ErrMsgExpiredToken = errors.New("access token has expired")
+)
+
+// Authenticate rejects requests without a valid "Authorization: Bearer"
+// access token with 401. Accepted requests carry the principal in their
+// context, and it is recorded as the actor of any change they make.
+func Authenticate(tokens token.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, raw, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(raw) == "" {
+			unauthorized(c, ErrMsgMissingToken, `Bearer`)
+			return
+		}
+
+		caller, err := tokens.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			if errors.Is(err, token.ErrMsgExpiredToken) {
+				unauthorized(c, ErrMsgExpiredToken, `Bearer error="invalid_token", error_description="the access token expired"`)
+				return
+			}
+
+			unauthorized(c, ErrMsgInvalidToken, `Bearer error="invalid_token"`)
+			return
+		}
+
+		ctx := principal.WithPrincipal(c.Request.Context(), caller)
+		ctx = actor.WithActor(ctx, caller.Actor())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// problemCodes names the errors the middleware rejects requests with in
+// problem responses.
+var problemCodes = map[error]string{
+	ErrMsgMissingToken:      "missing_token",
+	ErrMsgInvalidToken:      "invalid_token",
+	ErrMsgExpiredToken:      "expired_token",
+	ErrMsgInvalidAPIKey:     "invalid_api_key",
+	ErrMsgMissingUserHeader: "missing_user_header",
+	ErrMsgInvalidUserHeader: "invalid_user_header",
+
+	usecase.ErrMsgInternalServerError: "internal_error",
+}
+
+// unauthorized aborts with 401 and the challenge telling clients how to
+// authenticate.
+func unauthorized(c *gin.Context, err error, challenge string) {
+	c.Header("WWW-Authenticate", challenge)
+	reject(c, http.StatusUnauthorized, err)
+}
+
+// reject aborts with a problem of status for err.
+func reject(c *gin.Context, status int, err error) {
+	httpresponse.AbortWithProblem(c, httpresponse.NewProblem(status, problemCodes[err], err.Error(), ""))
+}
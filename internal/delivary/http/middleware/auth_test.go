@@ -0,0 +1,94 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/middleware"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/token"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Now().UTC()
+	options := token.Options{Secret: "test-secret", Issuer: "user-manager", TTL: time.Minute}
+	tokens := token.NewManager(options, clock.Fixed(now))
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(middleware.Authenticate(tokens))
+
+	var seen entities.Principal
+	var seenActor string
+	router.GET("/", func(c *gin.Context) {
+		seen, _ = principal.FromContext(c)
+		seenActor = actor.FromContext(c)
+	})
+
+	caller := entities.Principal{UserID: 7, Email: "ali@gmail.com"}
+	issued, err := tokens.Issue(caller)
+	assert.NoError(t, err)
+
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Actor", "mallory")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("ValidToken", func(t *testing.T) {
+		w := serve("Bearer " + issued.AccessToken)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, caller, seen)
+		assert.Equal(t, "user:7", seenActor, "a client can't name the actor")
+	})
+
+	t.Run("MissingToken", func(t *testing.T) {
+		w := serve("")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+		assert.Equal(t, httpresponse.ProblemContentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), middleware.ErrMsgMissingToken.Error())
+		assert.Contains(t, w.Body.String(), `"code":"missing_token"`)
+	})
+
+	t.Run("WrongScheme", func(t *testing.T) {
+		w := serve("Basic " + issued.AccessToken)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		w := serve("Bearer not-a-token")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), middleware.ErrMsgInvalidToken.Error())
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		old, err := token.NewManager(options, clock.Fixed(now.Add(-time.Hour))).Issue(caller)
+		assert.NoError(t, err)
+
+		w := serve("Bearer " + old.AccessToken)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), middleware.ErrMsgExpiredToken.Error())
+	})
+}
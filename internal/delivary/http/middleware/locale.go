@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// Locale puts the translator of the language Accept-Language prefers in the
+// request context, validation errors are reported in it.
+func Locale(translator *validation.Translator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		trans := translator.Match(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(validation.WithTranslator(c.Request.Context(), trans))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics observes how long every request took to serve, by method, route
+// template and status, like AccessLog logs it.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = RouteUnmatched
+		}
+
+		m.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,55 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/middleware"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := prometheus.NewRegistry()
+
+	router := gin.New()
+	router.Use(middleware.Metrics(metrics.New(registry)))
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+
+	for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	// requests observed by route and status
+	observed := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != "user_manager_http_request_duration_seconds" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			assert.Equal(t, http.MethodGet, labels["method"])
+			observed[labels["route"]+" "+labels["status"]] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+
+	assert.Equal(t, map[string]uint64{
+		"/users/:id 200":                   2,
+		middleware.RouteUnmatched + " 404": 1,
+	}, observed)
+}
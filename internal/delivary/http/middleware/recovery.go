@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panic further down the chain into a 500 problem carrying
+// the request id, and logs the panic with its stack under the same id. When
+// the response was already under way it can only be cut short.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			// the server is expected to abort the response without logging
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logger.ErrorContext(c.Request.Context(), "panic recovered",
+				slog.String("request_id", requestid.FromContext(c.Request.Context())),
+				slog.String("panic", fmt.Sprint(recovered)),
+				slog.String("stack", string(debug.Stack())),
+			)
+
+			_ = c.Error(fmt.Errorf("panic: %v", recovered))
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+
+			reject(c, http.StatusInternalServerError, usecase.ErrMsgInternalServerError)
+		}()
+
+		c.Next()
+	}
+}
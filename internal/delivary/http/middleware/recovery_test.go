@@ -0,0 +1,52 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/middleware"
+	httpresponse "github.com/alirezaghasemi/user-manager/internal/pkg/httpResponse"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, nil))
+
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Recovery(logger))
+	router.GET("/panic", func(c *gin.Context) {
+		panic("nil map")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, httpresponse.ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem httpresponse.Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, "internal_error", problem.Code)
+	assert.Equal(t, "req-1", problem.RequestID)
+	assert.Equal(t, "/panic", problem.Instance)
+	assert.NotContains(t, problem.Detail, "nil map", "the panic stays in the log")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "panic recovered", record["msg"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "nil map", record["panic"])
+	assert.Contains(t, record["stack"], "runtime/debug.Stack")
+}
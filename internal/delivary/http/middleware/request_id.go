@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the id of a request, from the client or a proxy in
+// front of us, and back in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID keeps ids from clients short and printable, they end up in
+// logs and the audit log.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID stores the X-Request-ID header in the request context, or a new
+// id when the header is missing or malformed, and echoes it in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = requestid.New()
+		}
+
+		c.Request = c.Request.WithContext(requestid.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+
+		c.Next()
+	}
+}
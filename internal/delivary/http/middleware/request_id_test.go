@@ -0,0 +1,54 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/middleware"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(middleware.RequestID())
+
+	var seen string
+	router.GET("/", func(c *gin.Context) {
+		seen = requestid.FromContext(c)
+	})
+
+	t.Run("FromHeader", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(middleware.RequestIDHeader, "proxy-1234")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "proxy-1234", seen)
+		assert.Equal(t, "proxy-1234", w.Header().Get(middleware.RequestIDHeader))
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Len(t, seen, 32)
+		assert.Equal(t, seen, w.Header().Get(middleware.RequestIDHeader))
+	})
+
+	t.Run("MalformedReplaced", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(middleware.RequestIDHeader, "has spaces\tand tabs")
+
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Len(t, seen, 32)
+	})
+}
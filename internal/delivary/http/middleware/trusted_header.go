@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/gin-gonic/gin"
+)
+
+// Definition Error Message
+var (
+	ErrMsgMissingUserHeader = errors.New("missing user id header")
+	ErrMsgInvalidUserHeader = errors.New("invalid user id header")
+)
+
+// TrustedHeader takes the caller's user id from header, as set by a proxy
+// that already authenticated the request. Only use it behind such a proxy,
+// anybody reaching the server directly can claim to be any user. Requests
+// without a valid id are rejected with 401.
+func TrustedHeader(header string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := strings.TrimSpace(c.GetHeader(header))
+		if value == "" {
+			reject(c, http.StatusUnauthorized, ErrMsgMissingUserHeader)
+			return
+		}
+
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || id == 0 {
+			reject(c, http.StatusUnauthorized, ErrMsgInvalidUserHeader)
+			return
+		}
+
+		caller := entities.Principal{UserID: id}
+		ctx := principal.WithPrincipal(c.Request.Context(), caller)
+		ctx = actor.WithActor(ctx, caller.Actor())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/middleware"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustedHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(middleware.TrustedHeader("X-User-ID"))
+
+	var seen entities.Principal
+	var seenActor string
+	router.GET("/", func(c *gin.Context) {
+		seen, _ = principal.FromContext(c)
+		seenActor = actor.FromContext(c)
+	})
+
+	serve := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Actor", "mallory")
+		if userID != "" {
+			req.Header.Set("X-User-ID", userID)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("ValidID", func(t *testing.T) {
+		w := serve("7")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, entities.Principal{UserID: 7}, seen)
+		assert.Equal(t, "user:7", seenActor)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		w := serve("")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), middleware.ErrMsgMissingUserHeader.Error())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, value := range []string{"abc", "0", "-1"} {
+			w := serve(value)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, value)
+		}
+	})
+}
@@ -1,25 +1,67 @@
 package router
 
 import (
+	"fmt"
+	"log/slog"
 	"net/http"
 
 	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/middleware"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/metrics"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
 	"github.com/gin-gonic/gin"
 )
 
-func NewRouter(userHandler handler.UserHandler) *gin.Engine {
-	router := gin.Default()
+// authenticate guards every route but login, refresh and the links mailed to
+// users, and must store the caller's principal in the request context.
+// Requests are logged to logger and observed by stats, which /metrics serves.
+// The client address is taken from X-Forwarded-For only on requests coming
+// from trustedProxies.
+func NewRouter(userHandler handler.UserHandler, authHandler handler.AuthHandler, roleHandler handler.RoleHandler, mfaHandler handler.MFAHandler, accountHandler handler.AccountHandler, auditHandler handler.AuditHandler, translator *validation.Translator, logger *slog.Logger, stats *metrics.Metrics, trustedProxies []string, authenticate gin.HandlerFunc) (*gin.Engine, error) {
+	router, err := NewEngine(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	// the request id comes first so the access log and a recovered panic carry it
+	router.Use(middleware.RequestID(), middleware.AccessLog(logger), middleware.Metrics(stats), middleware.Recovery(logger))
+	router.Use(middleware.Locale(translator))
 
 	router.GET("", func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, "welcome home")
 	})
 
+	// Prometheus Metrics, open like the home route for the scraper
+	router.GET("/metrics", gin.WrapH(stats.Handler()))
+
 	baseRouter := router.Group("/api/v1")
-	userRouter := baseRouter.Group("/user")
+
+	authRouter := baseRouter.Group("/auth")
+
+	// Login and Refresh, open api routes
+	authRouter.POST("/login", authHandler.Login)
+	authRouter.POST("/refresh", authHandler.Refresh)
+
+	// Verify Email And Reset Password With A Mailed Token, open api routes
+	authRouter.POST("/verify-email", accountHandler.VerifyEmail)
+	authRouter.POST("/password-reset", accountHandler.RequestPasswordReset)
+	authRouter.POST("/password-reset/confirm", accountHandler.ResetPassword)
+
+	// Check A Password For Another Service, needs credentials:verify
+	authRouter.POST("/verify-password", authenticate, authHandler.VerifyPassword)
+
+	// Logout Of This Or Every Session
+	authRouter.POST("/logout", authenticate, authHandler.Logout)
+	authRouter.POST("/logout-all", authenticate, authHandler.LogoutAll)
+
+	userRouter := baseRouter.Group("/user", authenticate)
 
 	// Create User
 	userRouter.POST("", userHandler.Create)
 
+	// Export Users
+	userRouter.GET("/export", userHandler.Export)
+
 	// Get User
 	userRouter.GET("/:id", userHandler.FindByID)
 
@@ -31,5 +73,61 @@ func NewRouter(userHandler handler.UserHandler) *gin.Engine {
 
 	// Delete User
 	userRouter.DELETE("/:id", userHandler.Delete)
-	return router
+
+	// Bulk Create, Update and Delete Users
+	userRouter.POST("/bulk", userHandler.BulkCreate)
+	userRouter.PATCH("/bulk", userHandler.BulkUpdate)
+	userRouter.DELETE("/bulk", userHandler.BulkDelete)
+
+	// Restore Deleted User
+	userRouter.POST("/:id/restore", userHandler.Restore)
+
+	// Mail A Verification Link To User
+	userRouter.POST("/:id/email/verification", accountHandler.RequestEmailVerification)
+
+	// List Open Sessions Of User
+	userRouter.GET("/:id/sessions", authHandler.Sessions)
+
+	// Show And Lift The Login Lockout Of User
+	userRouter.GET("/:id/lock", authHandler.LockStatus)
+	userRouter.DELETE("/:id/lock", authHandler.Unlock)
+
+	// List, Assign And Revoke Roles Of User
+	userRouter.GET("/:id/roles", roleHandler.UserRoles)
+	userRouter.PUT("/:id/roles/:role", roleHandler.Assign)
+	userRouter.DELETE("/:id/roles/:role", roleHandler.Revoke)
+
+	// Enroll, Activate, Verify And Disable TOTP Of User
+	userRouter.GET("/:id/mfa", mfaHandler.Status)
+	userRouter.POST("/:id/mfa", mfaHandler.Enroll)
+	userRouter.POST("/:id/mfa/activate", mfaHandler.Activate)
+	userRouter.POST("/:id/mfa/verify", mfaHandler.Verify)
+	userRouter.DELETE("/:id/mfa", mfaHandler.Disable)
+
+	// List Roles With Their Permissions
+	baseRouter.GET("/roles", authenticate, roleHandler.List)
+
+	// List The Audit Log Of User Changes
+	baseRouter.GET("/audit", authenticate, auditHandler.List)
+	return router, nil
+}
+
+// NewEngine returns an engine without routes that believes the client
+// address forwarded in X-Forwarded-For or X-Real-IP only from trustedProxies,
+// addresses or CIDRs. With none, the client is the peer of the connection, so
+// a caller can't pick the address its lockouts and sessions are recorded with.
+func NewEngine(trustedProxies []string) (*gin.Engine, error) {
+	engine := gin.New()
+	// let usecases called with the gin context see values stored on the request context
+	engine.ContextWithFallback = true
+
+	if len(trustedProxies) == 0 {
+		trustedProxies = nil
+	}
+	err := engine.SetTrustedProxies(trustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SERVER_TRUSTED_PROXIES: %w", err)
+	}
+
+	return engine, nil
 }
@@ -0,0 +1,67 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/handler"
+	"github.com/alirezaghasemi/user-manager/internal/delivary/http/router"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// lockoutRecorder is an AuthUsecase that keeps the lockout key of the client
+// IP it was asked to verify a password for.
+type lockoutRecorder struct {
+	usecase.AuthUsecase
+	key string
+}
+
+func (r *lockoutRecorder) VerifyPassword(ctx context.Context, email string, password string, clientIP string) (entities.User, error) {
+	r.key = entities.LockoutKeyIP(clientIP)
+	return entities.User{ID: 1}, nil
+}
+
+func TestNewEngine(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// lockoutKey verifies a password from RemoteAddr 192.0.2.1 with a forged
+	// X-Forwarded-For and returns the key its failures would count against.
+	lockoutKey := func(t *testing.T, trustedProxies []string) string {
+		engine, err := router.NewEngine(trustedProxies)
+		assert.NoError(t, err)
+
+		recorder := &lockoutRecorder{}
+		engine.POST("/verify-password", handler.NewAuthHandler(recorder, validation.New()).VerifyPassword)
+
+		req := httptest.NewRequest(http.MethodPost, "/verify-password", strings.NewReader(`{"email":"ali@gmail.com","password":"secret-password"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		req.Header.Set("X-Real-IP", "203.0.113.9")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		return recorder.key
+	}
+
+	t.Run("SpoofedForwardedFor", func(t *testing.T) {
+		assert.Equal(t, entities.LockoutKeyIP("192.0.2.1"), lockoutKey(t, nil), "a client can't pick its lockout key")
+		assert.Equal(t, entities.LockoutKeyIP("192.0.2.1"), lockoutKey(t, []string{}))
+	})
+
+	t.Run("TrustedProxy", func(t *testing.T) {
+		assert.Equal(t, entities.LockoutKeyIP("203.0.113.9"), lockoutKey(t, []string{"192.0.2.0/24"}))
+	})
+
+	t.Run("InvalidProxy", func(t *testing.T) {
+		_, err := router.NewEngine([]string{"not-an-address"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,151 @@
+package entities
+
+import "time"
+
+// Definition Pagination Limits
+const (
+	DefaultAuditListLimit = 50
+	MaxAuditListLimit     = 200
+)
+
+// AuditAction names what happened to the user of an audit event.
+type AuditAction string
+
+const (
+	AuditUserCreated     AuditAction = "user.created"
+	AuditUserUpdated     AuditAction = "user.updated"
+	AuditUserDeleted     AuditAction = "user.deleted"
+	AuditUserRestored    AuditAction = "user.restored"
+	AuditUserMFAEnrolled AuditAction = "user.mfa_enrolled"
+	AuditUserMFAEnabled  AuditAction = "user.mfa_enabled"
+	AuditUserMFADisabled AuditAction = "user.mfa_disabled"
+)
+
+// IsValid reports whether the action is one the audit log records.
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditUserCreated, AuditUserUpdated, AuditUserDeleted, AuditUserRestored, AuditUserMFAEnrolled, AuditUserMFAEnabled, AuditUserMFADisabled:
+		return true
+	default:
+		return false
+	}
+}
+
+// redacted stands in for the value of a secret field in a change.
+const redacted = "[redacted]"
+
+// AuditChange is one field of a user before and after a change, nil meaning
+// the field had no value, e.g. before the user was created.
+type AuditChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// AuditEvent records who changed which user how. Actor and RequestID come from
+// the context of the change.
+type AuditEvent struct {
+	ID        uint64
+	Actor     string
+	Action    AuditAction
+	UserID    uint64
+	RequestID string
+	Changes   []AuditChange `gorm:"serializer:json"`
+	CreatedAt time.Time
+}
+
+// AuditQuery narrows an audit listing, zero values being ignored. Events are
+// listed newest first; BeforeID positions a page behind the previous one.
+type AuditQuery struct {
+	UserID   *uint64
+	Actor    string
+	Action   AuditAction
+	From     *time.Time
+	To       *time.Time
+	BeforeID uint64
+	Limit    int
+}
+
+// Normalize clamps Limit to the allowed range.
+func (q AuditQuery) Normalize() AuditQuery {
+	if q.Limit <= 0 {
+		q.Limit = DefaultAuditListLimit
+	}
+	if q.Limit > MaxAuditListLimit {
+		q.Limit = MaxAuditListLimit
+	}
+
+	return q
+}
+
+// AuditPage is one page of an audit listing. NextBeforeID is the BeforeID of
+// the next page, 0 on the last one.
+type AuditPage struct {
+	Events       []AuditEvent
+	NextBeforeID uint64
+}
+
+// UserChanges returns the audited fields that differ between before and
+// after, a nil user standing for one that doesn't exist. Passwords are
+// reported as changed without their hashes.
+func UserChanges(before *User, after *User) []AuditChange {
+	fields := []struct {
+		name   string
+		secret bool
+		value  func(user User) any
+	}{
+		{name: "name", value: func(user User) any { return user.Name }},
+		{name: "family", value: func(user User) any { return user.Family }},
+		{name: "email", value: func(user User) any { return user.Email }},
+		{name: "age", value: func(user User) any { return user.Age }},
+		{name: "email_verified_at", value: func(user User) any {
+			if user.EmailVerifiedAt == nil {
+				return nil
+			}
+			return user.EmailVerifiedAt.UTC()
+		}},
+		{name: "password", secret: true, value: func(user User) any {
+			if user.PasswordHash == "" {
+				return nil
+			}
+			return user.PasswordHash
+		}},
+	}
+
+	changes := []AuditChange{}
+	for _, field := range fields {
+		var was, is any
+		if before != nil {
+			was = field.value(*before)
+		}
+		if after != nil {
+			is = field.value(*after)
+		}
+
+		if was == is {
+			continue
+		}
+
+		if field.secret {
+			was, is = redact(was), redact(is)
+		}
+		changes = append(changes, AuditChange{Field: field.name, Before: was, After: is})
+	}
+
+	return changes
+}
+
+// redact hides a secret value, keeping whether there was one.
+func redact(value any) any {
+	if value == nil {
+		return nil
+	}
+
+	return redacted
+}
+
+// DeletedChange is the change of a user being soft-deleted, or restored when
+// deleted is false.
+func DeletedChange(deleted bool) []AuditChange {
+	return []AuditChange{{Field: "deleted", Before: !deleted, After: deleted}}
+}
@@ -0,0 +1,47 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+// Principal is the authenticated caller of a request. SessionID names the
+// login the access token was issued for. A System principal, e.g. the CLI,
+// is trusted with every permission. A service account calling with an API
+// key has no user, only the key's Scopes.
+type Principal struct {
+	UserID         uint64
+	Email          string
+	SessionID      uint64
+	System         bool
+	APIKeyID       uint64
+	ServiceAccount string
+	Scopes         Scopes
+}
+
+// SystemPrincipal is the caller of operators' commands and background jobs.
+var SystemPrincipal = Principal{System: true}
+
+// Actor is how the principal is recorded in created_by and updated_by.
+func (p Principal) Actor() string {
+	if p.System {
+		return "system"
+	}
+
+	if p.APIKeyID != 0 {
+		return "service:" + p.ServiceAccount
+	}
+
+	return fmt.Sprintf("user:%d", p.UserID)
+}
+
+// AuthToken is a signed access token handed out on login and refresh, with
+// the refresh token that gets the next one.
+type AuthToken struct {
+	AccessToken      string
+	TokenType        string
+	ExpiresIn        time.Duration
+	ExpiresAt        time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Lockout counts the failed credential checks of one subject, a user or a
+// client IP, named by Key. Failures older than the attempt window start over.
+// Reaching the threshold locks the subject until LockedUntil; Lockouts
+// counts how often that happened, each lock lasting twice as long as the
+// one before.
+type Lockout struct {
+	Key           string `gorm:"primaryKey"`
+	Failures      int
+	Lockouts      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// TableName overrides the table name used by GORM.
+func (Lockout) TableName() string {
+	return "credential_lockouts"
+}
+
+// Locked reports whether the subject is locked at now.
+func (l Lockout) Locked(now time.Time) bool {
+	return l.LockedUntil != nil && now.Before(*l.LockedUntil)
+}
+
+// LockoutKeyUser, LockoutKeyMFA and LockoutKeyIP name the lockout of the
+// password of a user, of their mfa codes and of a client IP.
+func LockoutKeyUser(userID uint64) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+func LockoutKeyMFA(userID uint64) string {
+	return fmt.Sprintf("mfa:%d", userID)
+}
+
+func LockoutKeyIP(ip string) string {
+	return "ip:" + ip
+}
+
+// LockoutKeyPasswordReset names the limit on reset mails to email. The
+// address is hashed, it may belong to no user and needn't be kept.
+func LockoutKeyPasswordReset(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	return "reset:" + hex.EncodeToString(sum[:])
+}
+
+// LockStatus is the lockout of a user as shown on the user resource.
+type LockStatus struct {
+	Locked         bool
+	LockedUntil    *time.Time
+	FailedAttempts int
+	Lockouts       int
+}
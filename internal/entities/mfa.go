@@ -0,0 +1,61 @@
+package entities
+
+import "time"
+
+// MFA is the TOTP second factor of a user. Secret is sealed, never stored in
+// clear. Enrollment leaves EnabledAt unset until the user proves with a
+// first code that their authenticator works. LastUsedStep is the time step
+// of the last accepted code, so every code is accepted once.
+type MFA struct {
+	UserID       uint64 `gorm:"primaryKey"`
+	Secret       string
+	EnabledAt    *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// TableName overrides the table name used by GORM.
+func (MFA) TableName() string {
+	return "user_mfa"
+}
+
+// Enabled reports whether codes are checked for the user.
+func (m MFA) Enabled() bool {
+	return m.EnabledAt != nil
+}
+
+// RecoveryCode replaces a TOTP code once, for users who lost their
+// authenticator. Like refresh tokens it is stored as a SHA-256.
+type RecoveryCode struct {
+	ID        uint64
+	UserID    uint64
+	CodeHash  string
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+// TableName overrides the table name used by GORM.
+func (RecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// MFAEnrollment is what a user needs to add the secret to an authenticator.
+type MFAEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// MFAStatus describes the second factor of a user.
+type MFAStatus struct {
+	Enabled           bool
+	EnabledAt         *time.Time
+	RecoveryCodesLeft int64
+}
+
+// Definition MFA Methods
+// How a code passed verification.
+const (
+	MFAMethodTOTP         = "totp"
+	MFAMethodRecoveryCode = "recovery_code"
+)
@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+// TokenPurpose tells what a one-time token may be used for, a token of one
+// purpose is never accepted for another.
+type TokenPurpose string
+
+const (
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+)
+
+// OneTimeToken is a single-use token mailed to a user, stored as the SHA-256
+// of the opaque value in the link. Email is the address it was mailed to.
+// UsedAt is set once it was consumed.
+type OneTimeToken struct {
+	ID        uint64
+	UserID    uint64
+	Email     string
+	Purpose   TokenPurpose
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// Usable reports whether the token can still be consumed at now.
+func (t OneTimeToken) Usable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// Permission names an action on a resource, e.g. "users:delete". The same
+// permission with a ":self" suffix only covers the caller's own record.
+type Permission string
+
+// Definition Permissions
+const (
+	PermUsersRead         Permission = "users:read"
+	PermUsersCreate       Permission = "users:create"
+	PermUsersUpdate       Permission = "users:update"
+	PermUsersDelete       Permission = "users:delete"
+	PermSessionsRead      Permission = "sessions:read"
+	PermRolesManage       Permission = "roles:manage"
+	PermAPIKeysManage     Permission = "apikeys:manage"
+	PermMFAVerify         Permission = "mfa:verify"
+	PermMFAManage         Permission = "mfa:manage"
+	PermUsersUnlock       Permission = "users:unlock"
+	PermCredentialsVerify Permission = "credentials:verify"
+	PermAuditRead         Permission = "audit:read"
+)
+
+// selfSuffix marks a permission restricted to the caller's own record.
+const selfSuffix = ":self"
+
+// Self returns p restricted to the caller's own record.
+func (p Permission) Self() Permission {
+	if p.IsSelf() {
+		return p
+	}
+
+	return p + selfSuffix
+}
+
+// IsSelf reports whether p only covers the caller's own record.
+func (p Permission) IsSelf() bool {
+	return strings.HasSuffix(string(p), selfSuffix)
+}
+
+// Definition Roles
+// Every user holds RoleUser without it being assigned.
+const (
+	RoleAdmin   = "admin"
+	RoleSupport = "support"
+	RoleUser    = "user"
+)
+
+// Role is a named set of permissions.
+type Role struct {
+	ID          uint64
+	Name        string
+	Description string
+	Permissions []Permission `gorm:"-"`
+}
+
+// UserRole records that a user was assigned a role, and by whom.
+type UserRole struct {
+	UserID    uint64
+	RoleID    uint64
+	CreatedAt time.Time
+	CreatedBy string
+}
@@ -0,0 +1,48 @@
+package entities
+
+import "time"
+
+// Session is one login of a user, kept alive by rotating refresh tokens. All
+// refresh tokens of a session form one family that is revoked together.
+type Session struct {
+	ID           uint64
+	UserID       uint64
+	UserAgent    string
+	IPAddress    string
+	CreatedAt    time.Time
+	LastUsedAt   time.Time
+	ExpiresAt    time.Time
+	RevokedAt    *time.Time
+	RevokeReason string
+}
+
+// Active reports whether the session can still be refreshed at now.
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// Definition Session Revoke Reasons
+const (
+	RevokeReasonLogout        = "logout"
+	RevokeReasonLogoutAll     = "logout_all"
+	RevokeReasonReuse         = "reuse"
+	RevokeReasonUserGone      = "user_gone"
+	RevokeReasonPasswordReset = "password_reset"
+)
+
+// RefreshToken is one token of a session, stored as the SHA-256 of the
+// opaque value handed to the client. RotatedAt is set once it was exchanged,
+// presenting it again means it leaked.
+type RefreshToken struct {
+	ID        uint64
+	SessionID uint64
+	TokenHash string
+	CreatedAt time.Time
+	RotatedAt *time.Time
+}
+
+// SessionClient describes who logged in, shown in the session listing.
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
@@ -1,9 +1,70 @@
 package entities
 
+import (
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type User struct {
-	ID     uint64
-	Name   string
-	Family string
-	Email  string
-	Age    int
+	ID        uint64
+	Name      string
+	Family    string
+	Email     string
+	Age       int
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy string
+	UpdatedBy string
+	DeletedAt gorm.DeletedAt
+
+	// EmailVerifiedAt is when the user proved they own Email, nil until then
+	// and again after the email changes.
+	EmailVerifiedAt *time.Time
+
+	// PasswordHash is the bcrypt hash of the login password, empty for users
+	// that can't log in.
+	PasswordHash string
+	// Password is a plain text password to set when the user is created. It
+	// is never stored, the usecase replaces it with PasswordHash.
+	Password string `gorm:"-"`
+}
+
+// UserPatch holds the fields a partial update changes, nil meaning unchanged.
+// When Versions is not nil the patch applies only if it holds the stored
+// version, so an empty one never applies.
+type UserPatch struct {
+	Name     *string
+	Family   *string
+	Email    *string
+	Age      *int
+	Versions []int
+}
+
+// Accepts reports whether the patch may apply to the stored version.
+func (p UserPatch) Accepts(version int) bool {
+	return p.Versions == nil || slices.Contains(p.Versions, version)
+}
+
+// Apply returns user with the patched fields replaced.
+func (p UserPatch) Apply(user User) User {
+	if p.Name != nil {
+		user.Name = *p.Name
+	}
+	if p.Family != nil {
+		user.Family = *p.Family
+	}
+	if p.Email != nil {
+		if *p.Email != user.Email {
+			user.EmailVerifiedAt = nil
+		}
+		user.Email = *p.Email
+	}
+	if p.Age != nil {
+		user.Age = *p.Age
+	}
+
+	return user
 }
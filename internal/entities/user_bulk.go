@@ -0,0 +1,27 @@
+package entities
+
+// Definition Bulk Limits
+const MaxUserBulkSize = 1000
+
+// BulkMode decides what a bulk operation does when some items fail.
+type BulkMode string
+
+const (
+	// BulkAtomic applies every item or none of them.
+	BulkAtomic BulkMode = "atomic"
+	// BulkBestEffort applies the items that can be applied and reports the rest.
+	BulkBestEffort BulkMode = "best_effort"
+)
+
+// UserBatchPatch is one item of a bulk update: the patch for user ID.
+type UserBatchPatch struct {
+	ID    uint64
+	Patch UserPatch
+}
+
+// BulkResult is the outcome of one bulk item, at the same position as the
+// item in the request. Err is nil when the item was applied.
+type BulkResult struct {
+	User User
+	Err  error
+}
@@ -0,0 +1,44 @@
+package entities
+
+// ConflictPolicy decides what an import does with a user whose email
+// already belongs to an active user.
+type ConflictPolicy string
+
+const (
+	ConflictSkip   ConflictPolicy = "skip"
+	ConflictUpdate ConflictPolicy = "update"
+	ConflictFail   ConflictPolicy = "fail"
+)
+
+// IsValid reports whether the policy is one of the known policies.
+func (p ConflictPolicy) IsValid() bool {
+	switch p {
+	case ConflictSkip, ConflictUpdate, ConflictFail:
+		return true
+	default:
+		return false
+	}
+}
+
+type ImportOptions struct {
+	OnConflict ConflictPolicy
+	// DryRun runs the whole import and rolls it back, so the results show
+	// what would happen without changing anything.
+	DryRun bool
+}
+
+type ImportOutcome string
+
+const (
+	ImportCreated ImportOutcome = "created"
+	ImportUpdated ImportOutcome = "updated"
+	ImportSkipped ImportOutcome = "skipped"
+)
+
+// ImportResult is the outcome of one imported user, at the same position as
+// the user in the import. Outcome is empty when Err is set.
+type ImportResult struct {
+	User    User
+	Outcome ImportOutcome
+	Err     error
+}
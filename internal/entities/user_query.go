@@ -0,0 +1,135 @@
+package entities
+
+import "strconv"
+
+// Definition Pagination Limits
+const (
+	DefaultUserListLimit = 20
+	MaxUserListLimit     = 100
+)
+
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+type UserSortField string
+
+const (
+	UserSortByID     UserSortField = "id"
+	UserSortByName   UserSortField = "name"
+	UserSortByFamily UserSortField = "family"
+	UserSortByEmail  UserSortField = "email"
+	UserSortByAge    UserSortField = "age"
+)
+
+// IsValid reports whether the field is one of the sortable user fields.
+func (f UserSortField) IsValid() bool {
+	switch f {
+	case UserSortByID, UserSortByName, UserSortByFamily, UserSortByEmail, UserSortByAge:
+		return true
+	default:
+		return false
+	}
+}
+
+type UserSort struct {
+	Field     UserSortField
+	Direction SortDirection
+}
+
+// UserFilter narrows a user listing. Empty strings and nil ages are ignored,
+// text fields match case-insensitively on a substring. Soft-deleted users are
+// only listed with IncludeDeleted.
+type UserFilter struct {
+	Name           string
+	Family         string
+	Email          string
+	MinAge         *int
+	MaxAge         *int
+	IncludeDeleted bool
+}
+
+// UserCursor is the keyset position after the last row of a page: the value
+// of the active sort field and the id used as tie-breaker.
+type UserCursor struct {
+	ID        uint64        `json:"id"`
+	Field     UserSortField `json:"f"`
+	Direction SortDirection `json:"d"`
+	Value     string        `json:"v"`
+}
+
+// UserListQuery is the typed listing spec passed from the handler through the
+// usecase into the repository.
+// With Keyset set the listing pages by cursor instead of offset: only the
+// first sort field is used, Offset is ignored, no total is counted and After
+// positions the page behind the previous one.
+type UserListQuery struct {
+	Filter UserFilter
+	Sort   []UserSort
+	Limit  int
+	Offset int
+	Keyset bool
+	After  *UserCursor
+}
+
+// KeysetSort returns the single sort used in keyset mode, id ascending by default.
+func (q UserListQuery) KeysetSort() UserSort {
+	if len(q.Sort) == 0 {
+		return UserSort{Field: UserSortByID, Direction: SortAsc}
+	}
+
+	return q.Sort[0]
+}
+
+// CursorAfter builds the cursor positioned right after user for the given sort.
+func CursorAfter(user User, sort UserSort) *UserCursor {
+	var value string
+	switch sort.Field {
+	case UserSortByName:
+		value = user.Name
+	case UserSortByFamily:
+		value = user.Family
+	case UserSortByEmail:
+		value = user.Email
+	case UserSortByAge:
+		value = strconv.Itoa(user.Age)
+	case UserSortByID:
+		value = strconv.FormatUint(user.ID, 10)
+	}
+
+	return &UserCursor{
+		ID:        user.ID,
+		Field:     sort.Field,
+		Direction: sort.Direction,
+		Value:     value,
+	}
+}
+
+// Normalize applies the default and maximum page size and drops negative offsets.
+func (q UserListQuery) Normalize() UserListQuery {
+	if q.Limit <= 0 {
+		q.Limit = DefaultUserListLimit
+	}
+	if q.Limit > MaxUserListLimit {
+		q.Limit = MaxUserListLimit
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	return q
+}
+
+type UserPage struct {
+	Users  []User
+	Total  int64
+	Limit  int
+	Offset int
+	Next   *UserCursor
+}
+
+// Definition Stream Batch Size
+const DefaultUserBatchSize = 500
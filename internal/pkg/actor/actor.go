@@ -0,0 +1,24 @@
+package actor
+
+import "context"
+
+// System is reported when no actor was put in the context, e.g. background jobs.
+const System = "system"
+
+type contextKey struct{}
+
+// WithActor returns a copy of ctx carrying the identifier of whoever is
+// performing the operation.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, contextKey{}, actor)
+}
+
+// FromContext returns the actor stored in ctx, or System when there is none.
+func FromContext(ctx context.Context) string {
+	actor, ok := ctx.Value(contextKey{}).(string)
+	if !ok || actor == "" {
+		return System
+	}
+
+	return actor
+}
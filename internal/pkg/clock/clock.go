@@ -0,0 +1,27 @@
+package clock
+
+import "time"
+
+// Clock tells the current time. Repositories take one instead of calling
+// time.Now so tests can pin timestamps.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+// Definition Constructor
+func New() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// Fixed is a Clock that always returns the same instant.
+type Fixed time.Time
+
+func (f Fixed) Now() time.Time {
+	return time.Time(f)
+}
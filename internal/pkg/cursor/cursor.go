@@ -0,0 +1,80 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Definition Error Message
+var (
+	ErrMsgInvalidCursor = errors.New("invalid cursor")
+)
+
+// Codec turns a value into an opaque token of the form payload.signature,
+// both base64url encoded, and back. The HMAC-SHA256 signature stops clients
+// from forging or editing cursors.
+type Codec struct {
+	secret []byte
+}
+
+// Definition Constructor
+// An empty secret falls back to a random one, which is fine for a single
+// process but invalidates cursors on restart.
+func NewCodec(secret string) *Codec {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+
+	return &Codec{secret: key}
+}
+
+func (c *Codec) Encode(value interface{}) (string, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+func (c *Codec) Decode(token string, value interface{}) error {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrMsgInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInvalidCursor, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInvalidCursor, err)
+	}
+
+	if !hmac.Equal(signature, c.sign(payload)) {
+		return ErrMsgInvalidCursor
+	}
+
+	err = json.Unmarshal(payload, value)
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInvalidCursor, err)
+	}
+
+	return nil
+}
+
+func (c *Codec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,63 @@
+package cursor_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/cursor"
+	"github.com/stretchr/testify/assert"
+)
+
+type position struct {
+	ID    uint64 `json:"id"`
+	Value string `json:"value"`
+}
+
+func TestCodec(t *testing.T) {
+	codec := cursor.NewCodec("test-secret")
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		token, err := codec.Encode(position{ID: 42, Value: "Ali"})
+		assert.NoError(t, err)
+
+		var decoded position
+		err = codec.Decode(token, &decoded)
+
+		assert.NoError(t, err)
+		assert.Equal(t, position{ID: 42, Value: "Ali"}, decoded)
+	})
+
+	t.Run("TamperedPayload", func(t *testing.T) {
+		token, err := codec.Encode(position{ID: 42})
+		assert.NoError(t, err)
+
+		forged, err := codec.Encode(position{ID: 1})
+		assert.NoError(t, err)
+
+		_, signature, _ := strings.Cut(token, ".")
+		payload, _, _ := strings.Cut(forged, ".")
+
+		var decoded position
+		err = codec.Decode(payload+"."+signature, &decoded)
+
+		assert.True(t, errors.Is(err, cursor.ErrMsgInvalidCursor))
+	})
+
+	t.Run("DifferentSecret", func(t *testing.T) {
+		token, err := cursor.NewCodec("other-secret").Encode(position{ID: 42})
+		assert.NoError(t, err)
+
+		var decoded position
+		err = codec.Decode(token, &decoded)
+
+		assert.True(t, errors.Is(err, cursor.ErrMsgInvalidCursor))
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		var decoded position
+		err := codec.Decode("not-a-cursor", &decoded)
+
+		assert.True(t, errors.Is(err, cursor.ErrMsgInvalidCursor))
+	})
+}
@@ -9,11 +9,43 @@ func Success(message string, data interface{}) APIResponse {
 	}
 }
 
-// error response with optional trace
-func Error(message string, err interface{}) APIResponse {
+// success response with pagination metadata
+func Paginated(message string, data interface{}, pagination Pagination) APIResponse {
 	return APIResponse{
-		Success: false,
+		Success:    true,
+		Message:    message,
+		Data:       data,
+		Pagination: &pagination,
+	}
+}
+
+// pagination metadata for an offset/limit page
+func NewPagination(total int64, limit int, offset int) Pagination {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = int((total + int64(limit) - 1) / int64(limit))
+	}
+
+	page := 1
+	if limit > 0 {
+		page = offset/limit + 1
+	}
+
+	return Pagination{
+		Page:       page,
+		PerPage:    limit,
+		Offset:     offset,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+// success response with keyset pagination metadata
+func CursorPaginated(message string, data interface{}, cursor Cursor) APIResponse {
+	return APIResponse{
+		Success: true,
 		Message: message,
-		Errors:  err,
+		Data:    data,
+		Cursor:  &cursor,
 	}
 }
@@ -0,0 +1,38 @@
+package httpresponse
+
+import (
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of a Problem body.
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBase prefixes the code of a problem to form its type URI.
+const ProblemTypeBase = "urn:user-manager:problem:"
+
+// problem details response identified by a stable code
+func NewProblem(status int, code string, title string, detail string) Problem {
+	return Problem{
+		Type:   ProblemTypeBase + code,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// AbortWithProblem writes problem as application/problem+json, filling in
+// the path and the id of the request, and stops the handler chain.
+func AbortWithProblem(c *gin.Context, problem Problem) {
+	if problem.Instance == "" {
+		problem.Instance = c.Request.URL.Path
+	}
+	if problem.RequestID == "" {
+		problem.RequestID = requestid.FromContext(c.Request.Context())
+	}
+
+	// gin keeps a content type that is already set
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
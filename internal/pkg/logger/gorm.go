@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Definition Struct (Class)
+// gormLogger logs the queries of GORM. A failed query is an error, unless it
+// failed with an outcome the repositories turn into a domain error, such as
+// no row or a duplicate key; every query is logged at debug level. Queries
+// are logged without their values, which hold password hashes and tokens.
+type gormLogger struct {
+	logger *slog.Logger
+}
+
+// Definition Constructor
+func NewGormLogger(logger *slog.Logger) gormlogger.Interface {
+	return &gormLogger{logger: logger.With(slog.String("component", "database"))}
+}
+
+// Definition Implement Methods (LogMode, Info, Warn, Error, Trace, ParamsFilter)
+// LogMode is ignored, the level of the slog logger decides.
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+// Definition Implement Methods (LogMode, Info, Warn, Error, Trace, ParamsFilter)
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+// Definition Implement Methods (LogMode, Info, Warn, Error, Trace, ParamsFilter)
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+// Definition Implement Methods (LogMode, Info, Warn, Error, Trace, ParamsFilter)
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+// Definition Implement Methods (LogMode, Info, Warn, Error, Trace, ParamsFilter)
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	level := slog.LevelDebug
+	if err != nil && !expected(err) {
+		level = slog.LevelError
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("elapsed_ms", float64(time.Since(begin).Microseconds())/1000),
+	}
+	message := "query"
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		message = "query failed"
+	}
+
+	l.logger.LogAttrs(ctx, level, message, attrs...)
+}
+
+// Definition Implement Methods (LogMode, Info, Warn, Error, Trace, ParamsFilter)
+// ParamsFilter drops the values of a query from what Trace logs.
+func (l *gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// expected reports whether err is an outcome of a query the repositories
+// report to their callers rather than a failure.
+func expected(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) ||
+		errors.Is(err, gorm.ErrDuplicatedKey) ||
+		errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,80 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/config"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/logger"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormLogger(t *testing.T) {
+	setup := func(t *testing.T, level string) (*bytes.Buffer, func(err error)) {
+		var buf bytes.Buffer
+		log, err := logger.New(&buf, config.Log{Level: level, Format: config.LogFormatJSON})
+		require.NoError(t, err)
+
+		gormLogger := logger.NewGormLogger(log)
+		ctx := requestid.WithRequestID(context.Background(), "req-1")
+		trace := func(err error) {
+			gormLogger.Trace(ctx, time.Now(), func() (string, int64) {
+				return `SELECT * FROM "users" WHERE id = $1`, 0
+			}, err)
+		}
+
+		return &buf, trace
+	}
+
+	t.Run("Failure", func(t *testing.T) {
+		buf, trace := setup(t, "info")
+
+		trace(errors.New("connection refused"))
+
+		record := decode(t, buf)
+		assert.Equal(t, "ERROR", record["level"])
+		assert.Equal(t, "query failed", record["msg"])
+		assert.Equal(t, "database", record["component"])
+		assert.Equal(t, "req-1", record["request_id"])
+		assert.Equal(t, "connection refused", record["error"])
+		assert.Equal(t, `SELECT * FROM "users" WHERE id = $1`, record["sql"])
+	})
+
+	t.Run("ExpectedOutcome", func(t *testing.T) {
+		buf, trace := setup(t, "info")
+
+		trace(gorm.ErrRecordNotFound)
+		trace(gorm.ErrDuplicatedKey)
+
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("QueriesAtDebug", func(t *testing.T) {
+		buf, trace := setup(t, "debug")
+
+		trace(nil)
+
+		record := decode(t, buf)
+		assert.Equal(t, "DEBUG", record["level"])
+		assert.Equal(t, "query", record["msg"])
+	})
+
+	t.Run("ParamsFiltered", func(t *testing.T) {
+		var buf bytes.Buffer
+		log, err := logger.New(&buf, config.Log{Level: "info", Format: config.LogFormatJSON})
+		require.NoError(t, err)
+
+		filter, ok := logger.NewGormLogger(log).(gorm.ParamsFilter)
+		require.True(t, ok)
+
+		sql, params := filter.ParamsFilter(context.Background(), "UPDATE users SET password_hash = ?", "$2a$10$hash")
+		assert.Equal(t, "UPDATE users SET password_hash = ?", sql)
+		assert.Empty(t, params)
+	})
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/alirezaghasemi/user-manager/internal/config"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+)
+
+// Definition Outputs
+// Any other output is the path of a file.
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+)
+
+// New returns a logger writing records of cfg.Level and above to w, in the
+// format of cfg.Format. Secrets are redacted from every record and the
+// request, actor and principal of the context are added to it.
+func New(w io.Writer, cfg config.Log) (*slog.Logger, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(cfg.Level))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL %q, use debug, info, warn or error", cfg.Level)
+	}
+
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: redact}
+
+	var handler slog.Handler
+	switch cfg.Format {
+	case config.LogFormatJSON, "":
+		handler = slog.NewJSONHandler(w, options)
+	case config.LogFormatText:
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return nil, fmt.Errorf("unknown LOG_FORMAT %q, use %s or %s", cfg.Format, config.LogFormatJSON, config.LogFormatText)
+	}
+
+	return slog.New(contextHandler{Handler: handler}), nil
+}
+
+// Open returns the writer output names. A file is created when missing and
+// appended to otherwise; it stays open for the life of the process.
+func Open(output string) (io.Writer, error) {
+	switch output {
+	case OutputStdout, "":
+		return os.Stdout, nil
+	case OutputStderr:
+		return os.Stderr, nil
+	default:
+		file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open log output: %w", err)
+		}
+
+		return file, nil
+	}
+}
+
+// contextHandler adds who a record was logged for, as far as the context of
+// the record knows, unless the record already names it.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	present := make(map[string]bool, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		present[a.Key] = true
+		return true
+	})
+	add := func(a slog.Attr) {
+		if !present[a.Key] {
+			r.AddAttrs(a)
+		}
+	}
+
+	if id := requestid.FromContext(ctx); id != "" {
+		add(slog.String("request_id", id))
+	}
+	if name := actor.FromContext(ctx); name != actor.System {
+		add(slog.String("actor", name))
+	}
+	if caller, ok := principal.FromContext(ctx); ok && caller.UserID != 0 {
+		add(slog.Uint64("principal_id", caller.UserID))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
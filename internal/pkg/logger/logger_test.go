@@ -0,0 +1,131 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/config"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/logger"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
+
+func TestNew(t *testing.T) {
+	t.Run("Level", func(t *testing.T) {
+		var buf bytes.Buffer
+		log, err := logger.New(&buf, config.Log{Level: "warn", Format: config.LogFormatJSON})
+		require.NoError(t, err)
+
+		log.Info("hidden")
+		assert.Empty(t, buf.String())
+
+		log.Warn("shown")
+		assert.Equal(t, "shown", decode(t, &buf)["msg"])
+	})
+
+	t.Run("TextFormat", func(t *testing.T) {
+		var buf bytes.Buffer
+		log, err := logger.New(&buf, config.Log{Level: "info", Format: config.LogFormatText})
+		require.NoError(t, err)
+
+		log.Info("hello", slog.Int("n", 1))
+		assert.True(t, strings.HasPrefix(buf.String(), "time="))
+		assert.Contains(t, buf.String(), "msg=hello n=1")
+	})
+
+	t.Run("InvalidLevel", func(t *testing.T) {
+		_, err := logger.New(&bytes.Buffer{}, config.Log{Level: "loud", Format: config.LogFormatJSON})
+		assert.ErrorContains(t, err, "LOG_LEVEL")
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		_, err := logger.New(&bytes.Buffer{}, config.Log{Level: "info", Format: "xml"})
+		assert.ErrorContains(t, err, "LOG_FORMAT")
+	})
+}
+
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := logger.New(&buf, config.Log{Level: "info", Format: config.LogFormatJSON})
+	require.NoError(t, err)
+
+	log.Info("connect",
+		slog.String("password", "hunter2"),
+		slog.String("refresh_token", "abc"),
+		slog.String("dsn", "host=db password=hunter2"),
+		slog.String("detail", "host=db user=postgres password=hunter2 dbname=users"),
+		slog.String("url", "postgresql://postgres:hunter2@db:5432/users"),
+		slog.Any("error", errors.New("dial postgresql://postgres:hunter2@db:5432/users: refused")),
+		slog.String("email", "ali@gmail.com"),
+	)
+
+	record := decode(t, &buf)
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.Equal(t, logger.Redacted, record["password"])
+	assert.Equal(t, logger.Redacted, record["refresh_token"])
+	assert.Equal(t, logger.Redacted, record["dsn"])
+	assert.Equal(t, "host=db user=postgres password=[redacted] dbname=users", record["detail"])
+	assert.Equal(t, "postgresql://postgres:[redacted]@db:5432/users", record["url"])
+	assert.Equal(t, "dial postgresql://postgres:[redacted]@db:5432/users: refused", record["error"])
+	assert.Equal(t, "ali@gmail.com", record["email"])
+}
+
+func TestContextAttributes(t *testing.T) {
+	t.Run("FromContext", func(t *testing.T) {
+		var buf bytes.Buffer
+		log, err := logger.New(&buf, config.Log{Level: "info", Format: config.LogFormatJSON})
+		require.NoError(t, err)
+
+		ctx := requestid.WithRequestID(context.Background(), "req-1")
+		ctx = actor.WithActor(ctx, "user:7")
+		ctx = principal.WithPrincipal(ctx, entities.Principal{UserID: 7})
+
+		log.InfoContext(ctx, "hello")
+
+		record := decode(t, &buf)
+		assert.Equal(t, "req-1", record["request_id"])
+		assert.Equal(t, "user:7", record["actor"])
+		assert.Equal(t, float64(7), record["principal_id"])
+	})
+
+	t.Run("RecordWins", func(t *testing.T) {
+		var buf bytes.Buffer
+		log, err := logger.New(&buf, config.Log{Level: "info", Format: config.LogFormatJSON})
+		require.NoError(t, err)
+
+		log.InfoContext(requestid.WithRequestID(context.Background(), "req-1"), "hello", slog.String("request_id", "req-2"))
+
+		assert.Equal(t, 1, strings.Count(buf.String(), "request_id"))
+		assert.Equal(t, "req-2", decode(t, &buf)["request_id"])
+	})
+
+	t.Run("NoContext", func(t *testing.T) {
+		var buf bytes.Buffer
+		log, err := logger.New(&buf, config.Log{Level: "info", Format: config.LogFormatJSON})
+		require.NoError(t, err)
+
+		log.Info("hello")
+
+		record := decode(t, &buf)
+		assert.NotContains(t, record, "request_id")
+		assert.NotContains(t, record, "actor")
+		assert.NotContains(t, record, "principal_id")
+	})
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted stands in for the value of a secret.
+const Redacted = "[redacted]"
+
+// secretKeys are parts of attribute names whose values are never logged.
+var secretKeys = []string{"password", "secret", "token", "dsn", "authorization", "cookie", "api_key"}
+
+var (
+	// keywordPassword matches the password of a key=value DSN, as postgres takes it.
+	keywordPassword = regexp.MustCompile(`(?i)(password=)[^\s&]+`)
+	// urlPassword matches the password in the user info of a URL DSN.
+	urlPassword = regexp.MustCompile(`(://[^:/@\s]+:)[^@\s]+@`)
+)
+
+// redact is the ReplaceAttr of every handler. It hides the values of
+// attributes named like secrets and the passwords of DSNs in any text,
+// errors included.
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if isSecret(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(scrub(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			a.Value = slog.StringValue(scrub(err.Error()))
+		}
+	}
+
+	return a
+}
+
+// isSecret reports whether an attribute named key holds a secret.
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scrub replaces the passwords of DSNs in text.
+func scrub(text string) string {
+	text = keywordPassword.ReplaceAllString(text, "${1}"+Redacted)
+	return urlPassword.ReplaceAllString(text, "${1}"+Redacted+"@")
+}
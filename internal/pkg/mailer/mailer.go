@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+)
+
+// Message is a plain text mail to one recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers mail to users. Implementations for a real provider plug in
+// here; the ones in this package are for local development.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Definition Struct (Class)
+// logMailer logs every message instead of sending it.
+type logMailer struct {
+	logger *slog.Logger
+	from   string
+}
+
+// Definition Constructor
+func NewLogMailer(logger *slog.Logger, from string) Mailer {
+	return &logMailer{logger: logger, from: from}
+}
+
+// Definition Implement Methods (Send)
+func (m *logMailer) Send(ctx context.Context, msg Message) error {
+	m.logger.InfoContext(ctx, "mail", slog.String("from", m.from), slog.String("to", msg.To), slog.String("subject", msg.Subject), slog.String("body", msg.Body))
+	return nil
+}
+
+// Definition Struct (Class)
+// fileMailer writes every message as an .eml file, which mail clients open
+// as they would a received mail.
+type fileMailer struct {
+	mu    sync.Mutex
+	dir   string
+	from  string
+	clock clock.Clock
+	seq   int
+}
+
+// Definition Constructor
+// The directory is created when it doesn't exist yet.
+func NewFileMailer(dir string, from string, clock clock.Clock) (Mailer, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("create mail directory: %w", err)
+	}
+
+	return &fileMailer{dir: dir, from: from, clock: clock}, nil
+}
+
+// Definition Implement Methods (Send)
+func (m *fileMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	m.seq++
+	name := fmt.Sprintf("%s-%04d.eml", now.Format("20060102T150405.000000000"), m.seq)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format("Mon, 02 Jan 2006 15:04:05 -0700"))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	err := os.WriteFile(filepath.Join(m.dir, name), []byte(b.String()), 0o600)
+	if err != nil {
+		return fmt.Errorf("write mail: %w", err)
+	}
+
+	return nil
+}
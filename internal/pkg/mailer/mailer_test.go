@@ -0,0 +1,54 @@
+package mailer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogMailer(t *testing.T) {
+	var buf bytes.Buffer
+	m := mailer.NewLogMailer(slog.New(slog.NewJSONHandler(&buf, nil)), "no-reply@example.com")
+
+	err := m.Send(context.Background(), mailer.Message{To: "ali@gmail.com", Subject: "Hello", Body: "line one\nline two"})
+	require.NoError(t, err)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "ali@gmail.com", record["to"])
+	assert.Equal(t, "Hello", record["subject"])
+	assert.Equal(t, "line one\nline two", record["body"])
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	m, err := mailer.NewFileMailer(dir, "no-reply@example.com", clock.Fixed(now))
+	require.NoError(t, err)
+
+	for _, to := range []string{"ali@gmail.com", "sara@gmail.com"} {
+		require.NoError(t, m.Send(context.Background(), mailer.Message{To: to, Subject: "Hello", Body: "line one\nline two"}))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 2, "messages sent at the same instant don't overwrite each other")
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "From: no-reply@example.com\r\n")
+	assert.Contains(t, string(content), "To: ali@gmail.com\r\n")
+	assert.Contains(t, string(content), "Subject: Hello\r\n")
+	assert.Contains(t, string(content), "\r\n\r\nline one\r\nline two")
+}
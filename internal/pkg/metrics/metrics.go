@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the names of the metrics of the service.
+const Namespace = "user_manager"
+
+// Metrics holds the collectors of the service, all registered on one
+// registry. The server serves its own registry; tests pass a fresh one so
+// they count only what they did.
+type Metrics struct {
+	registry   *prometheus.Registry
+	requests   *prometheus.HistogramVec
+	operations *prometheus.CounterVec
+}
+
+// Definition Constructor
+func New(registry *prometheus.Registry) *Metrics {
+	m := &Metrics{
+		registry: registry,
+		requests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by method, route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "usecase",
+			Name:      "operations_total",
+			Help:      "Usecase operations run, by operation and outcome.",
+		}, []string{"operation", "outcome"}),
+	}
+	registry.MustRegister(m.requests, m.operations)
+
+	return m
+}
+
+// NewDefault returns metrics on a new registry that also collects the
+// runtime and process metrics of Go.
+func NewDefault() *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return New(registry)
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest records a served request. route must be a template, e.g.
+// /api/v1/user/:id, never the path, to keep the number of series bounded.
+func (m *Metrics) ObserveRequest(method string, route string, status int, elapsed time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Observe(elapsed.Seconds())
+}
+
+// CountOperation records an operation of a usecase that ended in outcome.
+func (m *Metrics) CountOperation(operation string, outcome string) {
+	m.operations.WithLabelValues(operation, outcome).Inc()
+}
+
+// WatchDB exports the connection pool statistics of db, as sql.DB.Stats
+// reports them at every scrape, labelled with name.
+func (m *Metrics) WatchDB(name string, db *sql.DB) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
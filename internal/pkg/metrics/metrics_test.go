@@ -0,0 +1,83 @@
+package metrics_test
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/glebarez/go-sqlite"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("CountOperation", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		m := metrics.New(registry)
+
+		m.CountOperation("user.create", "created")
+		m.CountOperation("user.create", "created")
+		m.CountOperation("user.create", "duplicate")
+
+		err := testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP user_manager_usecase_operations_total Usecase operations run, by operation and outcome.
+# TYPE user_manager_usecase_operations_total counter
+user_manager_usecase_operations_total{operation="user.create",outcome="created"} 2
+user_manager_usecase_operations_total{operation="user.create",outcome="duplicate"} 1
+`), "user_manager_usecase_operations_total")
+		assert.NoError(t, err)
+	})
+
+	t.Run("ObserveRequest", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		m := metrics.New(registry)
+
+		m.ObserveRequest(http.MethodGet, "/api/v1/user/:id", http.StatusNotFound, 20*time.Millisecond)
+
+		count, err := testutil.GatherAndCount(registry, "user_manager_http_request_duration_seconds")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("WatchDB", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		m := metrics.New(registry)
+
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "stats.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		db.SetMaxOpenConns(3)
+
+		require.NoError(t, m.WatchDB("sqlite", db))
+
+		err = testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP go_sql_max_open_connections Maximum number of open connections to the database.
+# TYPE go_sql_max_open_connections gauge
+go_sql_max_open_connections{db_name="sqlite"} 3
+`), "go_sql_max_open_connections")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		m := metrics.New(prometheus.NewRegistry())
+		m.CountOperation("user.find", "not_found")
+
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		body, err := io.ReadAll(w.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+		assert.Contains(t, string(body), `user_manager_usecase_operations_total{operation="user.find",outcome="not_found"} 1`)
+	})
+}
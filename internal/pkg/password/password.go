@@ -0,0 +1,48 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Definition Error Message
+var (
+	ErrMsgMismatch = errors.New("password does not match")
+	ErrMsgTooLong  = errors.New("password is longer than 72 bytes")
+)
+
+// dummyHash is compared against when there is no stored hash, so a login for
+// an unknown account costs as much as one with a wrong password.
+var dummyHash = []byte("$2a$10$sQZHxZDrblbbVRvxhRIalOHMNaZvJCXt3VO.AbF4HdkxlG5TVCWWO")
+
+// Hash returns the bcrypt hash of password.
+func Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return "", ErrMsgTooLong
+		}
+
+		return "", fmt.Errorf("hash password: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+// Compare reports ErrMsgMismatch unless password matches hash. An empty hash,
+// an account without a password, never matches.
+func Compare(hash string, password string) error {
+	if hash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return ErrMsgMismatch
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgMismatch, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package password_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/password"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPassword(t *testing.T) {
+	hash, err := password.Hash("correct horse")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "correct horse", hash)
+
+	t.Run("Match", func(t *testing.T) {
+		assert.NoError(t, password.Compare(hash, "correct horse"))
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		assert.True(t, errors.Is(password.Compare(hash, "battery staple"), password.ErrMsgMismatch))
+	})
+
+	t.Run("EmptyHashNeverMatches", func(t *testing.T) {
+		assert.True(t, errors.Is(password.Compare("", ""), password.ErrMsgMismatch))
+	})
+
+	t.Run("TooLong", func(t *testing.T) {
+		_, err := password.Hash(strings.Repeat("a", 73))
+		assert.True(t, errors.Is(err, password.ErrMsgTooLong))
+	})
+}
@@ -0,0 +1,20 @@
+package principal
+
+import (
+	"context"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+)
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated caller.
+func WithPrincipal(ctx context.Context, principal entities.Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the principal stored in ctx and whether there was one.
+func FromContext(ctx context.Context) (entities.Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(entities.Principal)
+	return principal, ok
+}
@@ -0,0 +1,76 @@
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Definition Error Message
+var (
+	ErrMsgCorrupted = errors.New("sealed value is corrupted or sealed with another key")
+)
+
+// Box encrypts small secrets, like TOTP secrets, before they are stored.
+// Values are sealed with AES-256-GCM under the SHA-256 of the key and
+// encoded as base64url nonce||ciphertext.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// Definition Constructor
+// An empty key falls back to a random one, which is fine for a single
+// process but leaves everything sealed unreadable after a restart.
+func NewBox(key string) *Box {
+	secret := []byte(key)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		_, _ = rand.Read(secret)
+	}
+
+	sum := sha256.Sum256(secret)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		// a 32 byte key is always valid
+		panic(err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+
+	return &Box{aead: aead}
+}
+
+// Seal encrypts plain.
+func (b *Box) Seal(plain string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value returned by Seal.
+func (b *Box) Open(sealed string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", ErrMsgCorrupted
+	}
+
+	nonce, ciphertext := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+	plain, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrMsgCorrupted
+	}
+
+	return string(plain), nil
+}
@@ -0,0 +1,49 @@
+package secretbox_test
+
+import (
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/secretbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBox(t *testing.T) {
+	box := secretbox.NewBox("test-key")
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		sealed, err := box.Seal("JBSWY3DPEHPK3PXP")
+		require.NoError(t, err)
+		assert.NotContains(t, sealed, "JBSWY3DPEHPK3PXP")
+
+		plain, err := box.Open(sealed)
+
+		require.NoError(t, err)
+		assert.Equal(t, "JBSWY3DPEHPK3PXP", plain)
+	})
+
+	t.Run("FreshNonce", func(t *testing.T) {
+		first, _ := box.Seal("secret")
+		second, _ := box.Seal("secret")
+
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("OtherKey", func(t *testing.T) {
+		sealed, err := box.Seal("secret")
+		require.NoError(t, err)
+
+		_, err = secretbox.NewBox("other-key").Open(sealed)
+		assert.ErrorIs(t, err, secretbox.ErrMsgCorrupted)
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		sealed, err := box.Seal("secret")
+		require.NoError(t, err)
+
+		for _, value := range []string{"", "!!", sealed[:10], sealed[:len(sealed)-2] + "AA"} {
+			_, err = box.Open(value)
+			assert.ErrorIs(t, err, secretbox.ErrMsgCorrupted, "%q", value)
+		}
+	})
+}
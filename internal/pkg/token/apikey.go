@@ -0,0 +1,51 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Definition API Key Format
+// An API key reads "umk_<12 hex>_<opaque>". Everything before the second
+// underscore is its prefix, stored in clear to find the key and show it in
+// listings; the whole key is only stored as its Hash.
+const (
+	APIKeyMarker = "umk_"
+	apiKeyIDSize = 6
+	apiKeyPrefix = len(APIKeyMarker) + 2*apiKeyIDSize
+)
+
+// NewAPIKey returns a random API key and its prefix.
+func NewAPIKey() (raw string, prefix string, err error) {
+	id := make([]byte, apiKeyIDSize)
+	_, err = rand.Read(id)
+	if err != nil {
+		return "", "", fmt.Errorf("generate api key: %w", err)
+	}
+
+	secret, err := NewOpaque()
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix = APIKeyMarker + hex.EncodeToString(id)
+	return prefix + "_" + secret, prefix, nil
+}
+
+// IsAPIKey reports whether raw looks like an API key rather than an access
+// token.
+func IsAPIKey(raw string) bool {
+	return strings.HasPrefix(raw, APIKeyMarker)
+}
+
+// APIKeyPrefix returns the prefix of the API key raw, and false when raw is
+// not shaped like one.
+func APIKeyPrefix(raw string) (string, bool) {
+	if !IsAPIKey(raw) || len(raw) <= apiKeyPrefix+1 || raw[apiKeyPrefix] != '_' {
+		return "", false
+	}
+
+	return raw[:apiKeyPrefix], true
+}
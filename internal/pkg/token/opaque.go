@@ -0,0 +1,31 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// opaqueSize is the number of random bytes in an opaque token.
+const opaqueSize = 32
+
+// NewOpaque returns a random url-safe token carrying no data of its own, the
+// server looks it up by Hash.
+func NewOpaque() (string, error) {
+	raw := make([]byte, opaqueSize)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// Hash is the hex SHA-256 of an opaque token, what gets stored. The tokens
+// are random, so a fast hash is enough to make a leaked table useless.
+func Hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,156 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Definition Error Message
+var (
+	ErrMsgInvalidToken = errors.New("invalid access token")
+	ErrMsgExpiredToken = errors.New("access token has expired")
+)
+
+// Options configures the signing of access tokens.
+type Options struct {
+	// Secret signs new tokens. An empty secret falls back to a random one,
+	// which is fine for a single process but invalidates tokens on restart.
+	Secret string
+	// PreviousSecrets still verify tokens signed before a key rotation.
+	PreviousSecrets []string
+	Issuer          string
+	TTL             time.Duration
+}
+
+// claims is the payload of an access token, the subject being the user id.
+type claims struct {
+	Email     string `json:"email"`
+	SessionID uint64 `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Definition Interface (Rules)
+type Manager interface {
+	Issue(principal entities.Principal) (entities.AuthToken, error)
+	Parse(raw string) (entities.Principal, error)
+}
+
+// Definition Struct (Class)
+// Tokens are HS256 JWTs whose kid header names the key that signed them, so
+// a rotated secret keeps verifying until it is dropped from PreviousSecrets.
+type manager struct {
+	kid     string
+	secret  []byte
+	keys    map[string][]byte
+	issuer  string
+	ttl     time.Duration
+	clock   clock.Clock
+	methods []string
+}
+
+// Definition Constructor
+func NewManager(options Options, clock clock.Clock) Manager {
+	secret := []byte(options.Secret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		_, _ = rand.Read(secret)
+	}
+
+	m := &manager{
+		kid:     keyID(secret),
+		secret:  secret,
+		keys:    map[string][]byte{keyID(secret): secret},
+		issuer:  options.Issuer,
+		ttl:     options.TTL,
+		clock:   clock,
+		methods: []string{jwt.SigningMethodHS256.Alg()},
+	}
+	for _, previous := range options.PreviousSecrets {
+		if previous != "" {
+			m.keys[keyID([]byte(previous))] = []byte(previous)
+		}
+	}
+
+	return m
+}
+
+// keyID derives a short public identifier of a secret.
+func keyID(secret []byte) string {
+	sum := sha256.Sum256(secret)
+	return hex.EncodeToString(sum[:4])
+}
+
+// Definition Implement Methods (Issue, Parse)
+func (m *manager) Issue(principal entities.Principal) (entities.AuthToken, error) {
+	now := m.clock.Now()
+	expiresAt := now.Add(m.ttl)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Email:     principal.Email,
+		SessionID: principal.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   strconv.FormatUint(principal.UserID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	token.Header["kid"] = m.kid
+
+	signed, err := token.SignedString(m.secret)
+	if err != nil {
+		return entities.AuthToken{}, fmt.Errorf("sign access token: %w", err)
+	}
+
+	return entities.AuthToken{
+		AccessToken: signed,
+		TokenType:   "Bearer",
+		ExpiresIn:   m.ttl,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (m *manager) Parse(raw string) (entities.Principal, error) {
+	var parsed claims
+	_, err := jwt.ParseWithClaims(raw, &parsed, m.key,
+		jwt.WithValidMethods(m.methods),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(m.clock.Now),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return entities.Principal{}, fmt.Errorf("%w:%w", ErrMsgExpiredToken, err)
+		}
+
+		return entities.Principal{}, fmt.Errorf("%w:%w", ErrMsgInvalidToken, err)
+	}
+
+	userID, err := strconv.ParseUint(parsed.Subject, 10, 64)
+	if err != nil {
+		return entities.Principal{}, fmt.Errorf("%w: bad subject %q", ErrMsgInvalidToken, parsed.Subject)
+	}
+
+	return entities.Principal{UserID: userID, Email: parsed.Email, SessionID: parsed.SessionID}, nil
+}
+
+// key picks the verification key named by the kid header.
+func (m *manager) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,110 @@
+package token_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/token"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	options := token.Options{Secret: "test-secret", Issuer: "user-manager", TTL: 15 * time.Minute}
+	principal := entities.Principal{UserID: 42, Email: "ali@example.com", SessionID: 7}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		tokens := token.NewManager(options, clock.Fixed(now))
+
+		issued, err := tokens.Issue(principal)
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer", issued.TokenType)
+		assert.Equal(t, now.Add(15*time.Minute), issued.ExpiresAt)
+
+		parsed, err := tokens.Parse(issued.AccessToken)
+
+		assert.NoError(t, err)
+		assert.Equal(t, principal, parsed)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		issued, err := token.NewManager(options, clock.Fixed(now)).Issue(principal)
+		assert.NoError(t, err)
+
+		_, err = token.NewManager(options, clock.Fixed(now.Add(16*time.Minute))).Parse(issued.AccessToken)
+
+		assert.True(t, errors.Is(err, token.ErrMsgExpiredToken))
+	})
+
+	t.Run("WrongSecret", func(t *testing.T) {
+		issued, err := token.NewManager(token.Options{Secret: "other", Issuer: "user-manager", TTL: time.Minute}, clock.Fixed(now)).Issue(principal)
+		assert.NoError(t, err)
+
+		_, err = token.NewManager(options, clock.Fixed(now)).Parse(issued.AccessToken)
+
+		assert.True(t, errors.Is(err, token.ErrMsgInvalidToken))
+	})
+
+	t.Run("WrongIssuer", func(t *testing.T) {
+		issued, err := token.NewManager(token.Options{Secret: "test-secret", Issuer: "someone-else", TTL: time.Minute}, clock.Fixed(now)).Issue(principal)
+		assert.NoError(t, err)
+
+		_, err = token.NewManager(options, clock.Fixed(now)).Parse(issued.AccessToken)
+
+		assert.True(t, errors.Is(err, token.ErrMsgInvalidToken))
+	})
+
+	t.Run("PreviousSecretStillVerifies", func(t *testing.T) {
+		issued, err := token.NewManager(options, clock.Fixed(now)).Issue(principal)
+		assert.NoError(t, err)
+
+		rotated := token.NewManager(token.Options{
+			Secret:          "new-secret",
+			PreviousSecrets: []string{"test-secret"},
+			Issuer:          "user-manager",
+			TTL:             time.Minute,
+		}, clock.Fixed(now))
+
+		parsed, err := rotated.Parse(issued.AccessToken)
+
+		assert.NoError(t, err)
+		assert.Equal(t, principal, parsed)
+	})
+
+	t.Run("UnsignedRejected", func(t *testing.T) {
+		unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.RegisteredClaims{
+			Issuer:    "user-manager",
+			Subject:   "42",
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		assert.NoError(t, err)
+
+		_, err = token.NewManager(options, clock.Fixed(now)).Parse(unsigned)
+
+		assert.True(t, errors.Is(err, token.ErrMsgInvalidToken))
+	})
+}
+
+func TestAPIKey(t *testing.T) {
+	raw, prefix, err := token.NewAPIKey()
+	assert.NoError(t, err)
+	assert.True(t, token.IsAPIKey(raw))
+	assert.Len(t, prefix, len("umk_")+12)
+
+	parsed, ok := token.APIKeyPrefix(raw)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsed)
+
+	other, _, err := token.NewAPIKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, raw, other)
+
+	for _, malformed := range []string{"", "umk_", prefix, prefix + "_", "umk_short_secret", "eyJhbGciOiJIUzI1NiJ9.e30.sig"} {
+		_, ok := token.APIKeyPrefix(malformed)
+		assert.False(t, ok, malformed)
+	}
+}
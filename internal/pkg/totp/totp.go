@@ -0,0 +1,118 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Definition TOTP Parameters
+// Codes follow RFC 6238 with the defaults every authenticator app supports:
+// HMAC-SHA1, six digits and a 30 second time step.
+const (
+	Digits     = 6
+	Period     = 30 * time.Second
+	modulus    = 1_000_000 // 10^Digits
+	secretSize = 20
+)
+
+// Definition Error Message
+var (
+	ErrMsgInvalidSecret = errors.New("invalid totp secret")
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns a random base32 secret, as shown to users who can't scan
+// the provisioning URI.
+func NewSecret() (string, error) {
+	raw := make([]byte, secretSize)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", fmt.Errorf("generate totp secret: %w", err)
+	}
+
+	return encoding.EncodeToString(raw), nil
+}
+
+// Step returns the time step t falls in.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code of secret for step.
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(key) == 0 {
+		return "", ErrMsgInvalidSecret
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", Digits, value%modulus), nil
+}
+
+// Match looks for code among the steps around now, skew steps either way to
+// allow for clock drift, and returns the step it belongs to. Callers must
+// remember that step to refuse the code again.
+func Match(secret string, code string, now time.Time, skew int) (int64, bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false, nil
+	}
+
+	current := Step(now)
+	for _, delta := range window(skew) {
+		step := current + int64(delta)
+
+		expected, err := Code(secret, step)
+		if err != nil {
+			return 0, false, err
+		}
+
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// window lists the step offsets to try, nearest first.
+func window(skew int) []int {
+	deltas := []int{0}
+	for i := 1; i <= skew; i++ {
+		deltas = append(deltas, -i, i)
+	}
+
+	return deltas
+}
+
+// URI returns the otpauth:// provisioning URI authenticator apps read from a
+// QR code.
+func URI(issuer string, account string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period/time.Second)))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
@@ -0,0 +1,91 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCode(t *testing.T) {
+	// the SHA1 vectors of RFC 6238, appendix B, cut to six digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := totp.Code(secret, totp.Step(time.Unix(unix, 0)))
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "at %d", unix)
+	}
+
+	_, err := totp.Code("not base32!", 1)
+	assert.ErrorIs(t, err, totp.ErrMsgInvalidSecret)
+}
+
+func TestMatch(t *testing.T) {
+	secret, err := totp.NewSecret()
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 17, 12, 0, 10, 0, time.UTC)
+	current := totp.Step(now)
+
+	codeAt := func(step int64) string {
+		code, err := totp.Code(secret, step)
+		require.NoError(t, err)
+		return code
+	}
+
+	t.Run("CurrentStep", func(t *testing.T) {
+		step, ok, err := totp.Match(secret, codeAt(current), now, 1)
+
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, current, step)
+	})
+
+	t.Run("Drift", func(t *testing.T) {
+		step, ok, _ := totp.Match(secret, codeAt(current-1), now, 1)
+		assert.True(t, ok)
+		assert.Equal(t, current-1, step)
+
+		step, ok, _ = totp.Match(secret, codeAt(current+1), now, 1)
+		assert.True(t, ok)
+		assert.Equal(t, current+1, step)
+
+		_, ok, _ = totp.Match(secret, codeAt(current-2), now, 1)
+		assert.False(t, ok, "outside the window")
+
+		_, ok, _ = totp.Match(secret, codeAt(current-1), now, 0)
+		assert.False(t, ok, "no drift allowed")
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+			_, ok, err := totp.Match(secret, code, now, 1)
+			assert.NoError(t, err)
+			assert.False(t, ok, "%q", code)
+		}
+	})
+}
+
+func TestURI(t *testing.T) {
+	uri := totp.URI("user-manager", "ali@gmail.com", "JBSWY3DPEHPK3PXP")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/user-manager:ali@gmail.com?"), uri)
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=user-manager")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
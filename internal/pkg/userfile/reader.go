@@ -0,0 +1,237 @@
+// Package userfile reads and writes users in the file formats the import and
+// export commands exchange with other systems.
+package userfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Definition Error Message
+var (
+	ErrMsgUnknownFormat = errors.New("unknown file format")
+	ErrMsgInvalidFile   = errors.New("invalid file")
+	ErrMsgInvalidRecord = errors.New("invalid record")
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+)
+
+// FormatFromPath guesses the format from the file extension.
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("%w: can't tell the format of %q", ErrMsgUnknownFormat, path)
+	}
+}
+
+// Record is one user as it appears in a file.
+type Record struct {
+	Name   string `json:"name"`
+	Family string `json:"family"`
+	Email  string `json:"email"`
+	Age    int    `json:"age"`
+}
+
+// RecordError reports a record that could not be decoded. Reading may go on
+// after it.
+type RecordError struct {
+	Row int
+	Err error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Definition Interface (Rules)
+// Reader returns the records of a file one at a time. Read returns the record
+// and its row (line for csv and ndjson, element for json, counting from 1), a
+// *RecordError for a bad record that can be skipped, io.EOF at the end and any
+// other error when the file can't be read any further.
+type Reader interface {
+	Read() (Record, int, error)
+}
+
+// Definition Constructor
+func NewReader(r io.Reader, format Format) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSON:
+		return newJSONReader(r)
+	case FormatNDJSON:
+		return &ndjsonReader{scanner: bufio.NewScanner(r)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrMsgUnknownFormat, format)
+	}
+}
+
+// csvReader maps the columns named in the header row; their order is free,
+// names are case-insensitive and unknown columns are ignored.
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading csv header: %w", ErrMsgInvalidFile, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+
+	for _, required := range []string{"name", "family", "email", "age"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: csv header has no %q column", ErrMsgInvalidFile, required)
+		}
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Read() (Record, int, error) {
+	fields, err := r.reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return Record{}, 0, io.EOF
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && !errors.Is(parseErr.Err, csv.ErrQuote) {
+			return Record{}, parseErr.Line, &RecordError{Row: parseErr.Line, Err: fmt.Errorf("%w: %w", ErrMsgInvalidRecord, parseErr.Err)}
+		}
+
+		return Record{}, 0, fmt.Errorf("%w:%w", ErrMsgInvalidFile, err)
+	}
+
+	row, _ := r.reader.FieldPos(0)
+
+	field := func(name string) string {
+		i := r.columns[name]
+		if i >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+
+	record := Record{
+		Name:   field("name"),
+		Family: field("family"),
+		Email:  field("email"),
+	}
+
+	if age := field("age"); age != "" {
+		record.Age, err = strconv.Atoi(age)
+		if err != nil {
+			return Record{}, row, &RecordError{Row: row, Err: fmt.Errorf("%w: age %q is not a number", ErrMsgInvalidRecord, age)}
+		}
+	}
+
+	return record, row, nil
+}
+
+// jsonReader streams the elements of a top-level array.
+type jsonReader struct {
+	decoder *json.Decoder
+	row     int
+}
+
+func newJSONReader(r io.Reader) (*jsonReader, error) {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInvalidFile, err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("%w: json file must hold an array of users", ErrMsgInvalidFile)
+	}
+
+	return &jsonReader{decoder: decoder}, nil
+}
+
+func (r *jsonReader) Read() (Record, int, error) {
+	if !r.decoder.More() {
+		return Record{}, 0, io.EOF
+	}
+
+	r.row++
+
+	var record Record
+	err := r.decoder.Decode(&record)
+	if err != nil {
+		// a wrong type leaves the decoder after the element, a syntax error doesn't
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return Record{}, r.row, &RecordError{Row: r.row, Err: fmt.Errorf("%w: %w", ErrMsgInvalidRecord, err)}
+		}
+
+		return Record{}, r.row, fmt.Errorf("%w: element %d: %w", ErrMsgInvalidFile, r.row, err)
+	}
+
+	return record, r.row, nil
+}
+
+// ndjsonReader decodes one user per line, skipping blank lines.
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonReader) Read() (Record, int, error) {
+	for r.scanner.Scan() {
+		r.line++
+
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record Record
+		err := json.Unmarshal(line, &record)
+		if err != nil {
+			return Record{}, r.line, &RecordError{Row: r.line, Err: fmt.Errorf("%w: %w", ErrMsgInvalidRecord, err)}
+		}
+
+		return record, r.line, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, 0, fmt.Errorf("%w:%w", ErrMsgInvalidFile, err)
+	}
+
+	return Record{}, 0, io.EOF
+}
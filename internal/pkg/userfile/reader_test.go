@@ -0,0 +1,116 @@
+package userfile_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/userfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type readResult struct {
+	record userfile.Record
+	row    int
+	bad    bool
+}
+
+func readAll(t *testing.T, reader userfile.Reader) []readResult {
+	t.Helper()
+
+	var results []readResult
+	for {
+		record, row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return results
+		}
+
+		var recordErr *userfile.RecordError
+		if errors.As(err, &recordErr) {
+			assert.ErrorIs(t, err, userfile.ErrMsgInvalidRecord)
+			results = append(results, readResult{row: row, bad: true})
+			continue
+		}
+		require.NoError(t, err)
+
+		results = append(results, readResult{record: record, row: row})
+	}
+}
+
+func TestReader(t *testing.T) {
+	ali := userfile.Record{Name: "Ali", Family: "Rezaei", Email: "ali@gmail.com", Age: 30}
+	sara := userfile.Record{Name: "Sara", Family: "Ahmadi", Email: "sara@gmail.com", Age: 25}
+
+	t.Run("CSV", func(t *testing.T) {
+		file := "\ufeffEmail,Name,Family,Age,Note\n" +
+			"ali@gmail.com,Ali,Rezaei,30,x\n" +
+			"sara@gmail.com,Sara,Ahmadi,old\n" +
+			"sara@gmail.com, Sara ,Ahmadi,25\n"
+
+		reader, err := userfile.NewReader(strings.NewReader(file), userfile.FormatCSV)
+		require.NoError(t, err)
+
+		assert.Equal(t, []readResult{
+			{record: ali, row: 2},
+			{row: 3, bad: true},
+			{record: sara, row: 4},
+		}, readAll(t, reader))
+	})
+
+	t.Run("CSVMissingColumn", func(t *testing.T) {
+		_, err := userfile.NewReader(strings.NewReader("name,family,email\n"), userfile.FormatCSV)
+
+		assert.ErrorIs(t, err, userfile.ErrMsgInvalidFile)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		file := `[
+			{"name":"Ali","family":"Rezaei","email":"ali@gmail.com","age":30},
+			{"name":"Sara","family":"Ahmadi","email":"sara@gmail.com","age":"old"},
+			{"name":"Sara","family":"Ahmadi","email":"sara@gmail.com","age":25}
+		]`
+
+		reader, err := userfile.NewReader(strings.NewReader(file), userfile.FormatJSON)
+		require.NoError(t, err)
+
+		assert.Equal(t, []readResult{
+			{record: ali, row: 1},
+			{row: 2, bad: true},
+			{record: sara, row: 3},
+		}, readAll(t, reader))
+	})
+
+	t.Run("JSONNotAnArray", func(t *testing.T) {
+		_, err := userfile.NewReader(strings.NewReader(`{"name":"Ali"}`), userfile.FormatJSON)
+
+		assert.ErrorIs(t, err, userfile.ErrMsgInvalidFile)
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		file := `{"name":"Ali","family":"Rezaei","email":"ali@gmail.com","age":30}
+
+{"name":"Sara",
+{"name":"Sara","family":"Ahmadi","email":"sara@gmail.com","age":25}
+`
+
+		reader, err := userfile.NewReader(strings.NewReader(file), userfile.FormatNDJSON)
+		require.NoError(t, err)
+
+		assert.Equal(t, []readResult{
+			{record: ali, row: 1},
+			{row: 3, bad: true},
+			{record: sara, row: 4},
+		}, readAll(t, reader))
+	})
+
+	t.Run("FormatFromPath", func(t *testing.T) {
+		format, err := userfile.FormatFromPath("users.JSONL")
+		assert.NoError(t, err)
+		assert.Equal(t, userfile.FormatNDJSON, format)
+
+		_, err = userfile.FormatFromPath("users.txt")
+		assert.ErrorIs(t, err, userfile.ErrMsgUnknownFormat)
+	})
+}
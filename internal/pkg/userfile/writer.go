@@ -0,0 +1,232 @@
+package userfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+)
+
+const FormatXLSX Format = "xlsx"
+
+// ContentType is the media type of a file in format.
+func ContentType(format Format) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Column is one exportable user field.
+type Column string
+
+const (
+	ColumnID        Column = "id"
+	ColumnName      Column = "name"
+	ColumnFamily    Column = "family"
+	ColumnEmail     Column = "email"
+	ColumnAge       Column = "age"
+	ColumnVersion   Column = "version"
+	ColumnCreatedAt Column = "created_at"
+	ColumnUpdatedAt Column = "updated_at"
+	ColumnCreatedBy Column = "created_by"
+	ColumnUpdatedBy Column = "updated_by"
+)
+
+// Columns lists every exportable column in their default order.
+var Columns = []Column{
+	ColumnID, ColumnName, ColumnFamily, ColumnEmail, ColumnAge,
+	ColumnVersion, ColumnCreatedAt, ColumnUpdatedAt, ColumnCreatedBy, ColumnUpdatedBy,
+}
+
+// ParseColumns parses a comma separated column list; an empty list selects
+// every column.
+func ParseColumns(raw string) ([]Column, error) {
+	if strings.TrimSpace(raw) == "" {
+		return Columns, nil
+	}
+
+	known := make(map[Column]bool, len(Columns))
+	for _, column := range Columns {
+		known[column] = true
+	}
+
+	parts := strings.Split(raw, ",")
+	columns := make([]Column, 0, len(parts))
+	seen := make(map[Column]bool, len(parts))
+	for _, part := range parts {
+		column := Column(strings.ToLower(strings.TrimSpace(part)))
+		if !known[column] {
+			return nil, fmt.Errorf("unknown column %q", part)
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("column %q listed twice", part)
+		}
+
+		seen[column] = true
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
+
+// value returns the column of user as a JSON-friendly value.
+func (c Column) value(user entities.User) interface{} {
+	switch c {
+	case ColumnID:
+		return user.ID
+	case ColumnName:
+		return user.Name
+	case ColumnFamily:
+		return user.Family
+	case ColumnEmail:
+		return user.Email
+	case ColumnAge:
+		return user.Age
+	case ColumnVersion:
+		return user.Version
+	case ColumnCreatedAt:
+		return user.CreatedAt.UTC().Format(time.RFC3339)
+	case ColumnUpdatedAt:
+		return user.UpdatedAt.UTC().Format(time.RFC3339)
+	case ColumnCreatedBy:
+		return user.CreatedBy
+	case ColumnUpdatedBy:
+		return user.UpdatedBy
+	default:
+		return nil
+	}
+}
+
+// text returns the column of user as text.
+func (c Column) text(user entities.User) string {
+	switch value := c.value(user).(type) {
+	case uint64:
+		return strconv.FormatUint(value, 10)
+	case int:
+		return strconv.Itoa(value)
+	case string:
+		return value
+	default:
+		return ""
+	}
+}
+
+// Definition Interface (Rules)
+// Writer writes users one at a time in a file format. Close completes the
+// file; it does not close the underlying io.Writer.
+type Writer interface {
+	Write(user entities.User) error
+	Flush() error
+	Close() error
+}
+
+// Definition Constructor
+func NewWriter(w io.Writer, format Format, columns []Column) (Writer, error) {
+	if len(columns) == 0 {
+		columns = Columns
+	}
+
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return &ndjsonWriter{w: w, columns: columns}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("%w: can't export %q", ErrMsgUnknownFormat, format)
+	}
+}
+
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []Column
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = string(column)
+	}
+
+	err := writer.Write(header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &csvWriter{writer: writer, columns: columns, record: make([]string, len(columns))}, nil
+}
+
+func (w *csvWriter) Write(user entities.User) error {
+	for i, column := range w.columns {
+		w.record[i] = column.text(user)
+	}
+
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (w *csvWriter) Close() error {
+	return w.Flush()
+}
+
+// ndjsonWriter writes one object per line with keys in column order.
+type ndjsonWriter struct {
+	w       io.Writer
+	columns []Column
+	buf     bytes.Buffer
+}
+
+func (w *ndjsonWriter) Write(user entities.User) error {
+	w.buf.Reset()
+	w.buf.WriteByte('{')
+	for i, column := range w.columns {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(string(column))
+		value, err := json.Marshal(column.value(user))
+		if err != nil {
+			return err
+		}
+
+		w.buf.Write(key)
+		w.buf.WriteByte(':')
+		w.buf.Write(value)
+	}
+	w.buf.WriteString("}\n")
+
+	_, err := w.w.Write(w.buf.Bytes())
+	return err
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return nil
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
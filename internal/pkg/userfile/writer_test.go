@@ -0,0 +1,113 @@
+package userfile_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/userfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	users := []entities.User{
+		{ID: 1, Name: "Ali", Family: "Rezaei", Email: "ali@gmail.com", Age: 30, Version: 1, CreatedAt: created},
+		{ID: 2, Name: `Sara "S", <admin>`, Family: "Ahmadi", Email: "sara@gmail.com", Age: 25, Version: 3, CreatedAt: created},
+	}
+
+	write := func(t *testing.T, format userfile.Format, columns []userfile.Column) []byte {
+		var buf bytes.Buffer
+		writer, err := userfile.NewWriter(&buf, format, columns)
+		require.NoError(t, err)
+
+		for _, user := range users {
+			require.NoError(t, writer.Write(user))
+		}
+		require.NoError(t, writer.Close())
+
+		return buf.Bytes()
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		out := write(t, userfile.FormatCSV, []userfile.Column{userfile.ColumnEmail, userfile.ColumnName, userfile.ColumnCreatedAt})
+
+		assert.Equal(t, "email,name,created_at\n"+
+			"ali@gmail.com,Ali,2026-01-02T03:04:05Z\n"+
+			`sara@gmail.com,"Sara ""S"", <admin>",2026-01-02T03:04:05Z`+"\n", string(out))
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		out := write(t, userfile.FormatNDJSON, []userfile.Column{userfile.ColumnID, userfile.ColumnEmail, userfile.ColumnAge})
+
+		assert.Equal(t, `{"id":1,"email":"ali@gmail.com","age":30}`+"\n"+
+			`{"id":2,"email":"sara@gmail.com","age":25}`+"\n", string(out))
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		out := write(t, userfile.FormatXLSX, []userfile.Column{userfile.ColumnID, userfile.ColumnName})
+
+		archive, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+		require.NoError(t, err)
+
+		parts := map[string][]byte{}
+		for _, file := range archive.File {
+			reader, err := file.Open()
+			require.NoError(t, err)
+			parts[file.Name], err = io.ReadAll(reader)
+			require.NoError(t, err)
+		}
+
+		for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+			require.Contains(t, parts, name)
+		}
+
+		var sheet struct {
+			Rows []struct {
+				Ref   string `xml:"r,attr"`
+				Cells []struct {
+					Ref    string `xml:"r,attr"`
+					Type   string `xml:"t,attr"`
+					Value  string `xml:"v"`
+					Inline string `xml:"is>t"`
+				} `xml:"c"`
+			} `xml:"sheetData>row"`
+		}
+		require.NoError(t, xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet))
+
+		require.Len(t, sheet.Rows, 3)
+		assert.Equal(t, "id", sheet.Rows[0].Cells[0].Inline)
+		assert.Equal(t, "A2", sheet.Rows[1].Cells[0].Ref)
+		assert.Equal(t, "1", sheet.Rows[1].Cells[0].Value)
+		assert.Equal(t, "B3", sheet.Rows[2].Cells[1].Ref)
+		assert.Equal(t, "inlineStr", sheet.Rows[2].Cells[1].Type)
+		assert.Equal(t, `Sara "S", <admin>`, sheet.Rows[2].Cells[1].Inline)
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		_, err := userfile.NewWriter(io.Discard, userfile.FormatJSON, nil)
+
+		assert.ErrorIs(t, err, userfile.ErrMsgUnknownFormat)
+	})
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := userfile.ParseColumns("")
+	assert.NoError(t, err)
+	assert.Equal(t, userfile.Columns, columns)
+
+	columns, err = userfile.ParseColumns(" Email,id ")
+	assert.NoError(t, err)
+	assert.Equal(t, []userfile.Column{userfile.ColumnEmail, userfile.ColumnID}, columns)
+
+	_, err = userfile.ParseColumns("email,password")
+	assert.Error(t, err)
+
+	_, err = userfile.ParseColumns("email,email")
+	assert.Error(t, err)
+}
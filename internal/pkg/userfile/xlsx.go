@@ -0,0 +1,147 @@
+package userfile
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+)
+
+// xlsxWriter streams a single-sheet workbook. The zip format lets the sheet
+// be written first, row by row, and the small fixed parts after it, so only
+// the current row is ever held in memory. Strings are stored inline, which
+// avoids a shared strings table that would have to be built in memory.
+type xlsxWriter struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	columns []Column
+	row     int
+}
+
+func newXLSXWriter(w io.Writer, columns []Column) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+
+	part, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &xlsxWriter{zip: archive, sheet: bufio.NewWriter(part), columns: columns}
+	writer.sheet.WriteString(xml.Header)
+	writer.sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = string(column)
+	}
+	writer.writeRow(header)
+
+	return writer, nil
+}
+
+func (w *xlsxWriter) Write(user entities.User) error {
+	values := make([]interface{}, len(w.columns))
+	for i, column := range w.columns {
+		values[i] = column.value(user)
+	}
+
+	return w.writeRow(values)
+}
+
+// writeRow writes numbers as numeric cells and everything else as inline strings.
+func (w *xlsxWriter) writeRow(values []interface{}) error {
+	w.row++
+	row := strconv.Itoa(w.row)
+
+	w.sheet.WriteString(`<row r="` + row + `">`)
+	for i, value := range values {
+		ref := xlsxColumnName(i) + row
+		switch value := value.(type) {
+		case uint64:
+			w.sheet.WriteString(`<c r="` + ref + `"><v>` + strconv.FormatUint(value, 10) + `</v></c>`)
+		case int:
+			w.sheet.WriteString(`<c r="` + ref + `"><v>` + strconv.Itoa(value) + `</v></c>`)
+		case string:
+			w.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(w.sheet, []byte(value))
+			w.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := w.sheet.WriteString(`</row>`)
+
+	return err
+}
+
+func (w *xlsxWriter) Flush() error {
+	return w.sheet.Flush()
+}
+
+func (w *xlsxWriter) Close() error {
+	w.sheet.WriteString(`</sheetData></worksheet>`)
+	err := w.sheet.Flush()
+	if err != nil {
+		return err
+	}
+
+	for _, part := range xlsxParts {
+		file, err := w.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(file, xml.Header+part.content)
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.zip.Close()
+}
+
+// xlsxColumnName turns a zero-based column index into A, B, ..., Z, AA, ...
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+
+	return name
+}
+
+// xlsxParts are the fixed parts of the workbook besides the sheet.
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{
+		name: "[Content_Types].xml",
+		content: `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`,
+	},
+	{
+		name: "_rels/.rels",
+		content: `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`,
+	},
+	{
+		name: "xl/workbook.xml",
+		content: `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Users" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`,
+	},
+	{
+		name: "xl/_rels/workbook.xml.rels",
+		content: `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`,
+	},
+}
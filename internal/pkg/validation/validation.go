@@ -0,0 +1,233 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/fa"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	fatranslations "github.com/go-playground/validator/v10/translations/fa"
+)
+
+// Definition Locales
+// English is the fallback for requests asking for none of them.
+const (
+	LocaleEnglish = "en"
+	LocalePersian = "fa"
+)
+
+// FieldError is one rule a field broke. Field is the name the client sent
+// it under and JSONPath where it sits in the request, e.g. $.users[1].email.
+type FieldError struct {
+	Field    string `json:"field"`
+	JSONPath string `json:"json_path"`
+	Rule     string `json:"rule"`
+	Param    string `json:"param,omitempty"`
+	Message  string `json:"message"`
+}
+
+// RuleMaxBytes limits the length of a string in bytes rather than in
+// characters as max does, e.g. max_bytes=72 for what bcrypt can hash.
+const RuleMaxBytes = "max_bytes"
+
+// New returns a validator reporting fields by their json name, or their form
+// name for query parameters, instead of the Go one, that knows RuleMaxBytes.
+func New() *validator.Validate {
+	validate := validator.New()
+	_ = validate.RegisterValidation(RuleMaxBytes, maxBytes)
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+
+		return ""
+	})
+
+	return validate
+}
+
+// maxBytes checks a string is at most as many bytes long as the parameter.
+func maxBytes(fl validator.FieldLevel) bool {
+	limit, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		panic(fmt.Sprintf("bad %s parameter %q", RuleMaxBytes, fl.Param()))
+	}
+
+	return len(fl.Field().String()) <= limit
+}
+
+// Translator holds the messages of validate in every supported locale.
+type Translator struct {
+	uni *ut.UniversalTranslator
+}
+
+// NewTranslator registers the English and Persian messages of the built in
+// rules on validate.
+func NewTranslator(validate *validator.Validate) (*Translator, error) {
+	english, persian := en.New(), fa.New()
+	uni := ut.New(english, english, persian)
+
+	trans, _ := uni.GetTranslator(LocaleEnglish)
+	err := entranslations.RegisterDefaultTranslations(validate, trans)
+	if err != nil {
+		return nil, err
+	}
+	err = registerTranslation(validate, trans, RuleMaxBytes, "{0} must be at most {1} bytes long")
+	if err != nil {
+		return nil, err
+	}
+
+	trans, _ = uni.GetTranslator(LocalePersian)
+	err = fatranslations.RegisterDefaultTranslations(validate, trans)
+	if err != nil {
+		return nil, err
+	}
+	err = registerTranslation(validate, trans, RuleMaxBytes, "طول {0} باید حداکثر {1} بایت باشد")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Translator{uni: uni}, nil
+}
+
+// registerTranslation sets the message of rule in the language of trans,
+// {0} being the field and {1} the parameter of the rule.
+func registerTranslation(validate *validator.Validate, trans ut.Translator, rule string, message string) error {
+	return validate.RegisterTranslation(rule, trans, func(trans ut.Translator) error {
+		return trans.Add(rule, message, false)
+	}, func(trans ut.Translator, fe validator.FieldError) string {
+		text, err := trans.T(rule, fe.Field(), fe.Param())
+		if err != nil {
+			return fe.Error()
+		}
+
+		return text
+	})
+}
+
+// Match returns the translator of the locale an Accept-Language header
+// prefers most, English when it names none of them.
+func (t *Translator) Match(acceptLanguage string) ut.Translator {
+	for _, locale := range preferredLocales(acceptLanguage) {
+		trans, found := t.uni.GetTranslator(locale)
+		if found {
+			return trans
+		}
+	}
+
+	return t.uni.GetFallback()
+}
+
+// preferredLocales lists the locales of an Accept-Language header by
+// descending quality, each followed by its base language, e.g. fa_ir, fa.
+func preferredLocales(header string) []string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "-", "_"))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, weighted{locale: tag, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	locales := make([]string, 0, 2*len(ranges))
+	for _, r := range ranges {
+		locales = append(locales, r.locale)
+		if base, _, ok := strings.Cut(r.locale, "_"); ok {
+			locales = append(locales, base)
+		}
+	}
+
+	return locales
+}
+
+// Fields lists the rules err says were broken with their messages in the
+// language of trans, or nil when err isn't a validation failure. Without a
+// translator the messages are the ones of the validator.
+func Fields(err error, trans ut.Translator) []FieldError {
+	var failed validator.ValidationErrors
+	if !errors.As(err, &failed) {
+		return nil
+	}
+
+	fields := make([]FieldError, len(failed))
+	for i, fe := range failed {
+		message := fe.Error()
+		if trans != nil {
+			message = fe.Translate(trans)
+		}
+
+		fields[i] = FieldError{
+			Field:    fe.Field(),
+			JSONPath: jsonPath(fe.Namespace()),
+			Rule:     fe.Tag(),
+			Param:    fe.Param(),
+			Message:  message,
+		}
+	}
+
+	return fields
+}
+
+// jsonPath turns the namespace of a field, which starts with the name of
+// the validated struct, into a path from the root of the request.
+func jsonPath(namespace string) string {
+	_, path, ok := strings.Cut(namespace, ".")
+	if !ok {
+		path = namespace
+	}
+
+	return "$." + path
+}
+
+type contextKey struct{}
+
+// WithTranslator returns a copy of ctx carrying the translator of the
+// language the request asked for.
+func WithTranslator(ctx context.Context, trans ut.Translator) context.Context {
+	return context.WithValue(ctx, contextKey{}, trans)
+}
+
+// TranslatorFromContext returns the translator stored in ctx, or nil when
+// there is none.
+func TranslatorFromContext(ctx context.Context) ut.Translator {
+	trans, _ := ctx.Value(contextKey{}).(ut.Translator)
+	return trans
+}
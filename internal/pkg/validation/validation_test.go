@@ -0,0 +1,103 @@
+package validation_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	Email string `json:"email,omitempty" validate:"required,email"`
+}
+
+type batch struct {
+	Mode  string `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	Users []item `json:"users" validate:"required,dive"`
+	Limit int    `form:"limit" validate:"omitempty,lte=100"`
+}
+
+func TestFields(t *testing.T) {
+	validate := validation.New()
+	translator, err := validation.NewTranslator(validate)
+	require.NoError(t, err)
+
+	err = validate.Struct(batch{Mode: "sometimes", Users: []item{{Email: "a@gmail.com"}, {Email: "nope"}}, Limit: 500})
+	require.Error(t, err)
+
+	t.Run("English", func(t *testing.T) {
+		fields := validation.Fields(err, translator.Match("en-US,en;q=0.9"))
+
+		assert.Equal(t, []validation.FieldError{
+			{Field: "mode", JSONPath: "$.mode", Rule: "oneof", Param: "atomic best_effort", Message: "mode must be one of [atomic best_effort]"},
+			{Field: "email", JSONPath: "$.users[1].email", Rule: "email", Message: "email must be a valid email address"},
+			{Field: "limit", JSONPath: "$.limit", Rule: "lte", Param: "100", Message: "limit must be 100 or less"},
+		}, fields)
+	})
+
+	t.Run("Persian", func(t *testing.T) {
+		fields := validation.Fields(err, translator.Match("en;q=0.5, fa-IR"))
+
+		require.Len(t, fields, 3)
+		assert.Equal(t, "email", fields[1].Field, "field names are not translated")
+		assert.Equal(t, "email باید یک ایمیل معتبر باشد", fields[1].Message)
+	})
+
+	t.Run("UnsupportedFallsBackToEnglish", func(t *testing.T) {
+		assert.Equal(t, validation.LocaleEnglish, translator.Match("de-DE, fr;q=0.8").Locale())
+		assert.Equal(t, validation.LocaleEnglish, translator.Match("").Locale())
+		assert.Equal(t, validation.LocaleEnglish, translator.Match("fa;q=0, en;q=0.1").Locale())
+	})
+
+	t.Run("WithoutTranslator", func(t *testing.T) {
+		fields := validation.Fields(err, nil)
+
+		require.Len(t, fields, 3)
+		assert.Contains(t, fields[1].Message, "failed on the 'email' tag")
+	})
+
+	t.Run("NotAValidationError", func(t *testing.T) {
+		assert.Nil(t, validation.Fields(context.Canceled, nil))
+	})
+}
+
+func TestTranslatorFromContext(t *testing.T) {
+	translator, err := validation.NewTranslator(validation.New())
+	require.NoError(t, err)
+
+	assert.Nil(t, validation.TranslatorFromContext(context.Background()))
+
+	ctx := validation.WithTranslator(context.Background(), translator.Match("fa"))
+	assert.Equal(t, validation.LocalePersian, validation.TranslatorFromContext(ctx).Locale())
+}
+
+func TestMaxBytes(t *testing.T) {
+	type credentials struct {
+		Password string `json:"password" validate:"max_bytes=72"`
+	}
+
+	validate := validation.New()
+	translator, err := validation.NewTranslator(validate)
+	require.NoError(t, err)
+
+	t.Run("CountsBytes", func(t *testing.T) {
+		assert.NoError(t, validate.Struct(credentials{Password: strings.Repeat("a", 72)}))
+		assert.NoError(t, validate.Struct(credentials{Password: strings.Repeat("رمز", 12)}), "36 characters, 72 bytes")
+		assert.Error(t, validate.Struct(credentials{Password: strings.Repeat("رمز", 13) + "ز"}), "40 characters, 80 bytes")
+	})
+
+	t.Run("Messages", func(t *testing.T) {
+		err := validate.Struct(credentials{Password: strings.Repeat("a", 73)})
+
+		english := validation.Fields(err, translator.Match("en"))
+		require.Len(t, english, 1)
+		assert.Equal(t, validation.FieldError{Field: "password", JSONPath: "$.password", Rule: validation.RuleMaxBytes, Param: "72", Message: "password must be at most 72 bytes long"}, english[0])
+
+		persian := validation.Fields(err, translator.Match("fa"))
+		require.Len(t, persian, 1)
+		assert.Equal(t, "طول password باید حداکثر 72 بایت باشد", persian[0].Message)
+	})
+}
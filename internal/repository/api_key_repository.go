@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"gorm.io/gorm"
+)
+
+// Definition Error Message
+var (
+	ErrMsgAPIKeyNotFound = errors.New("api key not found")
+)
+
+// Definition Interface (Rules)
+type APIKeyRepository interface {
+	Create(ctx context.Context, key entities.APIKey) (entities.APIKey, error)
+	FindByPrefix(ctx context.Context, prefix string) (entities.APIKey, error)
+	List(ctx context.Context) ([]entities.APIKey, error)
+	Revoke(ctx context.Context, id uint64) error
+	Touch(ctx context.Context, id uint64, usedAt time.Time) error
+}
+
+// Definition Struct (Class)
+type apiKeyRepository struct {
+	db    *gorm.DB
+	clock clock.Clock
+}
+
+// Definition Constructor
+func NewAPIKeyRepository(db *gorm.DB, clock clock.Clock) APIKeyRepository {
+	return &apiKeyRepository{db: db, clock: clock}
+}
+
+// Definition Implement Methods (Create, FindByPrefix, List, Revoke, Touch)
+func (r *apiKeyRepository) Create(ctx context.Context, key entities.APIKey) (entities.APIKey, error) {
+	key.ID = 0
+	key.CreatedAt = r.clock.Now()
+	key.CreatedBy = actor.FromContext(ctx)
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+
+	err := connection(ctx, r.db).Create(&key).Error
+	if err != nil {
+		return entities.APIKey{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return key, nil
+}
+
+// Definition Implement Methods (Create, FindByPrefix, List, Revoke, Touch)
+// FindByPrefix returns the key with prefix, revoked and expired ones too.
+func (r *apiKeyRepository) FindByPrefix(ctx context.Context, prefix string) (entities.APIKey, error) {
+	var key entities.APIKey
+	err := connection(ctx, r.db).Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.APIKey{}, fmt.Errorf("%w:%w", ErrMsgAPIKeyNotFound, err)
+		}
+
+		return entities.APIKey{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return key, nil
+}
+
+// Definition Implement Methods (Create, FindByPrefix, List, Revoke, Touch)
+// List returns every key, revoked and expired ones too, oldest first.
+func (r *apiKeyRepository) List(ctx context.Context) ([]entities.APIKey, error) {
+	keys := []entities.APIKey{}
+	err := connection(ctx, r.db).Order("id").Find(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return keys, nil
+}
+
+// Definition Implement Methods (Create, FindByPrefix, List, Revoke, Touch)
+// Revoke disables a key for good. Revoking it again keeps the first time.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uint64) error {
+	result := connection(ctx, r.db).Model(&entities.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", r.clock.Now())
+	if result.Error != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		var count int64
+		err := connection(ctx, r.db).Model(&entities.APIKey{}).Where("id = ?", id).Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+		if count == 0 {
+			return ErrMsgAPIKeyNotFound
+		}
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (Create, FindByPrefix, List, Revoke, Touch)
+// Touch records that the key was used at usedAt.
+func (r *apiKeyRepository) Touch(ctx context.Context, id uint64, usedAt time.Time) error {
+	err := connection(ctx, r.db).Model(&entities.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+	"gorm.io/gorm"
+)
+
+// Definition Interface (Rules)
+// Events are append-only, there is no way to change or remove one.
+type AuditRepository interface {
+	Record(ctx context.Context, event entities.AuditEvent) (entities.AuditEvent, error)
+	List(ctx context.Context, query entities.AuditQuery) (entities.AuditPage, error)
+}
+
+// Definition Struct (Class)
+type auditRepository struct {
+	db    *gorm.DB
+	clock clock.Clock
+}
+
+// Definition Constructor
+func NewAuditRepository(db *gorm.DB, clock clock.Clock) AuditRepository {
+	return &auditRepository{db: db, clock: clock}
+}
+
+// Definition Implement Methods (Record, List)
+// Record stores event stamped with the actor and request id of ctx. Called
+// with the context of a transaction, the event is only kept if it commits.
+func (r *auditRepository) Record(ctx context.Context, event entities.AuditEvent) (entities.AuditEvent, error) {
+	event.ID = 0
+	event.Actor = actor.FromContext(ctx)
+	event.RequestID = requestid.FromContext(ctx)
+	event.CreatedAt = r.clock.Now()
+	if event.Changes == nil {
+		event.Changes = []entities.AuditChange{}
+	}
+
+	err := connection(ctx, r.db).Create(&event).Error
+	if err != nil {
+		return entities.AuditEvent{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return event, nil
+}
+
+// Definition Implement Methods (Record, List)
+// List returns the events matching query newest first. From is inclusive,
+// To exclusive.
+func (r *auditRepository) List(ctx context.Context, query entities.AuditQuery) (entities.AuditPage, error) {
+	query = query.Normalize()
+
+	db := connection(ctx, r.db).Model(&entities.AuditEvent{})
+	if query.UserID != nil {
+		db = db.Where("user_id = ?", *query.UserID)
+	}
+	if query.Actor != "" {
+		db = db.Where("actor = ?", query.Actor)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at < ?", *query.To)
+	}
+	if query.BeforeID != 0 {
+		db = db.Where("id < ?", query.BeforeID)
+	}
+
+	// one more than asked tells whether a next page exists
+	events := []entities.AuditEvent{}
+	err := db.Order("id DESC").Limit(query.Limit + 1).Find(&events).Error
+	if err != nil {
+		return entities.AuditPage{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return auditPage(events, query.Limit), nil
+}
+
+// auditPage cuts events, fetched one past limit, down to a page.
+func auditPage(events []entities.AuditEvent, limit int) entities.AuditPage {
+	page := entities.AuditPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		page.NextBeforeID = page.Events[limit-1].ID
+	}
+
+	return page
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Definition Error Message
+var (
+	ErrMsgLockoutNotFound = errors.New("lockout not found")
+)
+
+// Definition Interface (Rules)
+type LockoutRepository interface {
+	Find(ctx context.Context, key string) (entities.Lockout, error)
+	RecordFailure(ctx context.Context, key string, windowStart time.Time) (entities.Lockout, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+// Definition Struct (Class)
+type lockoutRepository struct {
+	db    *gorm.DB
+	clock clock.Clock
+}
+
+// Definition Constructor
+func NewLockoutRepository(db *gorm.DB, clock clock.Clock) LockoutRepository {
+	return &lockoutRepository{db: db, clock: clock}
+}
+
+// Definition Implement Methods (Find, RecordFailure, Lock, Reset)
+func (r *lockoutRepository) Find(ctx context.Context, key string) (entities.Lockout, error) {
+	var lockout entities.Lockout
+	err := connection(ctx, r.db).Where("key = ?", key).First(&lockout).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Lockout{}, fmt.Errorf("%w:%w", ErrMsgLockoutNotFound, err)
+		}
+
+		return entities.Lockout{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return lockout, nil
+}
+
+// Definition Implement Methods (Find, RecordFailure, Lock, Reset)
+// RecordFailure counts one more failure of key and returns the lockout.
+// Failures before windowStart are forgotten. The count is incremented by the
+// database, so concurrent failures are all counted.
+func (r *lockoutRepository) RecordFailure(ctx context.Context, key string, windowStart time.Time) (entities.Lockout, error) {
+	now := r.clock.Now()
+
+	var lockout entities.Lockout
+	err := connection(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"failures":        gorm.Expr("CASE WHEN credential_lockouts.last_failure_at < ? THEN 1 ELSE credential_lockouts.failures + 1 END", windowStart),
+				"last_failure_at": now,
+			}),
+		}).Create(&entities.Lockout{Key: key, Failures: 1, LastFailureAt: now}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("key = ?", key).First(&lockout).Error
+	})
+	if err != nil {
+		return entities.Lockout{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return lockout, nil
+}
+
+// Definition Implement Methods (Find, RecordFailure, Lock, Reset)
+// Lock locks key until until, counting one more lockout and starting the
+// failures over.
+func (r *lockoutRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	result := connection(ctx, r.db).Model(&entities.Lockout{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"failures":     0,
+		"lockouts":     gorm.Expr("lockouts + 1"),
+		"locked_until": until,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrMsgLockoutNotFound
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (Find, RecordFailure, Lock, Reset)
+// Reset forgets every failure and lockout of key. Resetting a key without
+// any is a no-op.
+func (r *lockoutRepository) Reset(ctx context.Context, key string) error {
+	err := connection(ctx, r.db).Where("key = ?", key).Delete(&entities.Lockout{}).Error
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
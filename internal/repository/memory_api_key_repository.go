@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+)
+
+// Definition Struct (Class)
+// memoryAPIKeyRepository keeps API keys in a map guarded by a RWMutex,
+// mirroring the GORM repository.
+type memoryAPIKeyRepository struct {
+	mu     sync.RWMutex
+	clock  clock.Clock
+	nextID uint64
+	keys   map[uint64]entities.APIKey
+}
+
+// Definition Constructor
+func NewMemoryAPIKeyRepository(clock clock.Clock) APIKeyRepository {
+	return &memoryAPIKeyRepository{
+		clock: clock,
+		keys:  make(map[uint64]entities.APIKey),
+	}
+}
+
+// Definition Implement Methods (Create, FindByPrefix, List, Revoke, Touch)
+func (r *memoryAPIKeyRepository) Create(ctx context.Context, key entities.APIKey) (entities.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.keys {
+		if stored.Prefix == key.Prefix {
+			return entities.APIKey{}, ErrMsgInternalServerError
+		}
+	}
+
+	r.nextID++
+	key.ID = r.nextID
+	key.CreatedAt = r.clock.Now()
+	key.CreatedBy = actor.FromContext(ctx)
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+	key.Scopes = slices.Clone(key.Scopes)
+
+	r.remember(ctx, key.ID)
+	r.keys[key.ID] = key
+
+	return key, nil
+}
+
+// Definition Implement Methods (Create, FindByPrefix, List, Revoke, Touch)
+func (r *memoryAPIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (entities.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			return key, nil
+		}
+	}
+
+	return entities.APIKey{}, ErrMsgAPIKeyNotFound
+}
+
+// Definition Implement Methods (Create, FindByPrefix, List, Revoke, Touch)
+func (r *memoryAPIKeyRepository) List(ctx context.Context) ([]entities.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]entities.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b entities.APIKey) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return keys, nil
+}
+
+// Definition Implement Methods (Create, FindByPrefix, List, Revoke, Touch)
+func (r *memoryAPIKeyRepository) Revoke(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return ErrMsgAPIKeyNotFound
+	}
+
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	now := r.clock.Now()
+	key.RevokedAt = &now
+	r.remember(ctx, id)
+	r.keys[id] = key
+
+	return nil
+}
+
+// Definition Implement Methods (Create, FindByPrefix, List, Revoke, Touch)
+func (r *memoryAPIKeyRepository) Touch(ctx context.Context, id uint64, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil
+	}
+
+	key.LastUsedAt = &usedAt
+	r.remember(ctx, id)
+	r.keys[id] = key
+
+	return nil
+}
+
+// remember records how to undo the next write of a key when ctx carries a
+// memory transaction. Callers must hold the lock.
+func (r *memoryAPIKeyRepository) remember(ctx context.Context, id uint64) {
+	tx := memoryTxFromContext(ctx)
+	if tx == nil {
+		return
+	}
+
+	previous, existed := r.keys[id]
+	tx.onRollback(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if existed {
+			r.keys[id] = previous
+		} else {
+			delete(r.keys, id)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+)
+
+// Definition Struct (Class)
+// memoryAuditRepository keeps events in a slice ordered by id, guarded by a
+// RWMutex, mirroring the GORM repository.
+type memoryAuditRepository struct {
+	mu     sync.RWMutex
+	clock  clock.Clock
+	nextID uint64
+	events []entities.AuditEvent
+}
+
+// Definition Constructor
+func NewMemoryAuditRepository(clock clock.Clock) AuditRepository {
+	return &memoryAuditRepository{clock: clock}
+}
+
+// Definition Implement Methods (Record, List)
+func (r *memoryAuditRepository) Record(ctx context.Context, event entities.AuditEvent) (entities.AuditEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	event.ID = r.nextID
+	event.Actor = actor.FromContext(ctx)
+	event.RequestID = requestid.FromContext(ctx)
+	event.CreatedAt = r.clock.Now()
+	event.Changes = slices.Clone(event.Changes)
+	if event.Changes == nil {
+		event.Changes = []entities.AuditChange{}
+	}
+
+	if tx := memoryTxFromContext(ctx); tx != nil {
+		id := event.ID
+		tx.onRollback(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			r.events = slices.DeleteFunc(r.events, func(event entities.AuditEvent) bool { return event.ID == id })
+		})
+	}
+
+	r.events = append(r.events, event)
+	return event, nil
+}
+
+// Definition Implement Methods (Record, List)
+func (r *memoryAuditRepository) List(ctx context.Context, query entities.AuditQuery) (entities.AuditPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	query = query.Normalize()
+
+	events := []entities.AuditEvent{}
+	for i := len(r.events) - 1; i >= 0 && len(events) <= query.Limit; i-- {
+		event := r.events[i]
+		switch {
+		case query.UserID != nil && event.UserID != *query.UserID,
+			query.Actor != "" && event.Actor != query.Actor,
+			query.Action != "" && event.Action != query.Action,
+			query.From != nil && event.CreatedAt.Before(*query.From),
+			query.To != nil && !event.CreatedAt.Before(*query.To),
+			query.BeforeID != 0 && event.ID >= query.BeforeID:
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	return auditPage(events, query.Limit), nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+)
+
+// Definition Struct (Class)
+// memoryLockoutRepository keeps lockouts in a map keyed by their key,
+// guarded by a Mutex, mirroring the GORM repository.
+type memoryLockoutRepository struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	lockouts map[string]entities.Lockout
+}
+
+// Definition Constructor
+func NewMemoryLockoutRepository(clock clock.Clock) LockoutRepository {
+	return &memoryLockoutRepository{
+		clock:    clock,
+		lockouts: make(map[string]entities.Lockout),
+	}
+}
+
+// Definition Implement Methods (Find, RecordFailure, Lock, Reset)
+func (r *memoryLockoutRepository) Find(ctx context.Context, key string) (entities.Lockout, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lockout, ok := r.lockouts[key]
+	if !ok {
+		return entities.Lockout{}, ErrMsgLockoutNotFound
+	}
+
+	return lockout, nil
+}
+
+// Definition Implement Methods (Find, RecordFailure, Lock, Reset)
+func (r *memoryLockoutRepository) RecordFailure(ctx context.Context, key string, windowStart time.Time) (entities.Lockout, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lockout, ok := r.lockouts[key]
+	if !ok {
+		lockout = entities.Lockout{Key: key}
+	}
+
+	if lockout.LastFailureAt.Before(windowStart) {
+		lockout.Failures = 0
+	}
+	lockout.Failures++
+	lockout.LastFailureAt = r.clock.Now()
+
+	r.remember(ctx, key)
+	r.lockouts[key] = lockout
+	return lockout, nil
+}
+
+// Definition Implement Methods (Find, RecordFailure, Lock, Reset)
+func (r *memoryLockoutRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lockout, ok := r.lockouts[key]
+	if !ok {
+		return ErrMsgLockoutNotFound
+	}
+
+	lockout.Failures = 0
+	lockout.Lockouts++
+	lockout.LockedUntil = &until
+
+	r.remember(ctx, key)
+	r.lockouts[key] = lockout
+	return nil
+}
+
+// Definition Implement Methods (Find, RecordFailure, Lock, Reset)
+func (r *memoryLockoutRepository) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remember(ctx, key)
+	delete(r.lockouts, key)
+	return nil
+}
+
+// remember registers an undo step restoring the current state of key when
+// ctx carries a transaction. Callers must hold the lock.
+func (r *memoryLockoutRepository) remember(ctx context.Context, key string) {
+	tx := memoryTxFromContext(ctx)
+	if tx == nil {
+		return
+	}
+
+	previous, existed := r.lockouts[key]
+	tx.onRollback(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if existed {
+			r.lockouts[key] = previous
+		} else {
+			delete(r.lockouts, key)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+)
+
+// Definition Struct (Class)
+// memoryMFARepository keeps the MFA of users and their recovery codes in maps
+// guarded by a RWMutex, mirroring the GORM repository.
+type memoryMFARepository struct {
+	mu     sync.RWMutex
+	clock  clock.Clock
+	nextID uint64
+	mfas   map[uint64]entities.MFA
+	codes  map[uint64][]entities.RecoveryCode
+}
+
+// Definition Constructor
+func NewMemoryMFARepository(clock clock.Clock) MFARepository {
+	return &memoryMFARepository{
+		clock: clock,
+		mfas:  make(map[uint64]entities.MFA),
+		codes: make(map[uint64][]entities.RecoveryCode),
+	}
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+func (r *memoryMFARepository) Find(ctx context.Context, userID uint64) (entities.MFA, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	mfa, ok := r.mfas[userID]
+	if !ok {
+		return entities.MFA{}, ErrMsgMFANotFound
+	}
+
+	return mfa, nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+func (r *memoryMFARepository) Enroll(ctx context.Context, userID uint64, secret string) (entities.MFA, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	mfa := entities.MFA{UserID: userID, Secret: secret, CreatedAt: now, UpdatedAt: now}
+
+	r.remember(ctx, userID)
+	r.mfas[userID] = mfa
+
+	return mfa, nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+func (r *memoryMFARepository) Enable(ctx context.Context, userID uint64, step int64, codeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mfa, ok := r.mfas[userID]
+	if !ok {
+		return ErrMsgMFANotFound
+	}
+	if mfa.Enabled() {
+		return ErrMsgMFAAlreadyEnabled
+	}
+
+	now := r.clock.Now()
+	mfa.EnabledAt = &now
+	mfa.LastUsedStep = step
+	mfa.UpdatedAt = now
+
+	r.remember(ctx, userID)
+	r.mfas[userID] = mfa
+
+	codes := make([]entities.RecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		r.nextID++
+		codes[i] = entities.RecoveryCode{ID: r.nextID, UserID: userID, CodeHash: hash, CreatedAt: now}
+	}
+	r.codes[userID] = codes
+
+	return nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+func (r *memoryMFARepository) UseStep(ctx context.Context, userID uint64, step int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mfa, ok := r.mfas[userID]
+	if !ok || !mfa.Enabled() || mfa.LastUsedStep >= step {
+		return ErrMsgMFAStepUsed
+	}
+
+	mfa.LastUsedStep = step
+	mfa.UpdatedAt = r.clock.Now()
+
+	r.remember(ctx, userID)
+	r.mfas[userID] = mfa
+
+	return nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+func (r *memoryMFARepository) UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.codes[userID], func(code entities.RecoveryCode) bool {
+		return code.CodeHash == codeHash && code.UsedAt == nil
+	})
+	if i < 0 {
+		return ErrMsgRecoveryCodeNotFound
+	}
+
+	r.remember(ctx, userID)
+
+	now := r.clock.Now()
+	codes := slices.Clone(r.codes[userID])
+	codes[i].UsedAt = &now
+	r.codes[userID] = codes
+
+	return nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+func (r *memoryMFARepository) CountRecoveryCodes(ctx context.Context, userID uint64) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, code := range r.codes[userID] {
+		if code.UsedAt == nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+func (r *memoryMFARepository) Delete(ctx context.Context, userID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.mfas[userID]; !ok {
+		return ErrMsgMFANotFound
+	}
+
+	r.remember(ctx, userID)
+	delete(r.mfas, userID)
+	delete(r.codes, userID)
+
+	return nil
+}
+
+// remember records how to undo the next write to the MFA of userID when ctx
+// carries a memory transaction. Callers must hold the lock.
+func (r *memoryMFARepository) remember(ctx context.Context, userID uint64) {
+	tx := memoryTxFromContext(ctx)
+	if tx == nil {
+		return
+	}
+
+	previous, existed := r.mfas[userID]
+	codes, hadCodes := r.codes[userID]
+	tx.onRollback(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if existed {
+			r.mfas[userID] = previous
+		} else {
+			delete(r.mfas, userID)
+		}
+
+		if hadCodes {
+			r.codes[userID] = codes
+		} else {
+			delete(r.codes, userID)
+		}
+	})
+}
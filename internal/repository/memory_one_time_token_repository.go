@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+)
+
+// Definition Struct (Class)
+// memoryOneTimeTokenRepository keeps one-time tokens in a map keyed by their
+// hash, guarded by a RWMutex, mirroring the GORM repository.
+type memoryOneTimeTokenRepository struct {
+	mu     sync.RWMutex
+	clock  clock.Clock
+	nextID uint64
+	tokens map[string]entities.OneTimeToken
+}
+
+// Definition Constructor
+func NewMemoryOneTimeTokenRepository(clock clock.Clock) OneTimeTokenRepository {
+	return &memoryOneTimeTokenRepository{
+		clock:  clock,
+		tokens: make(map[string]entities.OneTimeToken),
+	}
+}
+
+// Definition Implement Methods (Create, Consume, InvalidateAll)
+func (r *memoryOneTimeTokenRepository) Create(ctx context.Context, token entities.OneTimeToken) (entities.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	token.ID = r.nextID
+	token.UsedAt = nil
+	token.CreatedAt = r.clock.Now()
+
+	r.remember(ctx, token.TokenHash)
+	r.tokens[token.TokenHash] = token
+	return token, nil
+}
+
+// Definition Implement Methods (Create, Consume, InvalidateAll)
+func (r *memoryOneTimeTokenRepository) Consume(ctx context.Context, purpose entities.TokenPurpose, tokenHash string) (entities.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[tokenHash]
+	if !ok || token.Purpose != purpose {
+		return entities.OneTimeToken{}, ErrMsgOneTimeTokenNotFound
+	}
+
+	now := r.clock.Now()
+	if !token.Usable(now) {
+		return entities.OneTimeToken{}, ErrMsgOneTimeTokenSpent
+	}
+
+	token.UsedAt = &now
+	r.remember(ctx, tokenHash)
+	r.tokens[tokenHash] = token
+	return token, nil
+}
+
+// Definition Implement Methods (Create, Consume, InvalidateAll)
+func (r *memoryOneTimeTokenRepository) InvalidateAll(ctx context.Context, userID uint64, purpose entities.TokenPurpose) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	var invalidated int64
+	for hash, token := range r.tokens {
+		if token.UserID != userID || token.Purpose != purpose || token.UsedAt != nil {
+			continue
+		}
+
+		token.UsedAt = &now
+		r.remember(ctx, hash)
+		r.tokens[hash] = token
+		invalidated++
+	}
+
+	return invalidated, nil
+}
+
+// remember registers an undo step restoring the current state of the token
+// with tokenHash when ctx carries a transaction. Callers must hold the lock.
+func (r *memoryOneTimeTokenRepository) remember(ctx context.Context, tokenHash string) {
+	tx := memoryTxFromContext(ctx)
+	if tx == nil {
+		return
+	}
+
+	previous, existed := r.tokens[tokenHash]
+	tx.onRollback(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if existed {
+			r.tokens[tokenHash] = previous
+		} else {
+			delete(r.tokens, tokenHash)
+		}
+	})
+}
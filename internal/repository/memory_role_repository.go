@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+)
+
+// seedRoles are the roles the migrations create.
+var seedRoles = []entities.Role{
+	{ID: 1, Name: entities.RoleAdmin, Description: "Manages every user and their roles", Permissions: []entities.Permission{
+		entities.PermAPIKeysManage, entities.PermAuditRead, entities.PermCredentialsVerify, entities.PermMFAManage, entities.PermMFAVerify, entities.PermRolesManage, entities.PermSessionsRead,
+		entities.PermUsersCreate, entities.PermUsersDelete, entities.PermUsersRead, entities.PermUsersUnlock, entities.PermUsersUpdate,
+	}},
+	{ID: 2, Name: entities.RoleSupport, Description: "Reads and updates any user, can not delete", Permissions: []entities.Permission{
+		entities.PermMFAVerify, entities.PermSessionsRead, entities.PermUsersRead, entities.PermUsersUnlock, entities.PermUsersUpdate,
+	}},
+	{ID: 3, Name: entities.RoleUser, Description: "Reads and updates their own record, held by every user", Permissions: []entities.Permission{
+		entities.PermMFAVerify.Self(), entities.PermSessionsRead.Self(), entities.PermUsersRead.Self(), entities.PermUsersUpdate.Self(),
+	}},
+}
+
+// Definition Struct (Class)
+// memoryRoleRepository holds the seeded roles and the role assignments in
+// maps guarded by a RWMutex, mirroring the GORM repository.
+type memoryRoleRepository struct {
+	mu          sync.RWMutex
+	clock       clock.Clock
+	roles       []entities.Role
+	assignments map[uint64]map[uint64]entities.UserRole
+}
+
+// Definition Constructor
+func NewMemoryRoleRepository(clock clock.Clock) RoleRepository {
+	return &memoryRoleRepository{
+		clock:       clock,
+		roles:       seedRoles,
+		assignments: make(map[uint64]map[uint64]entities.UserRole),
+	}
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+func (r *memoryRoleRepository) List(ctx context.Context) ([]entities.Role, error) {
+	return slices.Clone(r.roles), nil
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+func (r *memoryRoleRepository) UserRoles(ctx context.Context, userID uint64) ([]entities.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := []entities.Role{}
+	for _, role := range r.roles {
+		if _, ok := r.assignments[userID][role.ID]; ok {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles, nil
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+func (r *memoryRoleRepository) Permissions(ctx context.Context, userID uint64) ([]entities.Permission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	permissions := []entities.Permission{}
+	for _, role := range r.roles {
+		if _, ok := r.assignments[userID][role.ID]; ok || role.Name == entities.RoleUser {
+			permissions = append(permissions, role.Permissions...)
+		}
+	}
+
+	slices.Sort(permissions)
+	return slices.Compact(permissions), nil
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+func (r *memoryRoleRepository) Assign(ctx context.Context, userID uint64, role string) error {
+	found, err := r.findByName(role)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.assignments[userID][found.ID]; ok {
+		return nil
+	}
+
+	r.remember(ctx, userID, found.ID)
+	if r.assignments[userID] == nil {
+		r.assignments[userID] = make(map[uint64]entities.UserRole)
+	}
+	r.assignments[userID][found.ID] = entities.UserRole{
+		UserID:    userID,
+		RoleID:    found.ID,
+		CreatedAt: r.clock.Now(),
+		CreatedBy: actor.FromContext(ctx),
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+func (r *memoryRoleRepository) Revoke(ctx context.Context, userID uint64, role string) error {
+	found, err := r.findByName(role)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.assignments[userID][found.ID]; !ok {
+		return ErrMsgRoleNotAssigned
+	}
+
+	r.remember(ctx, userID, found.ID)
+	delete(r.assignments[userID], found.ID)
+
+	return nil
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+func (r *memoryRoleRepository) CountHolders(ctx context.Context, role string) (int64, error) {
+	found, err := r.findByName(role)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, roles := range r.assignments {
+		if _, ok := roles[found.ID]; ok {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// findByName returns the role called name. The roles never change, so no
+// lock is needed.
+func (r *memoryRoleRepository) findByName(name string) (entities.Role, error) {
+	for _, role := range r.roles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+
+	return entities.Role{}, fmt.Errorf("%w: %q", ErrMsgRoleNotFound, name)
+}
+
+// remember records how to undo the next write of an assignment when ctx
+// carries a memory transaction. Callers must hold the lock.
+func (r *memoryRoleRepository) remember(ctx context.Context, userID uint64, roleID uint64) {
+	tx := memoryTxFromContext(ctx)
+	if tx == nil {
+		return
+	}
+
+	previous, existed := r.assignments[userID][roleID]
+	tx.onRollback(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if existed {
+			if r.assignments[userID] == nil {
+				r.assignments[userID] = make(map[uint64]entities.UserRole)
+			}
+			r.assignments[userID][roleID] = previous
+		} else {
+			delete(r.assignments[userID], roleID)
+		}
+	})
+}
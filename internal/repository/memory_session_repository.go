@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+)
+
+// Definition Struct (Class)
+// memorySessionRepository keeps sessions and refresh tokens in maps guarded
+// by a RWMutex, mirroring the GORM repository.
+type memorySessionRepository struct {
+	mu          sync.RWMutex
+	clock       clock.Clock
+	nextID      uint64
+	nextTokenID uint64
+	sessions    map[uint64]entities.Session
+	tokens      map[string]entities.RefreshToken
+}
+
+// Definition Constructor
+func NewMemorySessionRepository(clock clock.Clock) SessionRepository {
+	return &memorySessionRepository{
+		clock:    clock,
+		sessions: make(map[uint64]entities.Session),
+		tokens:   make(map[string]entities.RefreshToken),
+	}
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+func (r *memorySessionRepository) Create(ctx context.Context, session entities.Session, tokenHash string) (entities.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	r.nextID++
+	session.ID = r.nextID
+	session.CreatedAt = now
+	session.LastUsedAt = now
+	session.RevokedAt = nil
+	session.RevokeReason = ""
+
+	r.rememberSession(ctx, session.ID)
+	r.sessions[session.ID] = session
+	r.addToken(ctx, session.ID, tokenHash, now)
+
+	return session, nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+func (r *memorySessionRepository) FindByID(ctx context.Context, id uint64) (entities.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return entities.Session{}, ErrMsgSessionNotFound
+	}
+
+	return session, nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+func (r *memorySessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (entities.Session, entities.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return entities.Session{}, entities.RefreshToken{}, ErrMsgSessionNotFound
+	}
+
+	session, ok := r.sessions[token.SessionID]
+	if !ok {
+		return entities.Session{}, entities.RefreshToken{}, ErrMsgSessionNotFound
+	}
+
+	return session, token, nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+func (r *memorySessionRepository) Rotate(ctx context.Context, token entities.RefreshToken, newHash string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tokens[token.TokenHash]
+	if !ok || stored.RotatedAt != nil {
+		return ErrMsgTokenRotated
+	}
+
+	now := r.clock.Now()
+	stored.RotatedAt = &now
+	r.rememberToken(ctx, stored.TokenHash)
+	r.tokens[stored.TokenHash] = stored
+	r.addToken(ctx, stored.SessionID, newHash, now)
+
+	session := r.sessions[stored.SessionID]
+	session.LastUsedAt = now
+	session.ExpiresAt = expiresAt
+	r.rememberSession(ctx, session.ID)
+	r.sessions[session.ID] = session
+
+	return nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+func (r *memorySessionRepository) Revoke(ctx context.Context, id uint64, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return ErrMsgSessionNotFound
+	}
+
+	r.revoke(ctx, session, reason)
+	return nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+func (r *memorySessionRepository) RevokeAll(ctx context.Context, userID uint64, reason string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var revoked int64
+	for _, session := range r.sessions {
+		if session.UserID == userID && r.revoke(ctx, session, reason) {
+			revoked++
+		}
+	}
+
+	return revoked, nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+func (r *memorySessionRepository) ListActive(ctx context.Context, userID uint64) ([]entities.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.clock.Now()
+	sessions := []entities.Session{}
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.Active(now) {
+			sessions = append(sessions, session)
+		}
+	}
+
+	slices.SortFunc(sessions, func(a, b entities.Session) int {
+		if c := b.LastUsedAt.Compare(a.LastUsedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	return sessions, nil
+}
+
+// revoke marks session revoked unless it already is. Callers must hold the
+// lock.
+func (r *memorySessionRepository) revoke(ctx context.Context, session entities.Session, reason string) bool {
+	if session.RevokedAt != nil {
+		return false
+	}
+
+	now := r.clock.Now()
+	session.RevokedAt = &now
+	session.RevokeReason = reason
+
+	r.rememberSession(ctx, session.ID)
+	r.sessions[session.ID] = session
+	return true
+}
+
+// addToken stores a fresh refresh token of sessionID. Callers must hold the
+// lock.
+func (r *memorySessionRepository) addToken(ctx context.Context, sessionID uint64, tokenHash string, now time.Time) {
+	r.nextTokenID++
+	r.rememberToken(ctx, tokenHash)
+	r.tokens[tokenHash] = entities.RefreshToken{ID: r.nextTokenID, SessionID: sessionID, TokenHash: tokenHash, CreatedAt: now}
+}
+
+// rememberSession and rememberToken record how to undo the next write of a
+// row when ctx carries a memory transaction. Callers must hold the lock.
+func (r *memorySessionRepository) rememberSession(ctx context.Context, id uint64) {
+	tx := memoryTxFromContext(ctx)
+	if tx == nil {
+		return
+	}
+
+	previous, existed := r.sessions[id]
+	tx.onRollback(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if existed {
+			r.sessions[id] = previous
+		} else {
+			delete(r.sessions, id)
+		}
+	})
+}
+
+func (r *memorySessionRepository) rememberToken(ctx context.Context, tokenHash string) {
+	tx := memoryTxFromContext(ctx)
+	if tx == nil {
+		return
+	}
+
+	previous, existed := r.tokens[tokenHash]
+	tx.onRollback(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if existed {
+			r.tokens[tokenHash] = previous
+		} else {
+			delete(r.tokens, tokenHash)
+		}
+	})
+}
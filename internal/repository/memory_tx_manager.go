@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// memoryTx collects the undo steps of the writes made inside one transaction.
+type memoryTx struct {
+	mu    sync.Mutex
+	undos []func()
+}
+
+// onRollback registers undo to run if the transaction rolls back.
+func (tx *memoryTx) onRollback(undo func()) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	tx.undos = append(tx.undos, undo)
+}
+
+// rollback runs the undo steps newest first. The steps take the repository
+// locks, so they run without holding tx.mu.
+func (tx *memoryTx) rollback() {
+	tx.mu.Lock()
+	undos := tx.undos
+	tx.undos = nil
+	tx.mu.Unlock()
+
+	for i := len(undos) - 1; i >= 0; i-- {
+		undos[i]()
+	}
+}
+
+// memoryTxFromContext returns the transaction carried by ctx, if any.
+func memoryTxFromContext(ctx context.Context) *memoryTx {
+	tx, _ := ctx.Value(txContextKey{}).(*memoryTx)
+	return tx
+}
+
+// Definition Struct (Class)
+// memoryTxManager runs one transaction at a time. Memory repositories record
+// how to undo each write made with a transactional context, and a rollback
+// replays those steps. Writes made outside any transaction are not isolated
+// from a running one.
+type memoryTxManager struct {
+	mu sync.Mutex
+}
+
+// Definition Constructor
+func NewMemoryTxManager() TxManager {
+	return &memoryTxManager{}
+}
+
+// Definition Implement Methods (WithinTx)
+func (m *memoryTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if memoryTxFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &memoryTx{}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.rollback()
+			panic(p)
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txContextKey{}, tx))
+	if err != nil {
+		tx.rollback()
+		return err
+	}
+
+	if err = ctx.Err(); err != nil {
+		tx.rollback()
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,564 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"gorm.io/gorm"
+)
+
+// Definition Struct (Class)
+// memoryUserRepository keeps users in a map guarded by a RWMutex. It mirrors
+// the GORM repository: auto-increment ids, email unique among active users,
+// soft delete and versioned updates.
+type memoryUserRepository struct {
+	mu     sync.RWMutex
+	clock  clock.Clock
+	nextID uint64
+	users  map[uint64]entities.User
+}
+
+// Definition Constructor
+func NewMemoryUserRepository(clock clock.Clock) UserRepository {
+	return &memoryUserRepository{
+		clock: clock,
+		users: make(map[uint64]entities.User),
+	}
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) Save(ctx context.Context, user entities.User) (entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.emailTaken(user.Email, 0) {
+		return entities.User{}, fmt.Errorf("%w: email %q already exists", ErrMsgDuplicateUser, user.Email)
+	}
+
+	now := r.clock.Now()
+	r.nextID++
+	user.ID = r.nextID
+	user.Version = 1
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.CreatedBy = actor.FromContext(ctx)
+	user.UpdatedBy = user.CreatedBy
+	user.DeletedAt = gorm.DeletedAt{}
+
+	r.remember(ctx, user.ID)
+	r.users[user.ID] = user
+	return user, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) SaveBatch(ctx context.Context, users []entities.User) ([]entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// check the whole batch first so a duplicate leaves nothing behind
+	emails := make(map[string]bool, len(users))
+	for _, user := range users {
+		if emails[user.Email] || r.emailTaken(user.Email, 0) {
+			return nil, fmt.Errorf("%w: email %q already exists", ErrMsgDuplicateUser, user.Email)
+		}
+		emails[user.Email] = true
+	}
+
+	now := r.clock.Now()
+	createdBy := actor.FromContext(ctx)
+
+	saved := make([]entities.User, len(users))
+	for i, user := range users {
+		r.nextID++
+		user.ID = r.nextID
+		user.Version = 1
+		user.CreatedAt = now
+		user.UpdatedAt = now
+		user.CreatedBy = createdBy
+		user.UpdatedBy = createdBy
+		user.DeletedAt = gorm.DeletedAt{}
+
+		r.remember(ctx, user.ID)
+		r.users[user.ID] = user
+		saved[i] = user
+	}
+
+	return saved, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	existing := []string{}
+	for _, email := range emails {
+		if r.emailTaken(email, 0) {
+			existing = append(existing, email)
+		}
+	}
+
+	return existing, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) FindByID(ctx context.Context, id uint64) (entities.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return entities.User{}, ErrMsgUserNotFound
+	}
+
+	return user, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (entities.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if !user.DeletedAt.Valid && user.Email == email {
+			return user, nil
+		}
+	}
+
+	return entities.User{}, ErrMsgUserNotFound
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) Update(ctx context.Context, user entities.User) (entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.ID]
+	if !ok || stored.DeletedAt.Valid {
+		return entities.User{}, ErrMsgUserNotFound
+	}
+
+	if stored.Version != user.Version {
+		return entities.User{}, ErrMsgVersionConflict
+	}
+
+	if r.emailTaken(user.Email, user.ID) {
+		return entities.User{}, fmt.Errorf("%w: email %q already exists", ErrMsgDuplicateUser, user.Email)
+	}
+
+	if stored.Email != user.Email {
+		stored.EmailVerifiedAt = nil
+	}
+	stored.Name = user.Name
+	stored.Family = user.Family
+	stored.Email = user.Email
+	stored.Age = user.Age
+	stored.Version++
+	stored.UpdatedAt = r.clock.Now()
+	stored.UpdatedBy = actor.FromContext(ctx)
+
+	r.remember(ctx, stored.ID)
+	r.users[stored.ID] = stored
+	return stored, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) UpdatePassword(ctx context.Context, id uint64, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return ErrMsgUserNotFound
+	}
+
+	user.PasswordHash = passwordHash
+	r.touch(ctx, user)
+	return nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) MarkEmailVerified(ctx context.Context, id uint64, email string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return ErrMsgUserNotFound
+	}
+
+	if user.Email != email {
+		return ErrMsgEmailChanged
+	}
+
+	user.EmailVerifiedAt = &at
+	r.touch(ctx, user)
+	return nil
+}
+
+// touch stores user with a bumped version, stamping the update. Callers must
+// hold the lock.
+func (r *memoryUserRepository) touch(ctx context.Context, user entities.User) {
+	user.Version++
+	user.UpdatedAt = r.clock.Now()
+	user.UpdatedBy = actor.FromContext(ctx)
+
+	r.remember(ctx, user.ID)
+	r.users[user.ID] = user
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) FindAll(ctx context.Context, query entities.UserListQuery) (entities.UserPage, error) {
+	r.mu.RLock()
+	users := r.matching(query.Filter)
+	r.mu.RUnlock()
+
+	if query.Keyset {
+		return memoryKeysetPage(users, query)
+	}
+
+	sorts := query.Sort
+	if !hasIDSort(sorts) {
+		sorts = append(append([]entities.UserSort{}, sorts...), entities.UserSort{Field: entities.UserSortByID, Direction: entities.SortAsc})
+	}
+	sortUsers(users, sorts)
+
+	page := entities.UserPage{
+		Users:  []entities.User{},
+		Total:  int64(len(users)),
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	if query.Offset < len(users) {
+		end := len(users)
+		if query.Limit > 0 && query.Offset+query.Limit < end {
+			end = query.Offset + query.Limit
+		}
+		page.Users = users[query.Offset:end]
+	}
+
+	return page, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+// Iterate copies one batch at a time under the read lock and calls fn without
+// holding it, so fn may write to the repository.
+func (r *memoryUserRepository) Iterate(ctx context.Context, filter entities.UserFilter, batchSize int, fn func(users []entities.User) error) error {
+	if batchSize <= 0 {
+		batchSize = entities.DefaultUserBatchSize
+	}
+
+	var lastID uint64
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		r.mu.RLock()
+		users := r.matching(filter)
+		r.mu.RUnlock()
+
+		sortUsers(users, []entities.UserSort{{Field: entities.UserSortByID, Direction: entities.SortAsc}})
+
+		batch := make([]entities.User, 0, batchSize)
+		for _, user := range users {
+			if user.ID > lastID {
+				batch = append(batch, user)
+			}
+			if len(batch) == batchSize {
+				break
+			}
+		}
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := fn(batch)
+		if err != nil {
+			return err
+		}
+
+		if len(batch) < batchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) Delete(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return ErrMsgUserNotFound
+	}
+
+	now := r.clock.Now()
+	user.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	user.Version++
+	user.UpdatedAt = now
+	user.UpdatedBy = actor.FromContext(ctx)
+
+	r.remember(ctx, id)
+	r.users[id] = user
+	return nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) DeleteBatch(ctx context.Context, ids []uint64) ([]uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	deleted := []uint64{}
+	for _, id := range ids {
+		user, ok := r.users[id]
+		if !ok || user.DeletedAt.Valid {
+			continue
+		}
+
+		user.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+		user.Version++
+		user.UpdatedAt = now
+		user.UpdatedBy = actor.FromContext(ctx)
+
+		r.remember(ctx, id)
+		r.users[id] = user
+		deleted = append(deleted, id)
+	}
+
+	slices.Sort(deleted)
+	return deleted, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) Restore(ctx context.Context, id uint64) (entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return entities.User{}, ErrMsgUserNotFound
+	}
+
+	if !user.DeletedAt.Valid {
+		return user, nil
+	}
+
+	if r.emailTaken(user.Email, user.ID) {
+		return entities.User{}, fmt.Errorf("%w: email %q already exists", ErrMsgDuplicateUser, user.Email)
+	}
+
+	user.DeletedAt = gorm.DeletedAt{}
+	user.Version++
+	user.UpdatedAt = r.clock.Now()
+	user.UpdatedBy = actor.FromContext(ctx)
+
+	r.remember(ctx, id)
+	r.users[id] = user
+	return user, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *memoryUserRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, user := range r.users {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(deletedBefore) {
+			r.remember(ctx, id)
+			delete(r.users, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// remember registers an undo step restoring the current state of id when ctx
+// carries a transaction. Callers must hold the lock and call it before writing.
+func (r *memoryUserRepository) remember(ctx context.Context, id uint64) {
+	tx := memoryTxFromContext(ctx)
+	if tx == nil {
+		return
+	}
+
+	previous, existed := r.users[id]
+	tx.onRollback(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if existed {
+			r.users[id] = previous
+		} else {
+			delete(r.users, id)
+		}
+	})
+}
+
+// emailTaken reports whether an active user other than exceptID uses email.
+// Callers must hold the lock.
+func (r *memoryUserRepository) emailTaken(email string, exceptID uint64) bool {
+	for _, user := range r.users {
+		if user.ID != exceptID && !user.DeletedAt.Valid && user.Email == email {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matching returns copies of the users passing filter. Callers must hold the lock.
+func (r *memoryUserRepository) matching(filter entities.UserFilter) []entities.User {
+	users := make([]entities.User, 0, len(r.users))
+	for _, user := range r.users {
+		if user.DeletedAt.Valid && !filter.IncludeDeleted {
+			continue
+		}
+		if !containsFold(user.Name, filter.Name) || !containsFold(user.Family, filter.Family) || !containsFold(user.Email, filter.Email) {
+			continue
+		}
+		if filter.MinAge != nil && user.Age < *filter.MinAge {
+			continue
+		}
+		if filter.MaxAge != nil && user.Age > *filter.MaxAge {
+			continue
+		}
+
+		users = append(users, user)
+	}
+
+	return users
+}
+
+// memoryKeysetPage mirrors findAllKeyset on an already filtered slice.
+func memoryKeysetPage(users []entities.User, query entities.UserListQuery) (entities.UserPage, error) {
+	sort := query.KeysetSort()
+	sorts := []entities.UserSort{sort}
+	if sort.Field != entities.UserSortByID {
+		sorts = append(sorts, entities.UserSort{Field: entities.UserSortByID, Direction: sort.Direction})
+	}
+	sortUsers(users, sorts)
+
+	if query.After != nil {
+		if !query.After.Field.IsValid() {
+			return entities.UserPage{}, fmt.Errorf("%w: unknown cursor field %q", ErrMsgInvalidCursor, query.After.Field)
+		}
+
+		start := len(users)
+		for i, user := range users {
+			after, err := isAfterCursor(user, *query.After)
+			if err != nil {
+				return entities.UserPage{}, err
+			}
+			if after {
+				start = i
+				break
+			}
+		}
+		users = users[start:]
+	}
+
+	page := entities.UserPage{Limit: query.Limit}
+	if len(users) > query.Limit {
+		users = users[:query.Limit]
+		page.Next = entities.CursorAfter(users[len(users)-1], sort)
+	}
+	page.Users = users
+
+	return page, nil
+}
+
+// isAfterCursor reports whether user comes strictly after the cursor position.
+func isAfterCursor(user entities.User, after entities.UserCursor) (bool, error) {
+	var order int
+	switch after.Field {
+	case entities.UserSortByAge:
+		age, err := strconv.Atoi(after.Value)
+		if err != nil {
+			return false, fmt.Errorf("%w:%w", ErrMsgInvalidCursor, err)
+		}
+		order = cmp.Compare(user.Age, age)
+	case entities.UserSortByID:
+		order = 0
+	default:
+		order = strings.Compare(userSortValue(user, after.Field), after.Value)
+	}
+
+	if order == 0 {
+		order = cmp.Compare(user.ID, after.ID)
+	}
+
+	if after.Direction == entities.SortDesc {
+		return order < 0, nil
+	}
+
+	return order > 0, nil
+}
+
+func sortUsers(users []entities.User, sorts []entities.UserSort) {
+	sort.SliceStable(users, func(i, j int) bool {
+		for _, s := range sorts {
+			var order int
+			switch s.Field {
+			case entities.UserSortByID:
+				order = cmp.Compare(users[i].ID, users[j].ID)
+			case entities.UserSortByAge:
+				order = cmp.Compare(users[i].Age, users[j].Age)
+			default:
+				order = strings.Compare(userSortValue(users[i], s.Field), userSortValue(users[j], s.Field))
+			}
+
+			if order == 0 {
+				continue
+			}
+			if s.Direction == entities.SortDesc {
+				return order > 0
+			}
+			return order < 0
+		}
+
+		return false
+	})
+}
+
+func userSortValue(user entities.User, field entities.UserSortField) string {
+	switch field {
+	case entities.UserSortByName:
+		return user.Name
+	case entities.UserSortByFamily:
+		return user.Family
+	case entities.UserSortByEmail:
+		return user.Email
+	default:
+		return ""
+	}
+}
+
+func hasIDSort(sorts []entities.UserSort) bool {
+	for _, s := range sorts {
+		if s.Field == entities.UserSortByID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFold(value string, substr string) bool {
+	return substr == "" || strings.Contains(strings.ToLower(value), strings.ToLower(substr))
+}
@@ -0,0 +1,90 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/alirezaghasemi/user-manager/internal/repository/repositorytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryUserRepository_ConcurrentSave(t *testing.T) {
+	repo := repository.NewMemoryUserRepository(clock.New())
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	ids := make(chan uint64, 100)
+	duplicates := make(chan error, 100)
+
+	// 50 distinct emails, each saved twice concurrently
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			user, err := repo.Save(ctx, entities.User{
+				Name:   "Test User",
+				Family: "Test Family",
+				Email:  fmt.Sprintf("test%d@gmail.com", i%50),
+				Age:    30,
+			})
+			if err != nil {
+				duplicates <- err
+				return
+			}
+			ids <- user.ID
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+	close(duplicates)
+
+	seen := map[uint64]bool{}
+	for id := range ids {
+		assert.False(t, seen[id], "id %d handed out twice", id)
+		seen[id] = true
+	}
+	assert.Len(t, seen, 50)
+
+	for err := range duplicates {
+		assert.True(t, errors.Is(err, repository.ErrMsgDuplicateUser))
+	}
+}
+
+func TestMemoryUserRepository_Timestamps(t *testing.T) {
+	created := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	repo := repository.NewMemoryUserRepository(clock.Fixed(created))
+	ctx := context.Background()
+
+	user, err := repo.Save(ctx, entities.User{Name: "Test User", Family: "Test Family", Email: "test@gmail.com", Age: 30})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(1), user.ID)
+	assert.Equal(t, 1, user.Version)
+	assert.Equal(t, created, user.CreatedAt)
+	assert.Equal(t, created, user.UpdatedAt)
+}
+
+func TestMemoryRepositories_Contract(t *testing.T) {
+	repositorytest.RunContracts(t, func(t *testing.T, clock clock.Clock) repositorytest.Repositories {
+		return repositorytest.Repositories{
+			Users:         repository.NewMemoryUserRepository(clock),
+			Sessions:      repository.NewMemorySessionRepository(clock),
+			Roles:         repository.NewMemoryRoleRepository(clock),
+			APIKeys:       repository.NewMemoryAPIKeyRepository(clock),
+			MFA:           repository.NewMemoryMFARepository(clock),
+			OneTimeTokens: repository.NewMemoryOneTimeTokenRepository(clock),
+			Lockouts:      repository.NewMemoryLockoutRepository(clock),
+			Audit:         repository.NewMemoryAuditRepository(clock),
+			TxManager:     repository.NewMemoryTxManager(),
+		}
+	})
+}
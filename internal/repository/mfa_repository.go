@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Definition Error Message
+var (
+	ErrMsgMFANotFound          = errors.New("mfa not enrolled")
+	ErrMsgMFAAlreadyEnabled    = errors.New("mfa is already enabled")
+	ErrMsgMFAStepUsed          = errors.New("mfa code of this time step was already used")
+	ErrMsgRecoveryCodeNotFound = errors.New("recovery code not found or already used")
+)
+
+// Definition Interface (Rules)
+type MFARepository interface {
+	Find(ctx context.Context, userID uint64) (entities.MFA, error)
+	Enroll(ctx context.Context, userID uint64, secret string) (entities.MFA, error)
+	Enable(ctx context.Context, userID uint64, step int64, codeHashes []string) error
+	UseStep(ctx context.Context, userID uint64, step int64) error
+	UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) error
+	CountRecoveryCodes(ctx context.Context, userID uint64) (int64, error)
+	Delete(ctx context.Context, userID uint64) error
+}
+
+// Definition Struct (Class)
+type mfaRepository struct {
+	db    *gorm.DB
+	clock clock.Clock
+}
+
+// Definition Constructor
+func NewMFARepository(db *gorm.DB, clock clock.Clock) MFARepository {
+	return &mfaRepository{db: db, clock: clock}
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+func (r *mfaRepository) Find(ctx context.Context, userID uint64) (entities.MFA, error) {
+	var mfa entities.MFA
+	err := connection(ctx, r.db).Where("user_id = ?", userID).First(&mfa).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.MFA{}, fmt.Errorf("%w:%w", ErrMsgMFANotFound, err)
+		}
+
+		return entities.MFA{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return mfa, nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+// Enroll stores the sealed secret of a pending enrollment, replacing any
+// earlier one. It must not be called for an enabled MFA.
+func (r *mfaRepository) Enroll(ctx context.Context, userID uint64, secret string) (entities.MFA, error) {
+	now := r.clock.Now()
+	mfa := entities.MFA{UserID: userID, Secret: secret, CreatedAt: now, UpdatedAt: now}
+
+	err := connection(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"secret":         secret,
+			"enabled_at":     nil,
+			"last_used_step": 0,
+			"created_at":     now,
+			"updated_at":     now,
+		}),
+	}).Create(&mfa).Error
+	if err != nil {
+		return entities.MFA{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return mfa, nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+// Enable turns on a pending enrollment whose first code was of step, and
+// replaces the recovery codes of the user with codeHashes.
+func (r *mfaRepository) Enable(ctx context.Context, userID uint64, step int64, codeHashes []string) error {
+	now := r.clock.Now()
+
+	err := connection(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.MFA{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{"enabled_at": now, "last_used_step": step, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			var count int64
+			err := tx.Model(&entities.MFA{}).Where("user_id = ?", userID).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				return ErrMsgMFANotFound
+			}
+
+			return ErrMsgMFAAlreadyEnabled
+		}
+
+		return replaceRecoveryCodes(tx, userID, codeHashes, now)
+	})
+	if err != nil {
+		if errors.Is(err, ErrMsgMFANotFound) || errors.Is(err, ErrMsgMFAAlreadyEnabled) {
+			return err
+		}
+
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+// UseStep records that a code of step was accepted. The conditional update
+// only lets steps after the last accepted one through, so a code is never
+// accepted twice, not even by two concurrent requests.
+func (r *mfaRepository) UseStep(ctx context.Context, userID uint64, step int64) error {
+	result := connection(ctx, r.db).Model(&entities.MFA{}).
+		Where("user_id = ? AND enabled_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Updates(map[string]interface{}{"last_used_step": step, "updated_at": r.clock.Now()})
+	if result.Error != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrMsgMFAStepUsed
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+// UseRecoveryCode spends the unused recovery code with codeHash.
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) error {
+	result := connection(ctx, r.db).Model(&entities.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", r.clock.Now())
+	if result.Error != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrMsgRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+// CountRecoveryCodes returns how many recovery codes of userID are unused.
+func (r *mfaRepository) CountRecoveryCodes(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	err := connection(ctx, r.db).Model(&entities.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return count, nil
+}
+
+// Definition Implement Methods (Find, Enroll, Enable, UseStep, UseRecoveryCode, CountRecoveryCodes, Delete)
+// Delete removes the MFA of userID, enabled or pending, with its recovery
+// codes.
+func (r *mfaRepository) Delete(ctx context.Context, userID uint64) error {
+	err := connection(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("user_id = ?", userID).Delete(&entities.MFA{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrMsgMFANotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrMsgMFANotFound) {
+			return err
+		}
+
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
+
+// replaceRecoveryCodes swaps the recovery codes of userID for codeHashes.
+func replaceRecoveryCodes(tx *gorm.DB, userID uint64, codeHashes []string, now time.Time) error {
+	err := tx.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error
+	if err != nil {
+		return err
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	codes := make([]entities.RecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = entities.RecoveryCode{UserID: userID, CodeHash: hash, CreatedAt: now}
+	}
+
+	return tx.Create(&codes).Error
+}
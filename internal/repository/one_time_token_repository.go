@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"gorm.io/gorm"
+)
+
+// Definition Error Message
+var (
+	ErrMsgOneTimeTokenNotFound = errors.New("one-time token not found")
+	ErrMsgOneTimeTokenSpent    = errors.New("one-time token is used or expired")
+)
+
+// Definition Interface (Rules)
+type OneTimeTokenRepository interface {
+	Create(ctx context.Context, token entities.OneTimeToken) (entities.OneTimeToken, error)
+	Consume(ctx context.Context, purpose entities.TokenPurpose, tokenHash string) (entities.OneTimeToken, error)
+	InvalidateAll(ctx context.Context, userID uint64, purpose entities.TokenPurpose) (int64, error)
+}
+
+// Definition Struct (Class)
+type oneTimeTokenRepository struct {
+	db    *gorm.DB
+	clock clock.Clock
+}
+
+// Definition Constructor
+func NewOneTimeTokenRepository(db *gorm.DB, clock clock.Clock) OneTimeTokenRepository {
+	return &oneTimeTokenRepository{db: db, clock: clock}
+}
+
+// Definition Implement Methods (Create, Consume, InvalidateAll)
+func (r *oneTimeTokenRepository) Create(ctx context.Context, token entities.OneTimeToken) (entities.OneTimeToken, error) {
+	token.ID = 0
+	token.UsedAt = nil
+	token.CreatedAt = r.clock.Now()
+
+	err := connection(ctx, r.db).Create(&token).Error
+	if err != nil {
+		return entities.OneTimeToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return token, nil
+}
+
+// Definition Implement Methods (Create, Consume, InvalidateAll)
+// Consume marks the token with tokenHash used and returns it. A token of
+// another purpose is as good as missing. The conditional update lets only
+// one of two concurrent attempts win, the other gets ErrMsgOneTimeTokenSpent
+// like a used or expired token does.
+func (r *oneTimeTokenRepository) Consume(ctx context.Context, purpose entities.TokenPurpose, tokenHash string) (entities.OneTimeToken, error) {
+	var token entities.OneTimeToken
+	err := connection(ctx, r.db).Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.OneTimeToken{}, fmt.Errorf("%w:%w", ErrMsgOneTimeTokenNotFound, err)
+		}
+
+		return entities.OneTimeToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	now := r.clock.Now()
+	result := connection(ctx, r.db).Model(&entities.OneTimeToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return entities.OneTimeToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entities.OneTimeToken{}, ErrMsgOneTimeTokenSpent
+	}
+
+	token.UsedAt = &now
+	return token, nil
+}
+
+// Definition Implement Methods (Create, Consume, InvalidateAll)
+// InvalidateAll marks every unused token of userID for purpose used and
+// returns how many, so only a link sent afterwards still works.
+func (r *oneTimeTokenRepository) InvalidateAll(ctx context.Context, userID uint64, purpose entities.TokenPurpose) (int64, error) {
+	result := connection(ctx, r.db).Model(&entities.OneTimeToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", r.clock.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("%w:%w", ErrMsgInternalServerError, result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,92 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunAPIKeyRepositoryContract checks an APIKeyRepository implementation.
+func RunAPIKeyRepositoryContract(t *testing.T, newRepositories Factory) {
+	t.Helper()
+
+	ctx := actor.WithActor(context.Background(), "cli")
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(24 * time.Hour)
+
+	newKey := func(prefix string) entities.APIKey {
+		return entities.APIKey{
+			ServiceAccount: "provisioning",
+			Prefix:         prefix,
+			KeyHash:        "hash-" + prefix,
+			Scopes:         entities.Scopes{entities.ScopeUsersRead, entities.ScopeUsersWrite},
+			ExpiresAt:      &expiresAt,
+		}
+	}
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repo := newRepositories(t, clock.Fixed(now)).APIKeys
+
+		created, err := repo.Create(ctx, newKey("umk_000000000001"))
+		require.NoError(t, err)
+		assert.NotZero(t, created.ID)
+		assert.Equal(t, "cli", created.CreatedBy)
+
+		found, err := repo.FindByPrefix(ctx, "umk_000000000001")
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, found.ID)
+		assert.Equal(t, "provisioning", found.ServiceAccount)
+		assert.Equal(t, "hash-umk_000000000001", found.KeyHash)
+		assert.Equal(t, entities.Scopes{entities.ScopeUsersRead, entities.ScopeUsersWrite}, found.Scopes)
+		require.NotNil(t, found.ExpiresAt)
+		assert.True(t, expiresAt.Equal(*found.ExpiresAt))
+		assert.Nil(t, found.LastUsedAt)
+		assert.Nil(t, found.RevokedAt)
+
+		_, err = repo.FindByPrefix(ctx, "umk_unknown")
+		assert.True(t, errors.Is(err, repository.ErrMsgAPIKeyNotFound), "got %v", err)
+	})
+
+	t.Run("DuplicatePrefix", func(t *testing.T) {
+		repo := newRepositories(t, clock.Fixed(now)).APIKeys
+
+		_, err := repo.Create(ctx, newKey("umk_000000000001"))
+		require.NoError(t, err)
+
+		_, err = repo.Create(ctx, newKey("umk_000000000001"))
+		assert.Error(t, err)
+	})
+
+	t.Run("ListTouchAndRevoke", func(t *testing.T) {
+		repo := newRepositories(t, clock.Fixed(now)).APIKeys
+
+		first, err := repo.Create(ctx, newKey("umk_000000000001"))
+		require.NoError(t, err)
+		second, err := repo.Create(ctx, newKey("umk_000000000002"))
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Touch(ctx, first.ID, now.Add(time.Minute)))
+		require.NoError(t, repo.Revoke(ctx, second.ID))
+		require.NoError(t, repo.Revoke(ctx, second.ID), "revoking twice changes nothing")
+
+		keys, err := repo.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, first.ID, keys[0].ID)
+		require.NotNil(t, keys[0].LastUsedAt)
+		assert.True(t, now.Add(time.Minute).Equal(*keys[0].LastUsedAt))
+		assert.Nil(t, keys[0].RevokedAt)
+		assert.NotNil(t, keys[1].RevokedAt)
+
+		err = repo.Revoke(ctx, second.ID+100)
+		assert.True(t, errors.Is(err, repository.ErrMsgAPIKeyNotFound), "got %v", err)
+	})
+}
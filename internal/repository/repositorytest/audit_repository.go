@@ -0,0 +1,124 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunAuditRepositoryContract checks an AuditRepository implementation and
+// that it joins the transactions of the TxManager.
+func RunAuditRepositoryContract(t *testing.T, newRepositories Factory) {
+	t.Helper()
+
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	ctx := requestid.WithRequestID(actor.WithActor(context.Background(), "admin"), "req-1")
+
+	t.Run("Record", func(t *testing.T) {
+		repo := newRepositories(t, &contractClock{now: start}).Audit
+
+		recorded, err := repo.Record(ctx, entities.AuditEvent{
+			Action:  entities.AuditUserUpdated,
+			UserID:  7,
+			Changes: []entities.AuditChange{{Field: "name", Before: "Ali", After: "Reza"}},
+		})
+		require.NoError(t, err)
+		assert.NotZero(t, recorded.ID)
+		assert.Equal(t, "admin", recorded.Actor)
+		assert.Equal(t, "req-1", recorded.RequestID)
+		assert.True(t, start.Equal(recorded.CreatedAt))
+
+		page, err := repo.List(ctx, entities.AuditQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 1)
+		event := page.Events[0]
+		assert.Equal(t, recorded.ID, event.ID)
+		assert.Equal(t, entities.AuditUserUpdated, event.Action)
+		assert.Equal(t, uint64(7), event.UserID)
+		assert.Equal(t, []entities.AuditChange{{Field: "name", Before: "Ali", After: "Reza"}}, event.Changes)
+		assert.Zero(t, page.NextBeforeID)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		clk := &contractClock{now: start}
+		repo := newRepositories(t, clk).Audit
+
+		record := func(who string, action entities.AuditAction, userID uint64) entities.AuditEvent {
+			clk.now = clk.now.Add(time.Minute)
+			event, err := repo.Record(actor.WithActor(ctx, who), entities.AuditEvent{Action: action, UserID: userID})
+			require.NoError(t, err)
+			return event
+		}
+		created := record("admin", entities.AuditUserCreated, 1)
+		updated := record("support", entities.AuditUserUpdated, 1)
+		other := record("admin", entities.AuditUserCreated, 2)
+		deleted := record("admin", entities.AuditUserDeleted, 1)
+
+		ids := func(query entities.AuditQuery) []uint64 {
+			page, err := repo.List(ctx, query)
+			require.NoError(t, err)
+			result := []uint64{}
+			for _, event := range page.Events {
+				result = append(result, event.ID)
+			}
+			return result
+		}
+
+		userID := uint64(1)
+		assert.Equal(t, []uint64{deleted.ID, updated.ID, created.ID}, ids(entities.AuditQuery{UserID: &userID}), "newest first")
+		assert.Equal(t, []uint64{updated.ID}, ids(entities.AuditQuery{Actor: "support"}))
+		assert.Equal(t, []uint64{other.ID, created.ID}, ids(entities.AuditQuery{Action: entities.AuditUserCreated}))
+
+		from, to := updated.CreatedAt, deleted.CreatedAt
+		assert.Equal(t, []uint64{other.ID, updated.ID}, ids(entities.AuditQuery{From: &from, To: &to}), "from is inclusive, to exclusive")
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		repo := newRepositories(t, &contractClock{now: start}).Audit
+
+		for i := uint64(1); i <= 5; i++ {
+			_, err := repo.Record(ctx, entities.AuditEvent{Action: entities.AuditUserCreated, UserID: i})
+			require.NoError(t, err)
+		}
+
+		page, err := repo.List(ctx, entities.AuditQuery{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 2)
+		assert.Equal(t, uint64(5), page.Events[0].UserID)
+		assert.Equal(t, page.Events[1].ID, page.NextBeforeID)
+
+		page, err = repo.List(ctx, entities.AuditQuery{Limit: 2, BeforeID: page.NextBeforeID})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 2)
+		assert.Equal(t, uint64(3), page.Events[0].UserID)
+
+		page, err = repo.List(ctx, entities.AuditQuery{Limit: 2, BeforeID: page.NextBeforeID})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 1)
+		assert.Zero(t, page.NextBeforeID, "the last page has no next one")
+	})
+
+	t.Run("RolledBack", func(t *testing.T) {
+		repos := newRepositories(t, &contractClock{now: start})
+		repo, txManager := repos.Audit, repos.TxManager
+		errAbort := errors.New("abort")
+
+		err := txManager.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := repo.Record(ctx, entities.AuditEvent{Action: entities.AuditUserDeleted, UserID: 1})
+			require.NoError(t, err)
+			return errAbort
+		})
+		require.True(t, errors.Is(err, errAbort))
+
+		page, err := repo.List(ctx, entities.AuditQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, page.Events, "an event is only kept with the change it records")
+	})
+}
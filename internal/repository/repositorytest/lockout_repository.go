@@ -0,0 +1,76 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunLockoutRepositoryContract checks a LockoutRepository implementation.
+func RunLockoutRepositoryContract(t *testing.T, newRepositories Factory) {
+	t.Helper()
+
+	ctx := context.Background()
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	t.Run("RecordFailure", func(t *testing.T) {
+		clk := &contractClock{now: start}
+		repo := newRepositories(t, clk).Lockouts
+
+		_, err := repo.Find(ctx, "user:1")
+		assert.True(t, errors.Is(err, repository.ErrMsgLockoutNotFound), "got %v", err)
+
+		for i := 1; i <= 3; i++ {
+			clk.now = clk.now.Add(time.Minute)
+			lockout, err := repo.RecordFailure(ctx, "user:1", clk.now.Add(-15*time.Minute))
+			require.NoError(t, err)
+			assert.Equal(t, i, lockout.Failures)
+			assert.True(t, clk.now.Equal(lockout.LastFailureAt))
+		}
+
+		lockout, err := repo.RecordFailure(ctx, "ip:10.0.0.1", clk.now.Add(-15*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 1, lockout.Failures, "keys are counted apart")
+
+		clk.now = clk.now.Add(time.Hour)
+		lockout, err = repo.RecordFailure(ctx, "user:1", clk.now.Add(-15*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 1, lockout.Failures, "failures before the window are forgotten")
+	})
+
+	t.Run("LockAndReset", func(t *testing.T) {
+		clk := &contractClock{now: start}
+		repo := newRepositories(t, clk).Lockouts
+		until := start.Add(time.Minute)
+
+		err := repo.Lock(ctx, "user:1", until)
+		assert.True(t, errors.Is(err, repository.ErrMsgLockoutNotFound), "got %v", err)
+
+		_, err = repo.RecordFailure(ctx, "user:1", start.Add(-time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, repo.Lock(ctx, "user:1", until))
+		_, err = repo.RecordFailure(ctx, "user:1", start.Add(-time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, repo.Lock(ctx, "user:1", until.Add(time.Minute)))
+
+		lockout, err := repo.Find(ctx, "user:1")
+		require.NoError(t, err)
+		assert.Zero(t, lockout.Failures)
+		assert.Equal(t, 2, lockout.Lockouts)
+		require.NotNil(t, lockout.LockedUntil)
+		assert.True(t, until.Add(time.Minute).Equal(*lockout.LockedUntil))
+		assert.True(t, lockout.Locked(start))
+		assert.False(t, lockout.Locked(until.Add(time.Minute)))
+
+		require.NoError(t, repo.Reset(ctx, "user:1"))
+		require.NoError(t, repo.Reset(ctx, "user:1"), "resetting again is a no-op")
+
+		_, err = repo.Find(ctx, "user:1")
+		assert.True(t, errors.Is(err, repository.ErrMsgLockoutNotFound), "got %v", err)
+	})
+}
@@ -0,0 +1,150 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunMFARepositoryContract checks an MFARepository implementation.
+func RunMFARepositoryContract(t *testing.T, newRepositories Factory) {
+	t.Helper()
+
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) (repository.MFARepository, repository.TxManager, uint64) {
+		t.Helper()
+
+		repos := newRepositories(t, clock.Fixed(now))
+		repo, users, txManager := repos.MFA, repos.Users, repos.TxManager
+
+		user, err := users.Save(ctx, entities.User{Name: "Owner", Family: "Owner", Email: "owner@gmail.com", Age: 30})
+		require.NoError(t, err)
+
+		return repo, txManager, user.ID
+	}
+
+	t.Run("EnrollAndEnable", func(t *testing.T) {
+		repo, _, userID := setup(t)
+
+		_, err := repo.Find(ctx, userID)
+		assert.True(t, errors.Is(err, repository.ErrMsgMFANotFound), "got %v", err)
+
+		_, err = repo.Enroll(ctx, userID, "sealed-1")
+		require.NoError(t, err)
+		_, err = repo.Enroll(ctx, userID, "sealed-2")
+		require.NoError(t, err, "enrolling again replaces the pending secret")
+
+		mfa, err := repo.Find(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "sealed-2", mfa.Secret)
+		assert.False(t, mfa.Enabled())
+
+		require.NoError(t, repo.Enable(ctx, userID, 100, []string{"hash-1", "hash-2"}))
+
+		mfa, err = repo.Find(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, mfa.Enabled())
+		assert.Equal(t, int64(100), mfa.LastUsedStep)
+
+		err = repo.Enable(ctx, userID, 101, nil)
+		assert.True(t, errors.Is(err, repository.ErrMsgMFAAlreadyEnabled), "got %v", err)
+
+		err = repo.Enable(ctx, userID+1, 101, nil)
+		assert.True(t, errors.Is(err, repository.ErrMsgMFANotFound), "got %v", err)
+
+		count, err := repo.CountRecoveryCodes(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("UseStep", func(t *testing.T) {
+		repo, _, userID := setup(t)
+
+		_, err := repo.Enroll(ctx, userID, "sealed")
+		require.NoError(t, err)
+
+		err = repo.UseStep(ctx, userID, 100)
+		assert.True(t, errors.Is(err, repository.ErrMsgMFAStepUsed), "pending enrollments accept no codes, got %v", err)
+
+		require.NoError(t, repo.Enable(ctx, userID, 100, nil))
+
+		err = repo.UseStep(ctx, userID, 100)
+		assert.True(t, errors.Is(err, repository.ErrMsgMFAStepUsed), "the activation step is used, got %v", err)
+
+		require.NoError(t, repo.UseStep(ctx, userID, 102))
+
+		err = repo.UseStep(ctx, userID, 101)
+		assert.True(t, errors.Is(err, repository.ErrMsgMFAStepUsed), "older steps are refused, got %v", err)
+	})
+
+	t.Run("UseRecoveryCode", func(t *testing.T) {
+		repo, _, userID := setup(t)
+
+		_, err := repo.Enroll(ctx, userID, "sealed")
+		require.NoError(t, err)
+		require.NoError(t, repo.Enable(ctx, userID, 100, []string{"hash-1", "hash-2"}))
+
+		require.NoError(t, repo.UseRecoveryCode(ctx, userID, "hash-1"))
+
+		err = repo.UseRecoveryCode(ctx, userID, "hash-1")
+		assert.True(t, errors.Is(err, repository.ErrMsgRecoveryCodeNotFound), "got %v", err)
+
+		err = repo.UseRecoveryCode(ctx, userID, "hash-3")
+		assert.True(t, errors.Is(err, repository.ErrMsgRecoveryCodeNotFound), "got %v", err)
+
+		count, err := repo.CountRecoveryCodes(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo, _, userID := setup(t)
+
+		_, err := repo.Enroll(ctx, userID, "sealed")
+		require.NoError(t, err)
+		require.NoError(t, repo.Enable(ctx, userID, 100, []string{"hash-1"}))
+
+		require.NoError(t, repo.Delete(ctx, userID))
+
+		_, err = repo.Find(ctx, userID)
+		assert.True(t, errors.Is(err, repository.ErrMsgMFANotFound), "got %v", err)
+
+		count, err := repo.CountRecoveryCodes(ctx, userID)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		err = repo.Delete(ctx, userID)
+		assert.True(t, errors.Is(err, repository.ErrMsgMFANotFound), "got %v", err)
+	})
+
+	t.Run("RollbackUndoesEnable", func(t *testing.T) {
+		repo, txManager, userID := setup(t)
+		failure := errors.New("failure")
+
+		_, err := repo.Enroll(ctx, userID, "sealed")
+		require.NoError(t, err)
+
+		err = txManager.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, repo.Enable(ctx, userID, 100, []string{"hash-1"}))
+			return failure
+		})
+		require.ErrorIs(t, err, failure)
+
+		mfa, err := repo.Find(ctx, userID)
+		require.NoError(t, err)
+		assert.False(t, mfa.Enabled())
+
+		count, err := repo.CountRecoveryCodes(ctx, userID)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
@@ -0,0 +1,111 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunOneTimeTokenRepositoryContract checks a OneTimeTokenRepository
+// implementation.
+func RunOneTimeTokenRepositoryContract(t *testing.T, newRepositories Factory) {
+	t.Helper()
+
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T, clock clock.Clock) (repository.OneTimeTokenRepository, repository.TxManager, uint64) {
+		t.Helper()
+
+		repos := newRepositories(t, clock)
+		repo, users, txManager := repos.OneTimeTokens, repos.Users, repos.TxManager
+
+		user, err := users.Save(ctx, entities.User{Name: "Owner", Family: "Owner", Email: "owner@gmail.com", Age: 30})
+		require.NoError(t, err)
+
+		return repo, txManager, user.ID
+	}
+
+	newToken := func(userID uint64, purpose entities.TokenPurpose, hash string) entities.OneTimeToken {
+		return entities.OneTimeToken{UserID: userID, Email: "owner@gmail.com", Purpose: purpose, TokenHash: hash, ExpiresAt: now.Add(time.Hour)}
+	}
+
+	t.Run("Consume", func(t *testing.T) {
+		repo, _, userID := setup(t, clock.Fixed(now))
+
+		created, err := repo.Create(ctx, newToken(userID, entities.TokenPurposePasswordReset, "hash-1"))
+		require.NoError(t, err)
+		assert.NotZero(t, created.ID)
+
+		_, err = repo.Consume(ctx, entities.TokenPurposeEmailVerification, "hash-1")
+		assert.True(t, errors.Is(err, repository.ErrMsgOneTimeTokenNotFound), "a token of another purpose is refused, got %v", err)
+
+		consumed, err := repo.Consume(ctx, entities.TokenPurposePasswordReset, "hash-1")
+		require.NoError(t, err)
+		assert.Equal(t, userID, consumed.UserID)
+		assert.Equal(t, "owner@gmail.com", consumed.Email)
+		assert.NotNil(t, consumed.UsedAt)
+
+		_, err = repo.Consume(ctx, entities.TokenPurposePasswordReset, "hash-1")
+		assert.True(t, errors.Is(err, repository.ErrMsgOneTimeTokenSpent), "got %v", err)
+
+		_, err = repo.Consume(ctx, entities.TokenPurposePasswordReset, "hash-2")
+		assert.True(t, errors.Is(err, repository.ErrMsgOneTimeTokenNotFound), "got %v", err)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		repo, _, userID := setup(t, clock.Fixed(now.Add(time.Hour)))
+
+		_, err := repo.Create(ctx, newToken(userID, entities.TokenPurposePasswordReset, "hash-1"))
+		require.NoError(t, err)
+
+		_, err = repo.Consume(ctx, entities.TokenPurposePasswordReset, "hash-1")
+		assert.True(t, errors.Is(err, repository.ErrMsgOneTimeTokenSpent), "got %v", err)
+	})
+
+	t.Run("InvalidateAll", func(t *testing.T) {
+		repo, _, userID := setup(t, clock.Fixed(now))
+
+		for _, hash := range []string{"hash-1", "hash-2"} {
+			_, err := repo.Create(ctx, newToken(userID, entities.TokenPurposePasswordReset, hash))
+			require.NoError(t, err)
+		}
+		_, err := repo.Create(ctx, newToken(userID, entities.TokenPurposeEmailVerification, "hash-3"))
+		require.NoError(t, err)
+
+		invalidated, err := repo.InvalidateAll(ctx, userID, entities.TokenPurposePasswordReset)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), invalidated)
+
+		_, err = repo.Consume(ctx, entities.TokenPurposePasswordReset, "hash-2")
+		assert.True(t, errors.Is(err, repository.ErrMsgOneTimeTokenSpent), "got %v", err)
+
+		_, err = repo.Consume(ctx, entities.TokenPurposeEmailVerification, "hash-3")
+		assert.NoError(t, err, "tokens of other purposes are kept")
+	})
+
+	t.Run("RollbackUndoesConsume", func(t *testing.T) {
+		repo, txManager, userID := setup(t, clock.Fixed(now))
+		failure := errors.New("failure")
+
+		_, err := repo.Create(ctx, newToken(userID, entities.TokenPurposePasswordReset, "hash-1"))
+		require.NoError(t, err)
+
+		err = txManager.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := repo.Consume(ctx, entities.TokenPurposePasswordReset, "hash-1")
+			require.NoError(t, err)
+			return failure
+		})
+		require.ErrorIs(t, err, failure)
+
+		_, err = repo.Consume(ctx, entities.TokenPurposePasswordReset, "hash-1")
+		assert.NoError(t, err)
+	})
+}
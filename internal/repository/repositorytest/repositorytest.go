@@ -0,0 +1,52 @@
+// Package repositorytest holds the contracts every implementation of the
+// repositories must pass. It imports testing, so only tests may import it.
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+)
+
+// Repositories are the repositories of one store and the TxManager over it.
+type Repositories struct {
+	Users         repository.UserRepository
+	Sessions      repository.SessionRepository
+	Roles         repository.RoleRepository
+	APIKeys       repository.APIKeyRepository
+	MFA           repository.MFARepository
+	OneTimeTokens repository.OneTimeTokenRepository
+	Lockouts      repository.LockoutRepository
+	Audit         repository.AuditRepository
+	TxManager     repository.TxManager
+}
+
+// Factory returns the repositories of a store holding the seeded roles and
+// nothing else, all reading the time from clock. Every contract calls it
+// once per case.
+type Factory func(t *testing.T, clock clock.Clock) Repositories
+
+// contractClock is a clock the contracts move by hand.
+type contractClock struct {
+	now time.Time
+}
+
+func (c *contractClock) Now() time.Time {
+	return c.now
+}
+
+// RunContracts runs every contract against the repositories newRepositories
+// returns.
+func RunContracts(t *testing.T, newRepositories Factory) {
+	t.Run("UserRepository", func(t *testing.T) { RunUserRepositoryContract(t, newRepositories) })
+	t.Run("TxManager", func(t *testing.T) { RunTxManagerContract(t, newRepositories) })
+	t.Run("SessionRepository", func(t *testing.T) { RunSessionRepositoryContract(t, newRepositories) })
+	t.Run("RoleRepository", func(t *testing.T) { RunRoleRepositoryContract(t, newRepositories) })
+	t.Run("APIKeyRepository", func(t *testing.T) { RunAPIKeyRepositoryContract(t, newRepositories) })
+	t.Run("MFARepository", func(t *testing.T) { RunMFARepositoryContract(t, newRepositories) })
+	t.Run("OneTimeTokenRepository", func(t *testing.T) { RunOneTimeTokenRepositoryContract(t, newRepositories) })
+	t.Run("LockoutRepository", func(t *testing.T) { RunLockoutRepositoryContract(t, newRepositories) })
+	t.Run("AuditRepository", func(t *testing.T) { RunAuditRepositoryContract(t, newRepositories) })
+}
@@ -0,0 +1,145 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunRoleRepositoryContract checks a RoleRepository implementation.
+func RunRoleRepositoryContract(t *testing.T, newRepositories Factory) {
+	t.Helper()
+
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) (repository.RoleRepository, repository.TxManager, []entities.User) {
+		t.Helper()
+
+		repos := newRepositories(t, clock.Fixed(now))
+		roles, users, txManager := repos.Roles, repos.Users, repos.TxManager
+
+		holders := make([]entities.User, 0, 2)
+		for _, email := range []string{"holder1@gmail.com", "holder2@gmail.com"} {
+			user, err := users.Save(ctx, entities.User{Name: "Holder", Family: "Holder", Email: email, Age: 30})
+			require.NoError(t, err)
+			holders = append(holders, user)
+		}
+
+		return roles, txManager, holders
+	}
+
+	names := func(roles []entities.Role) []string {
+		result := make([]string, len(roles))
+		for i, role := range roles {
+			result[i] = role.Name
+		}
+		return result
+	}
+
+	t.Run("List", func(t *testing.T) {
+		repo, _, _ := setup(t)
+
+		roles, err := repo.List(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{entities.RoleAdmin, entities.RoleSupport, entities.RoleUser}, names(roles))
+		assert.Equal(t, []entities.Permission{
+			entities.PermAPIKeysManage, entities.PermAuditRead, entities.PermCredentialsVerify, entities.PermMFAManage, entities.PermMFAVerify, entities.PermRolesManage, entities.PermSessionsRead,
+			entities.PermUsersCreate, entities.PermUsersDelete, entities.PermUsersRead, entities.PermUsersUnlock, entities.PermUsersUpdate,
+		}, roles[0].Permissions)
+		assert.Equal(t, []entities.Permission{"mfa:verify:self", "sessions:read:self", "users:read:self", "users:update:self"}, roles[2].Permissions)
+	})
+
+	t.Run("ImplicitUserRole", func(t *testing.T) {
+		repo, _, holders := setup(t)
+
+		roles, err := repo.UserRoles(ctx, holders[0].ID)
+		require.NoError(t, err)
+		assert.Empty(t, roles)
+
+		permissions, err := repo.Permissions(ctx, holders[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, []entities.Permission{"mfa:verify:self", "sessions:read:self", "users:read:self", "users:update:self"}, permissions)
+	})
+
+	t.Run("AssignAndRevoke", func(t *testing.T) {
+		repo, _, holders := setup(t)
+
+		require.NoError(t, repo.Assign(ctx, holders[0].ID, entities.RoleSupport))
+		require.NoError(t, repo.Assign(ctx, holders[0].ID, entities.RoleSupport), "assigning twice changes nothing")
+
+		roles, err := repo.UserRoles(ctx, holders[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{entities.RoleSupport}, names(roles))
+
+		permissions, err := repo.Permissions(ctx, holders[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, []entities.Permission{
+			"mfa:verify", "mfa:verify:self", "sessions:read", "sessions:read:self", "users:read", "users:read:self", "users:unlock", "users:update", "users:update:self",
+		}, permissions)
+
+		others, err := repo.Permissions(ctx, holders[1].ID)
+		require.NoError(t, err)
+		assert.NotContains(t, others, entities.PermUsersUpdate)
+
+		require.NoError(t, repo.Revoke(ctx, holders[0].ID, entities.RoleSupport))
+
+		err = repo.Revoke(ctx, holders[0].ID, entities.RoleSupport)
+		assert.True(t, errors.Is(err, repository.ErrMsgRoleNotAssigned), "got %v", err)
+
+		permissions, err = repo.Permissions(ctx, holders[0].ID)
+		require.NoError(t, err)
+		assert.NotContains(t, permissions, entities.PermUsersUpdate)
+	})
+
+	t.Run("UnknownRole", func(t *testing.T) {
+		repo, _, holders := setup(t)
+
+		err := repo.Assign(ctx, holders[0].ID, "owner")
+		assert.True(t, errors.Is(err, repository.ErrMsgRoleNotFound), "got %v", err)
+
+		err = repo.Revoke(ctx, holders[0].ID, "owner")
+		assert.True(t, errors.Is(err, repository.ErrMsgRoleNotFound), "got %v", err)
+
+		_, err = repo.CountHolders(ctx, "owner")
+		assert.True(t, errors.Is(err, repository.ErrMsgRoleNotFound), "got %v", err)
+	})
+
+	t.Run("CountHolders", func(t *testing.T) {
+		repo, _, holders := setup(t)
+
+		for _, holder := range holders {
+			require.NoError(t, repo.Assign(ctx, holder.ID, entities.RoleAdmin))
+		}
+
+		count, err := repo.CountHolders(ctx, entities.RoleAdmin)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		count, err = repo.CountHolders(ctx, entities.RoleSupport)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("RollbackUndoesAssign", func(t *testing.T) {
+		repo, txManager, holders := setup(t)
+		failure := errors.New("failure")
+
+		err := txManager.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, repo.Assign(ctx, holders[0].ID, entities.RoleAdmin))
+			return failure
+		})
+		require.ErrorIs(t, err, failure)
+
+		roles, err := repo.UserRoles(ctx, holders[0].ID)
+		require.NoError(t, err)
+		assert.Empty(t, roles)
+	})
+}
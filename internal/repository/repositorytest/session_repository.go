@@ -0,0 +1,191 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunSessionRepositoryContract checks a SessionRepository implementation.
+func RunSessionRepositoryContract(t *testing.T, newRepositories Factory) {
+	t.Helper()
+
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) (repository.SessionRepository, repository.TxManager, []entities.User) {
+		t.Helper()
+
+		repos := newRepositories(t, clock.Fixed(now))
+		sessions, users, txManager := repos.Sessions, repos.Users, repos.TxManager
+
+		owners := make([]entities.User, 0, 2)
+		for _, email := range []string{"owner1@gmail.com", "owner2@gmail.com"} {
+			user, err := users.Save(ctx, entities.User{Name: "Owner", Family: "Owner", Email: email, Age: 30})
+			require.NoError(t, err)
+			owners = append(owners, user)
+		}
+
+		return sessions, txManager, owners
+	}
+
+	newSession := func(userID uint64, expiresAt time.Time) entities.Session {
+		return entities.Session{UserID: userID, UserAgent: "curl/8.0", IPAddress: "127.0.0.1", ExpiresAt: expiresAt}
+	}
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repo, _, owners := setup(t)
+
+		created, err := repo.Create(ctx, newSession(owners[0].ID, now.Add(time.Hour)), "hash-1")
+		require.NoError(t, err)
+		assert.NotZero(t, created.ID)
+		assert.True(t, now.Equal(created.LastUsedAt))
+
+		session, token, err := repo.FindByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, session.ID)
+		assert.Equal(t, owners[0].ID, session.UserID)
+		assert.Equal(t, "curl/8.0", session.UserAgent)
+		assert.Nil(t, session.RevokedAt)
+		assert.Equal(t, created.ID, token.SessionID)
+		assert.Nil(t, token.RotatedAt)
+
+		_, _, err = repo.FindByTokenHash(ctx, "unknown")
+		assert.True(t, errors.Is(err, repository.ErrMsgSessionNotFound), "got %v", err)
+
+		found, err := repo.FindByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, owners[0].ID, found.UserID)
+
+		_, err = repo.FindByID(ctx, created.ID+100)
+		assert.True(t, errors.Is(err, repository.ErrMsgSessionNotFound), "got %v", err)
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		repo, _, owners := setup(t)
+
+		created, err := repo.Create(ctx, newSession(owners[0].ID, now.Add(time.Hour)), "hash-1")
+		require.NoError(t, err)
+		_, token, err := repo.FindByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Rotate(ctx, token, "hash-2", now.Add(2*time.Hour)))
+
+		_, old, err := repo.FindByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.NotNil(t, old.RotatedAt, "the exchanged token stays around to detect reuse")
+
+		session, next, err := repo.FindByTokenHash(ctx, "hash-2")
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, session.ID)
+		assert.Nil(t, next.RotatedAt)
+		assert.True(t, now.Add(2*time.Hour).Equal(session.ExpiresAt))
+
+		err = repo.Rotate(ctx, token, "hash-3", now.Add(2*time.Hour))
+		assert.True(t, errors.Is(err, repository.ErrMsgTokenRotated), "got %v", err)
+		_, _, err = repo.FindByTokenHash(ctx, "hash-3")
+		assert.True(t, errors.Is(err, repository.ErrMsgSessionNotFound), "a lost rotation adds no token, got %v", err)
+	})
+
+	t.Run("RotateRollsBack", func(t *testing.T) {
+		repo, txManager, owners := setup(t)
+
+		_, err := repo.Create(ctx, newSession(owners[0].ID, now.Add(time.Hour)), "hash-1")
+		require.NoError(t, err)
+		_, token, err := repo.FindByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+
+		failure := errors.New("boom")
+		err = txManager.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, repo.Rotate(ctx, token, "hash-2", now.Add(2*time.Hour)))
+			return failure
+		})
+		require.ErrorIs(t, err, failure)
+
+		_, token, err = repo.FindByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		assert.Nil(t, token.RotatedAt)
+		_, _, err = repo.FindByTokenHash(ctx, "hash-2")
+		assert.True(t, errors.Is(err, repository.ErrMsgSessionNotFound), "got %v", err)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		repo, _, owners := setup(t)
+
+		created, err := repo.Create(ctx, newSession(owners[0].ID, now.Add(time.Hour)), "hash-1")
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Revoke(ctx, created.ID, entities.RevokeReasonLogout))
+		require.NoError(t, repo.Revoke(ctx, created.ID, entities.RevokeReasonReuse))
+
+		session, _, err := repo.FindByTokenHash(ctx, "hash-1")
+		require.NoError(t, err)
+		require.NotNil(t, session.RevokedAt)
+		assert.Equal(t, entities.RevokeReasonLogout, session.RevokeReason, "revoking again keeps the first reason")
+		assert.False(t, session.Active(now))
+
+		found, err := repo.FindByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, found.RevokedAt, "revoked sessions are still found")
+
+		err = repo.Revoke(ctx, created.ID+100, entities.RevokeReasonLogout)
+		assert.True(t, errors.Is(err, repository.ErrMsgSessionNotFound), "got %v", err)
+	})
+
+	t.Run("RevokeAll", func(t *testing.T) {
+		repo, _, owners := setup(t)
+
+		for i, hash := range []string{"hash-1", "hash-2", "hash-3"} {
+			owner := owners[0]
+			if i == 2 {
+				owner = owners[1]
+			}
+			_, err := repo.Create(ctx, newSession(owner.ID, now.Add(time.Hour)), hash)
+			require.NoError(t, err)
+		}
+
+		revoked, err := repo.RevokeAll(ctx, owners[0].ID, entities.RevokeReasonLogoutAll)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), revoked)
+
+		revoked, err = repo.RevokeAll(ctx, owners[0].ID, entities.RevokeReasonLogoutAll)
+		require.NoError(t, err)
+		assert.Zero(t, revoked)
+
+		active, err := repo.ListActive(ctx, owners[1].ID)
+		require.NoError(t, err)
+		assert.Len(t, active, 1, "other users keep their sessions")
+	})
+
+	t.Run("ListActive", func(t *testing.T) {
+		repo, _, owners := setup(t)
+
+		older, err := repo.Create(ctx, newSession(owners[0].ID, now.Add(time.Hour)), "hash-1")
+		require.NoError(t, err)
+		newer, err := repo.Create(ctx, newSession(owners[0].ID, now.Add(time.Hour)), "hash-2")
+		require.NoError(t, err)
+		revoked, err := repo.Create(ctx, newSession(owners[0].ID, now.Add(time.Hour)), "hash-3")
+		require.NoError(t, err)
+		require.NoError(t, repo.Revoke(ctx, revoked.ID, entities.RevokeReasonLogout))
+		_, err = repo.Create(ctx, newSession(owners[0].ID, now.Add(-time.Minute)), "hash-4")
+		require.NoError(t, err)
+		_, err = repo.Create(ctx, newSession(owners[1].ID, now.Add(time.Hour)), "hash-5")
+		require.NoError(t, err)
+
+		active, err := repo.ListActive(ctx, owners[0].ID)
+		require.NoError(t, err)
+
+		ids := make([]uint64, len(active))
+		for i, session := range active {
+			ids[i] = session.ID
+		}
+		assert.Equal(t, []uint64{newer.ID, older.ID}, ids, "revoked, expired and foreign sessions are left out")
+	})
+}
@@ -0,0 +1,117 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunTxManagerContract checks that repository calls made with the context
+// handed out by a TxManager commit or roll back together.
+func RunTxManagerContract(t *testing.T, newRepositories Factory) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	user := entities.User{Name: "Name", Family: "Family", Email: "user@gmail.com", Age: 30}
+
+	t.Run("Commit", func(t *testing.T) {
+		repos := newRepositories(t, clock.New())
+		repo, txManager := repos.Users, repos.TxManager
+
+		var saved entities.User
+		err := txManager.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			saved, err = repo.Save(ctx, user)
+			if err != nil {
+				return err
+			}
+
+			saved.Age = 31
+			saved, err = repo.Update(ctx, saved)
+			return err
+		})
+		require.NoError(t, err)
+
+		found, err := repo.FindByID(ctx, saved.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 31, found.Age)
+		assert.Equal(t, 2, found.Version)
+	})
+
+	t.Run("RollbackOnError", func(t *testing.T) {
+		repos := newRepositories(t, clock.New())
+		repo, txManager := repos.Users, repos.TxManager
+		existing, err := repo.Save(ctx, user)
+		require.NoError(t, err)
+
+		failure := errors.New("failure")
+		var created entities.User
+		err = txManager.WithinTx(ctx, func(ctx context.Context) error {
+			existing.Age = 99
+			_, err := repo.Update(ctx, existing)
+			require.NoError(t, err)
+			require.NoError(t, repo.Delete(ctx, existing.ID))
+
+			created, err = repo.Save(ctx, entities.User{Name: "Other", Family: "Other", Email: "other@gmail.com", Age: 40})
+			require.NoError(t, err)
+
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+
+		found, err := repo.FindByID(ctx, existing.ID)
+		require.NoError(t, err, "the delete should have been rolled back")
+		assert.Equal(t, 30, found.Age)
+		assert.Equal(t, 1, found.Version)
+
+		_, err = repo.FindByID(ctx, created.ID)
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "the insert should have been rolled back, got %v", err)
+	})
+
+	t.Run("RollbackOnPanic", func(t *testing.T) {
+		repos := newRepositories(t, clock.New())
+		repo, txManager := repos.Users, repos.TxManager
+
+		var saved entities.User
+		assert.Panics(t, func() {
+			_ = txManager.WithinTx(ctx, func(ctx context.Context) error {
+				var err error
+				saved, err = repo.Save(ctx, user)
+				require.NoError(t, err)
+				panic("boom")
+			})
+		})
+
+		_, err := repo.FindByID(ctx, saved.ID)
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "the insert should have been rolled back, got %v", err)
+	})
+
+	t.Run("NestedJoinsOuter", func(t *testing.T) {
+		repos := newRepositories(t, clock.New())
+		repo, txManager := repos.Users, repos.TxManager
+
+		failure := errors.New("failure")
+		var saved entities.User
+		err := txManager.WithinTx(ctx, func(ctx context.Context) error {
+			err := txManager.WithinTx(ctx, func(ctx context.Context) error {
+				var err error
+				saved, err = repo.Save(ctx, user)
+				return err
+			})
+			require.NoError(t, err)
+
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+
+		_, err = repo.FindByID(ctx, saved.ID)
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "the inner write belongs to the outer transaction, got %v", err)
+	})
+}
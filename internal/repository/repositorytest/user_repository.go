@@ -0,0 +1,482 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunUserRepositoryContract checks that a UserRepository implementation
+// behaves the way the usecases expect: which sentinel errors come back when,
+// how versions, soft delete and listing work.
+func RunUserRepositoryContract(t *testing.T, newRepositories Factory) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	newUser := func(i int) entities.User {
+		return entities.User{
+			Name:   fmt.Sprintf("Name %02d", i),
+			Family: fmt.Sprintf("Family %02d", i),
+			Email:  fmt.Sprintf("user%02d@gmail.com", i),
+			Age:    20 + i,
+		}
+	}
+
+	seed := func(t *testing.T, repo repository.UserRepository, n int) []entities.User {
+		t.Helper()
+
+		users := make([]entities.User, 0, n)
+		for i := 1; i <= n; i++ {
+			user, err := repo.Save(ctx, newUser(i))
+			require.NoError(t, err)
+			users = append(users, user)
+		}
+
+		return users
+	}
+
+	t.Run("Save", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+
+		first, err := repo.Save(ctx, newUser(1))
+		require.NoError(t, err)
+		second, err := repo.Save(ctx, newUser(2))
+		require.NoError(t, err)
+
+		assert.NotZero(t, first.ID)
+		assert.Greater(t, second.ID, first.ID, "ids should auto-increment")
+		assert.Equal(t, 1, first.Version)
+		assert.False(t, first.CreatedAt.IsZero())
+		assert.Equal(t, first.CreatedAt, first.UpdatedAt)
+	})
+
+	t.Run("SaveDuplicateEmail", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		seed(t, repo, 1)
+
+		duplicate := newUser(2)
+		duplicate.Email = newUser(1).Email
+		_, err := repo.Save(ctx, duplicate)
+
+		assert.True(t, errors.Is(err, repository.ErrMsgDuplicateUser), "expected repository.ErrMsgDuplicateUser, got %v", err)
+	})
+
+	t.Run("SaveBatch", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+
+		saved, err := repo.SaveBatch(ctx, []entities.User{newUser(1), newUser(2), newUser(3)})
+
+		require.NoError(t, err)
+		require.Len(t, saved, 3)
+		for i, user := range saved {
+			assert.NotZero(t, user.ID)
+			assert.Equal(t, 1, user.Version)
+			assert.Equal(t, newUser(i+1).Email, user.Email, "users keep the request order")
+
+			found, err := repo.FindByID(ctx, user.ID)
+			require.NoError(t, err)
+			assert.Equal(t, user.Email, found.Email)
+		}
+	})
+
+	t.Run("SaveBatchDuplicateSavesNothing", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		seed(t, repo, 1)
+
+		_, err := repo.SaveBatch(ctx, []entities.User{newUser(2), newUser(1)})
+		assert.True(t, errors.Is(err, repository.ErrMsgDuplicateUser), "expected repository.ErrMsgDuplicateUser, got %v", err)
+
+		_, err = repo.SaveBatch(ctx, []entities.User{newUser(3), newUser(3)})
+		assert.True(t, errors.Is(err, repository.ErrMsgDuplicateUser), "expected repository.ErrMsgDuplicateUser, got %v", err)
+
+		page, err := repo.FindAll(ctx, entities.UserListQuery{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), page.Total)
+	})
+
+	t.Run("ExistingEmails", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		users := seed(t, repo, 2)
+		require.NoError(t, repo.Delete(ctx, users[1].ID))
+
+		existing, err := repo.ExistingEmails(ctx, []string{newUser(1).Email, newUser(2).Email, newUser(3).Email})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{newUser(1).Email}, existing, "deleted users don't hold their email")
+	})
+
+	t.Run("FindByID", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		saved := seed(t, repo, 1)[0]
+
+		found, err := repo.FindByID(ctx, saved.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, saved.ID, found.ID)
+		assert.Equal(t, saved.Email, found.Email)
+		assert.Equal(t, saved.Version, found.Version)
+	})
+
+	t.Run("FindByIDMissing", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+
+		_, err := repo.FindByID(ctx, 999)
+
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "expected repository.ErrMsgUserNotFound, got %v", err)
+	})
+
+	t.Run("FindByEmail", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		users := seed(t, repo, 2)
+
+		found, err := repo.FindByEmail(ctx, users[1].Email)
+		require.NoError(t, err)
+		assert.Equal(t, users[1].ID, found.ID)
+
+		require.NoError(t, repo.Delete(ctx, users[1].ID))
+		_, err = repo.FindByEmail(ctx, users[1].Email)
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "deleted users are not found by email, got %v", err)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		saved := seed(t, repo, 1)[0]
+
+		saved.Name = "Changed"
+		saved.Age = 50
+		updated, err := repo.Update(ctx, saved)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Changed", updated.Name)
+		assert.Equal(t, 2, updated.Version)
+
+		found, err := repo.FindByID(ctx, saved.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Changed", found.Name)
+		assert.Equal(t, 50, found.Age)
+		assert.Equal(t, 2, found.Version)
+	})
+
+	t.Run("PasswordHash", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		saved, err := repo.Save(ctx, entities.User{Name: "Ali", Family: "Ahmadi", Email: "hash@example.com", Age: 30, PasswordHash: "$2a$10$hash"})
+		require.NoError(t, err)
+
+		saved.Name = "Changed"
+		_, err = repo.Update(ctx, saved)
+		require.NoError(t, err)
+
+		found, err := repo.FindByEmail(ctx, "hash@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "$2a$10$hash", found.PasswordHash, "updating the profile keeps the password")
+	})
+
+	t.Run("UpdatePassword", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		saved := seed(t, repo, 1)[0]
+
+		require.NoError(t, repo.UpdatePassword(ctx, saved.ID, "$2a$10$new"))
+
+		found, err := repo.FindByID(ctx, saved.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "$2a$10$new", found.PasswordHash)
+		assert.Equal(t, 2, found.Version)
+
+		err = repo.UpdatePassword(ctx, 999, "$2a$10$new")
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "expected repository.ErrMsgUserNotFound, got %v", err)
+	})
+
+	t.Run("MarkEmailVerified", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		saved := seed(t, repo, 1)[0]
+		verifiedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+		err := repo.MarkEmailVerified(ctx, saved.ID, "other@gmail.com", verifiedAt)
+		assert.True(t, errors.Is(err, repository.ErrMsgEmailChanged), "expected repository.ErrMsgEmailChanged, got %v", err)
+
+		require.NoError(t, repo.MarkEmailVerified(ctx, saved.ID, saved.Email, verifiedAt))
+
+		found, err := repo.FindByID(ctx, saved.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found.EmailVerifiedAt)
+		assert.True(t, verifiedAt.Equal(*found.EmailVerifiedAt))
+
+		found.Name = "Changed"
+		found, err = repo.Update(ctx, found)
+		require.NoError(t, err)
+		found, err = repo.FindByID(ctx, saved.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, found.EmailVerifiedAt, "keeping the email keeps it verified")
+
+		found.Email = "changed@gmail.com"
+		_, err = repo.Update(ctx, found)
+		require.NoError(t, err)
+		found, err = repo.FindByID(ctx, saved.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found.EmailVerifiedAt, "a new email is not verified")
+
+		err = repo.MarkEmailVerified(ctx, 999, saved.Email, verifiedAt)
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "expected repository.ErrMsgUserNotFound, got %v", err)
+	})
+
+	t.Run("UpdateMissingRow", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+
+		missing := newUser(1)
+		missing.ID = 999
+		missing.Version = 1
+		_, err := repo.Update(ctx, missing)
+
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "expected repository.ErrMsgUserNotFound, got %v", err)
+	})
+
+	t.Run("UpdateStaleVersion", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		saved := seed(t, repo, 1)[0]
+
+		_, err := repo.Update(ctx, saved)
+		require.NoError(t, err)
+
+		// saved still carries version 1
+		_, err = repo.Update(ctx, saved)
+
+		assert.True(t, errors.Is(err, repository.ErrMsgVersionConflict), "expected repository.ErrMsgVersionConflict, got %v", err)
+	})
+
+	t.Run("UpdateDuplicateEmail", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		users := seed(t, repo, 2)
+
+		users[1].Email = users[0].Email
+		_, err := repo.Update(ctx, users[1])
+
+		assert.True(t, errors.Is(err, repository.ErrMsgDuplicateUser), "expected repository.ErrMsgDuplicateUser, got %v", err)
+	})
+
+	t.Run("FindAll", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		seed(t, repo, 5)
+
+		page, err := repo.FindAll(ctx, entities.UserListQuery{
+			Sort:   []entities.UserSort{{Field: entities.UserSortByAge, Direction: entities.SortDesc}},
+			Limit:  2,
+			Offset: 1,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), page.Total)
+		require.Len(t, page.Users, 2)
+		assert.Equal(t, 24, page.Users[0].Age)
+		assert.Equal(t, 23, page.Users[1].Age)
+	})
+
+	t.Run("FindAllFilter", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		seed(t, repo, 5)
+
+		minAge, maxAge := 22, 24
+		page, err := repo.FindAll(ctx, entities.UserListQuery{
+			Filter: entities.UserFilter{Email: "USER0", MinAge: &minAge, MaxAge: &maxAge},
+			Limit:  10,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), page.Total)
+		require.Len(t, page.Users, 3)
+		assert.Equal(t, "user02@gmail.com", page.Users[0].Email)
+	})
+
+	t.Run("FindAllFilterEscapesWildcards", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		seed(t, repo, 3)
+
+		page, err := repo.FindAll(ctx, entities.UserListQuery{
+			Filter: entities.UserFilter{Name: "%"},
+			Limit:  10,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), page.Total)
+	})
+
+	t.Run("FindAllKeyset", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		seed(t, repo, 5)
+
+		query := entities.UserListQuery{
+			Sort:   []entities.UserSort{{Field: entities.UserSortByName, Direction: entities.SortDesc}},
+			Limit:  2,
+			Keyset: true,
+		}
+
+		var names []string
+		for pages := 0; pages < 5; pages++ {
+			page, err := repo.FindAll(ctx, query)
+			require.NoError(t, err)
+
+			for _, user := range page.Users {
+				names = append(names, user.Name)
+			}
+
+			if page.Next == nil {
+				break
+			}
+			query.After = page.Next
+		}
+
+		assert.Equal(t, []string{"Name 05", "Name 04", "Name 03", "Name 02", "Name 01"}, names)
+	})
+
+	t.Run("Iterate", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		users := seed(t, repo, 5)
+
+		var ids []uint64
+		batches := 0
+		err := repo.Iterate(ctx, entities.UserFilter{}, 2, func(batch []entities.User) error {
+			batches++
+			for _, user := range batch {
+				ids = append(ids, user.ID)
+			}
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, batches)
+		assert.Len(t, ids, len(users))
+		for i := 1; i < len(ids); i++ {
+			assert.Greater(t, ids[i], ids[i-1], "ids should be visited in ascending order")
+		}
+	})
+
+	t.Run("IterateStopsOnCallbackError", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		seed(t, repo, 5)
+
+		stop := errors.New("stop")
+		calls := 0
+		err := repo.Iterate(ctx, entities.UserFilter{}, 2, func(batch []entities.User) error {
+			calls++
+			return stop
+		})
+
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		saved := seed(t, repo, 1)[0]
+
+		err := repo.Delete(ctx, saved.ID)
+		require.NoError(t, err)
+
+		_, err = repo.FindByID(ctx, saved.ID)
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "deleted user should not be found, got %v", err)
+
+		err = repo.Delete(ctx, saved.ID)
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "deleting twice should report not found, got %v", err)
+
+		page, err := repo.FindAll(ctx, entities.UserListQuery{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), page.Total)
+
+		page, err = repo.FindAll(ctx, entities.UserListQuery{Filter: entities.UserFilter{IncludeDeleted: true}, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, page.Users, 1)
+		assert.True(t, page.Users[0].DeletedAt.Valid)
+		assert.Equal(t, saved.Version+1, page.Users[0].Version, "deleting is a change of the user")
+	})
+
+	t.Run("DeleteBatch", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		users := seed(t, repo, 3)
+		require.NoError(t, repo.Delete(ctx, users[0].ID))
+
+		deleted, err := repo.DeleteBatch(ctx, []uint64{users[2].ID, users[0].ID, users[1].ID, 999})
+
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{users[1].ID, users[2].ID}, deleted, "only active users are deleted, in id order")
+
+		page, err := repo.FindAll(ctx, entities.UserListQuery{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), page.Total)
+
+		page, err = repo.FindAll(ctx, entities.UserListQuery{Filter: entities.UserFilter{IncludeDeleted: true}, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, page.Users, 3)
+		for _, user := range page.Users {
+			assert.Equal(t, 2, user.Version, "user %d is deleted once", user.ID)
+		}
+	})
+
+	t.Run("DeleteMissing", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+
+		err := repo.Delete(ctx, 999)
+
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "expected repository.ErrMsgUserNotFound, got %v", err)
+	})
+
+	t.Run("DeletedEmailIsReusable", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		saved := seed(t, repo, 1)[0]
+		require.NoError(t, repo.Delete(ctx, saved.ID))
+
+		_, err := repo.Save(ctx, newUser(1))
+		require.NoError(t, err)
+
+		// the original can't come back while its email is taken
+		_, err = repo.Restore(ctx, saved.ID)
+		assert.True(t, errors.Is(err, repository.ErrMsgDuplicateUser), "expected repository.ErrMsgDuplicateUser, got %v", err)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		saved := seed(t, repo, 1)[0]
+		require.NoError(t, repo.Delete(ctx, saved.ID))
+
+		restored, err := repo.Restore(ctx, saved.ID)
+
+		require.NoError(t, err)
+		assert.False(t, restored.DeletedAt.Valid)
+		assert.Equal(t, saved.Version+2, restored.Version, "deleting and restoring each bump the version")
+
+		found, err := repo.FindByID(ctx, saved.ID)
+		require.NoError(t, err)
+		assert.Equal(t, restored.Version, found.Version)
+
+		_, err = repo.Restore(ctx, 999)
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "expected repository.ErrMsgUserNotFound, got %v", err)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		repo := newRepositories(t, clock.New()).Users
+		users := seed(t, repo, 3)
+		require.NoError(t, repo.Delete(ctx, users[0].ID))
+		require.NoError(t, repo.Delete(ctx, users[1].ID))
+
+		purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), purged, "nothing was deleted an hour ago")
+
+		purged, err = repo.Purge(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), purged)
+
+		_, err = repo.Restore(ctx, users[0].ID)
+		assert.True(t, errors.Is(err, repository.ErrMsgUserNotFound), "purged user should be gone, got %v", err)
+
+		_, err = repo.FindByID(ctx, users[2].ID)
+		assert.NoError(t, err, "active users are never purged")
+	})
+}
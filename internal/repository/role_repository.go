@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Definition Error Message
+var (
+	ErrMsgRoleNotFound    = errors.New("role not found")
+	ErrMsgRoleNotAssigned = errors.New("role not assigned to user")
+)
+
+// Definition Interface (Rules)
+type RoleRepository interface {
+	List(ctx context.Context) ([]entities.Role, error)
+	UserRoles(ctx context.Context, userID uint64) ([]entities.Role, error)
+	Permissions(ctx context.Context, userID uint64) ([]entities.Permission, error)
+	Assign(ctx context.Context, userID uint64, role string) error
+	Revoke(ctx context.Context, userID uint64, role string) error
+	CountHolders(ctx context.Context, role string) (int64, error)
+}
+
+// Definition Struct (Class)
+type roleRepository struct {
+	db    *gorm.DB
+	clock clock.Clock
+}
+
+// Definition Constructor
+func NewRoleRepository(db *gorm.DB, clock clock.Clock) RoleRepository {
+	return &roleRepository{db: db, clock: clock}
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+// List returns every role with its permissions, in the order they were created.
+func (r *roleRepository) List(ctx context.Context) ([]entities.Role, error) {
+	roles := []entities.Role{}
+	err := connection(ctx, r.db).Order("id").Find(&roles).Error
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return r.withPermissions(ctx, roles)
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+// UserRoles returns the roles assigned to userID. The implicit user role is
+// not among them.
+func (r *roleRepository) UserRoles(ctx context.Context, userID uint64) ([]entities.Role, error) {
+	roles := []entities.Role{}
+	err := connection(ctx, r.db).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.id").
+		Find(&roles).Error
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return r.withPermissions(ctx, roles)
+}
+
+// withPermissions fills in the permissions of roles.
+func (r *roleRepository) withPermissions(ctx context.Context, roles []entities.Role) ([]entities.Role, error) {
+	if len(roles) == 0 {
+		return roles, nil
+	}
+
+	ids := make([]uint64, len(roles))
+	for i, role := range roles {
+		ids[i] = role.ID
+	}
+
+	var rows []struct {
+		RoleID uint64
+		Name   entities.Permission
+	}
+	err := connection(ctx, r.db).Table("role_permissions").
+		Select("role_permissions.role_id, permissions.name").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("role_permissions.role_id IN ?", ids).
+		Order("permissions.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	byRole := make(map[uint64][]entities.Permission, len(roles))
+	for _, row := range rows {
+		byRole[row.RoleID] = append(byRole[row.RoleID], row.Name)
+	}
+
+	for i := range roles {
+		roles[i].Permissions = byRole[roles[i].ID]
+	}
+
+	return roles, nil
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+// Permissions returns every permission userID holds through its assigned
+// roles and the implicit user role, sorted and without repeats.
+func (r *roleRepository) Permissions(ctx context.Context, userID uint64) ([]entities.Permission, error) {
+	permissions := []entities.Permission{}
+	err := connection(ctx, r.db).Table("permissions").
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ? OR roles.id IN (?)", entities.RoleUser,
+			connection(ctx, r.db).Table("user_roles").Select("role_id").Where("user_id = ?", userID)).
+		Order("permissions.name").
+		Pluck("permissions.name", &permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return permissions, nil
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+// Assign gives userID the role. Assigning a role the user already holds
+// changes nothing.
+func (r *roleRepository) Assign(ctx context.Context, userID uint64, role string) error {
+	found, err := r.findByName(ctx, role)
+	if err != nil {
+		return err
+	}
+
+	err = connection(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&entities.UserRole{
+		UserID:    userID,
+		RoleID:    found.ID,
+		CreatedAt: r.clock.Now(),
+		CreatedBy: actor.FromContext(ctx),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+func (r *roleRepository) Revoke(ctx context.Context, userID uint64, role string) error {
+	found, err := r.findByName(ctx, role)
+	if err != nil {
+		return err
+	}
+
+	result := connection(ctx, r.db).Where("user_id = ? AND role_id = ?", userID, found.ID).Delete(&entities.UserRole{})
+	if result.Error != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrMsgRoleNotAssigned
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (List, UserRoles, Permissions, Assign, Revoke, CountHolders)
+// CountHolders returns how many users were assigned the role.
+func (r *roleRepository) CountHolders(ctx context.Context, role string) (int64, error) {
+	found, err := r.findByName(ctx, role)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = connection(ctx, r.db).Model(&entities.UserRole{}).Where("role_id = ?", found.ID).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return count, nil
+}
+
+// findByName returns the role called name.
+func (r *roleRepository) findByName(ctx context.Context, name string) (entities.Role, error) {
+	var role entities.Role
+	err := connection(ctx, r.db).Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Role{}, fmt.Errorf("%w: %q", ErrMsgRoleNotFound, name)
+		}
+
+		return entities.Role{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return role, nil
+}
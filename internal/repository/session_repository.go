@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"gorm.io/gorm"
+)
+
+// Definition Error Message
+var (
+	ErrMsgSessionNotFound = errors.New("session not found")
+	ErrMsgTokenRotated    = errors.New("refresh token was already rotated")
+)
+
+// Definition Interface (Rules)
+type SessionRepository interface {
+	Create(ctx context.Context, session entities.Session, tokenHash string) (entities.Session, error)
+	FindByID(ctx context.Context, id uint64) (entities.Session, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (entities.Session, entities.RefreshToken, error)
+	Rotate(ctx context.Context, token entities.RefreshToken, newHash string, expiresAt time.Time) error
+	Revoke(ctx context.Context, id uint64, reason string) error
+	RevokeAll(ctx context.Context, userID uint64, reason string) (int64, error)
+	ListActive(ctx context.Context, userID uint64) ([]entities.Session, error)
+}
+
+// Definition Struct (Class)
+type sessionRepository struct {
+	db    *gorm.DB
+	clock clock.Clock
+}
+
+// Definition Constructor
+func NewSessionRepository(db *gorm.DB, clock clock.Clock) SessionRepository {
+	return &sessionRepository{db: db, clock: clock}
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+// Create starts a session together with its first refresh token.
+func (r *sessionRepository) Create(ctx context.Context, session entities.Session, tokenHash string) (entities.Session, error) {
+	now := r.clock.Now()
+	session.ID = 0
+	session.CreatedAt = now
+	session.LastUsedAt = now
+	session.RevokedAt = nil
+	session.RevokeReason = ""
+
+	err := connection(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&session).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&entities.RefreshToken{SessionID: session.ID, TokenHash: tokenHash, CreatedAt: now}).Error
+	})
+	if err != nil {
+		return entities.Session{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return session, nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+// FindByID returns the session with id, revoked or not.
+func (r *sessionRepository) FindByID(ctx context.Context, id uint64) (entities.Session, error) {
+	var session entities.Session
+	err := connection(ctx, r.db).First(&session, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Session{}, fmt.Errorf("%w:%w", ErrMsgSessionNotFound, err)
+		}
+
+		return entities.Session{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return session, nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+// FindByTokenHash returns the refresh token with tokenHash, rotated or not,
+// and its session, revoked or not.
+func (r *sessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (entities.Session, entities.RefreshToken, error) {
+	var token entities.RefreshToken
+	err := connection(ctx, r.db).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Session{}, entities.RefreshToken{}, fmt.Errorf("%w:%w", ErrMsgSessionNotFound, err)
+		}
+
+		return entities.Session{}, entities.RefreshToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	var session entities.Session
+	err = connection(ctx, r.db).First(&session, token.SessionID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Session{}, entities.RefreshToken{}, fmt.Errorf("%w:%w", ErrMsgSessionNotFound, err)
+		}
+
+		return entities.Session{}, entities.RefreshToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return session, token, nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+// Rotate marks token as used and adds newHash as the next token of its
+// session, extending the session to expiresAt. The conditional update lets
+// only one of two concurrent rotations of the same token win, the other gets
+// ErrMsgTokenRotated.
+func (r *sessionRepository) Rotate(ctx context.Context, token entities.RefreshToken, newHash string, expiresAt time.Time) error {
+	now := r.clock.Now()
+
+	err := connection(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.RefreshToken{}).
+			Where("id = ? AND rotated_at IS NULL", token.ID).
+			Update("rotated_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrMsgTokenRotated
+		}
+
+		err := tx.Create(&entities.RefreshToken{SessionID: token.SessionID, TokenHash: newHash, CreatedAt: now}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&entities.Session{}).
+			Where("id = ?", token.SessionID).
+			Updates(map[string]interface{}{"last_used_at": now, "expires_at": expiresAt}).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrMsgTokenRotated) {
+			return err
+		}
+
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+// Revoke ends a session for good. Revoking it again keeps the first reason.
+func (r *sessionRepository) Revoke(ctx context.Context, id uint64, reason string) error {
+	result := connection(ctx, r.db).Model(&entities.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": r.clock.Now(), "revoke_reason": reason})
+	if result.Error != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		var count int64
+		err := connection(ctx, r.db).Model(&entities.Session{}).Where("id = ?", id).Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+		if count == 0 {
+			return ErrMsgSessionNotFound
+		}
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+// RevokeAll revokes every session of userID still open and returns how many.
+func (r *sessionRepository) RevokeAll(ctx context.Context, userID uint64, reason string) (int64, error) {
+	result := connection(ctx, r.db).Model(&entities.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{"revoked_at": r.clock.Now(), "revoke_reason": reason})
+	if result.Error != nil {
+		return 0, fmt.Errorf("%w:%w", ErrMsgInternalServerError, result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// Definition Implement Methods (Create, FindByID, FindByTokenHash, Rotate, Revoke, RevokeAll, ListActive)
+// ListActive returns the sessions of userID that can still be refreshed, most
+// recently used first.
+func (r *sessionRepository) ListActive(ctx context.Context, userID uint64) ([]entities.Session, error) {
+	sessions := []entities.Session{}
+	err := connection(ctx, r.db).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, r.clock.Now()).
+		Order("last_used_at DESC").Order("id DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return sessions, nil
+}
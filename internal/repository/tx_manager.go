@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Definition Interface (Rules)
+// TxManager runs fn inside a transaction carried by the context it passes on:
+// every repository call made with that context joins the transaction. The
+// transaction commits when fn returns nil and rolls back on an error or a
+// panic. Calling WithinTx with a context that already carries a transaction
+// joins it instead of starting a nested one.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txContextKey struct{}
+
+// Definition Struct (Class)
+type txManager struct {
+	db *gorm.DB
+}
+
+// Definition Constructor
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{db: db}
+}
+
+// Definition Implement Methods (WithinTx)
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
+
+// connection returns the transaction carried by ctx, or db outside of one,
+// bound to ctx.
+func connection(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
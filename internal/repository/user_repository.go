@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/alirezaghasemi/user-manager/internal/entities"
-	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
 	"gorm.io/gorm"
 )
 
@@ -17,35 +21,58 @@ var (
 	ErrMsgFailedToUpdateUser  = errors.New("failed to update user")
 	ErrMsgUserNotFound        = errors.New("user not found")
 	ErrMsgInternalServerError = errors.New("internal server")
+	ErrMsgInvalidCursor       = errors.New("invalid cursor")
+	ErrMsgVersionConflict     = errors.New("user version conflict")
+	ErrMsgEmailChanged        = errors.New("user email changed")
 )
 
+// Definition Batch Size
+// userBatchInsertSize keeps a multi-row INSERT well under the bind parameter
+// limits of Postgres (65535) and SQLite (32766).
+const userBatchInsertSize = 500
+
 // Definition Interface (Rules)
 type UserRepository interface {
 	Save(ctx context.Context, user entities.User) (entities.User, error)
+	SaveBatch(ctx context.Context, users []entities.User) ([]entities.User, error)
+	ExistingEmails(ctx context.Context, emails []string) ([]string, error)
 	FindByID(ctx context.Context, id uint64) (entities.User, error)
+	FindByEmail(ctx context.Context, email string) (entities.User, error)
 	Update(ctx context.Context, user entities.User) (entities.User, error)
-	FindAll(ctx context.Context) ([]entities.User, error)
+	UpdatePassword(ctx context.Context, id uint64, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id uint64, email string, at time.Time) error
+	FindAll(ctx context.Context, query entities.UserListQuery) (entities.UserPage, error)
+	Iterate(ctx context.Context, filter entities.UserFilter, batchSize int, fn func(users []entities.User) error) error
 	Delete(ctx context.Context, id uint64) error
+	DeleteBatch(ctx context.Context, ids []uint64) ([]uint64, error)
+	Restore(ctx context.Context, id uint64) (entities.User, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
 // Definition Struct (Class)
 type userRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	clock clock.Clock
 }
 
 // Definition Constructor
-func NewUserRepository(db *gorm.DB) UserRepository {
-	return &userRepository{db: db}
+func NewUserRepository(db *gorm.DB, clock clock.Clock) UserRepository {
+	return &userRepository{db: db, clock: clock}
 }
 
 // Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
 func (r *userRepository) Save(ctx context.Context, user entities.User) (entities.User, error) {
-	err := r.db.WithContext(ctx).Create(&user).Error
+	now := r.clock.Now()
+	user.Version = 1
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.CreatedBy = actor.FromContext(ctx)
+	user.UpdatedBy = user.CreatedBy
+
+	err := connection(ctx, r.db).Create(&user).Error
 	if err != nil {
 		// below check error for duplicate record error
-		// var pgErr *pq.Error
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		if isDuplicateKey(err) {
 			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgDuplicateUser, err)
 		}
 		return entities.User{}, fmt.Errorf("%w:%w", ErrMsgFailedToSaveUser, err)
@@ -54,10 +81,63 @@ func (r *userRepository) Save(ctx context.Context, user entities.User) (entities
 	return user, nil
 }
 
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+// SaveBatch inserts users with multi-row INSERTs of userBatchInsertSize rows.
+// Either every user is saved or, on any error, none is.
+func (r *userRepository) SaveBatch(ctx context.Context, users []entities.User) ([]entities.User, error) {
+	if len(users) == 0 {
+		return []entities.User{}, nil
+	}
+
+	now := r.clock.Now()
+	createdBy := actor.FromContext(ctx)
+
+	saved := make([]entities.User, len(users))
+	for i, user := range users {
+		user.ID = 0
+		user.Version = 1
+		user.CreatedAt = now
+		user.UpdatedAt = now
+		user.CreatedBy = createdBy
+		user.UpdatedBy = createdBy
+		saved[i] = user
+	}
+
+	err := connection(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&saved, userBatchInsertSize).Error
+	})
+	if err != nil {
+		if isDuplicateKey(err) {
+			return nil, fmt.Errorf("%w:%w", ErrMsgDuplicateUser, err)
+		}
+		return nil, fmt.Errorf("%w:%w", ErrMsgFailedToSaveUser, err)
+	}
+
+	return saved, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+// ExistingEmails returns which of emails already belong to an active user.
+func (r *userRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	existing := []string{}
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	err := connection(ctx, r.db).Model(&entities.User{}).
+		Where("email IN ?", emails).
+		Pluck("email", &existing).Error
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return existing, nil
+}
+
 // Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
 func (r *userRepository) FindByID(ctx context.Context, id uint64) (entities.User, error) {
 	var user entities.User
-	err := r.db.WithContext(ctx).First(&user, id).Error
+	err := connection(ctx, r.db).First(&user, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
@@ -70,12 +150,46 @@ func (r *userRepository) FindByID(ctx context.Context, id uint64) (entities.User
 }
 
 // Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+// FindByEmail returns the active user holding email.
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (entities.User, error) {
+	var user entities.User
+	err := connection(ctx, r.db).Where("email = ?", email).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
+		}
+
+		return entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return user, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+// Update only applies when the stored version still equals user.Version and
+// bumps it, so a writer working on a stale copy gets ErrMsgVersionConflict
+// instead of silently overwriting someone else's change. Changing the email
+// clears email_verified_at, the new address is not verified yet.
 func (r *userRepository) Update(ctx context.Context, user entities.User) (entities.User, error) {
-	tx := r.db.WithContext(ctx).Model(&user).Where("id = ?", user.ID).Updates(user)
+	user.UpdatedAt = r.clock.Now()
+	user.UpdatedBy = actor.FromContext(ctx)
+
+	tx := connection(ctx, r.db).Model(&entities.User{}).
+		Where("id = ? AND version = ?", user.ID, user.Version).
+		Updates(map[string]interface{}{
+			"name":       user.Name,
+			"family":     user.Family,
+			"email":      user.Email,
+			"age":        user.Age,
+			"updated_at": user.UpdatedAt,
+			"updated_by": user.UpdatedBy,
+			"version":    gorm.Expr("version + 1"),
+			// the right-hand side reads the row before the update
+			"email_verified_at": gorm.Expr("CASE WHEN email = ? THEN email_verified_at ELSE NULL END", user.Email),
+		})
 
 	if tx.Error != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(tx.Error, &pgErr) && pgErr.Code == "23505" {
+		if isDuplicateKey(tx.Error) {
 			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgDuplicateUser, tx.Error)
 		}
 
@@ -83,28 +197,296 @@ func (r *userRepository) Update(ctx context.Context, user entities.User) (entiti
 	}
 
 	if tx.RowsAffected == 0 {
-		return entities.User{}, fmt.Errorf("%w:%w", ErrMsgUserNotFound, tx.Error)
+		// tell a missing row from a stale version
+		var count int64
+		err := connection(ctx, r.db).Model(&entities.User{}).Where("id = ?", user.ID).Count(&count).Error
+		if err != nil {
+			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		if count == 0 {
+			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgUserNotFound, gorm.ErrRecordNotFound)
+		}
+
+		return entities.User{}, ErrMsgVersionConflict
 	}
 
+	user.Version++
 	return user, nil
 }
 
 // Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
-func (r *userRepository) FindAll(ctx context.Context) ([]entities.User, error) {
-	var users []entities.User
-	err := r.db.WithContext(ctx).Find(&users).Error
+// UpdatePassword replaces the password hash of an active user. It bumps the
+// version like any other write but doesn't check it, a reset must not fail
+// because the profile was edited in the meantime.
+func (r *userRepository) UpdatePassword(ctx context.Context, id uint64, passwordHash string) error {
+	return r.updateColumns(ctx, connection(ctx, r.db).Model(&entities.User{}).Where("id = ?", id), id, map[string]interface{}{
+		"password_hash": passwordHash,
+	})
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+// MarkEmailVerified sets email_verified_at of an active user, but only while
+// the user still has email; a link mailed to an old address verifies
+// nothing and gets ErrMsgEmailChanged.
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id uint64, email string, at time.Time) error {
+	return r.updateColumns(ctx, connection(ctx, r.db).Model(&entities.User{}).Where("id = ? AND email = ?", id, email), id, map[string]interface{}{
+		"email_verified_at": at,
+	})
+}
+
+// updateColumns applies columns to the rows of query, stamping the update,
+// and tells a missing user from one the extra conditions of query excluded.
+func (r *userRepository) updateColumns(ctx context.Context, query *gorm.DB, id uint64, columns map[string]interface{}) error {
+	columns["updated_at"] = r.clock.Now()
+	columns["updated_by"] = actor.FromContext(ctx)
+	columns["version"] = gorm.Expr("version + 1")
+
+	tx := query.Updates(columns)
+	if tx.Error != nil {
+		return fmt.Errorf("%w:%w", ErrMsgFailedToUpdateUser, tx.Error)
+	}
+
+	if tx.RowsAffected == 0 {
+		var count int64
+		err := connection(ctx, r.db).Model(&entities.User{}).Where("id = ?", id).Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		if count == 0 {
+			return fmt.Errorf("%w:%w", ErrMsgUserNotFound, gorm.ErrRecordNotFound)
+		}
+
+		return ErrMsgEmailChanged
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+func (r *userRepository) FindAll(ctx context.Context, query entities.UserListQuery) (entities.UserPage, error) {
+	if query.Keyset {
+		return r.findAllKeyset(ctx, query)
+	}
+
+	var total int64
+	err := r.filtered(ctx, query.Filter).Count(&total).Error
+	if err != nil {
+		return entities.UserPage{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	users := []entities.User{}
+	err = applyUserSort(r.filtered(ctx, query.Filter), query.Sort).
+		Limit(query.Limit).
+		Offset(query.Offset).
+		Find(&users).Error
 	if err != nil {
-		return []entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		return entities.UserPage{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return entities.UserPage{
+		Users:  users,
+		Total:  total,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}, nil
+}
+
+// findAllKeyset reads one page after query.After ordered by the keyset sort,
+// fetching one extra row to know whether a next page exists.
+func (r *userRepository) findAllKeyset(ctx context.Context, query entities.UserListQuery) (entities.UserPage, error) {
+	sort := query.KeysetSort()
+
+	tx := r.filtered(ctx, query.Filter)
+	if query.After != nil {
+		var err error
+		tx, err = applyUserCursor(tx, *query.After)
+		if err != nil {
+			return entities.UserPage{}, err
+		}
+	}
+
+	sorts := []entities.UserSort{sort}
+	if sort.Field != entities.UserSortByID {
+		sorts = append(sorts, entities.UserSort{Field: entities.UserSortByID, Direction: sort.Direction})
+	}
+
+	users := []entities.User{}
+	err := applyUserSort(tx, sorts).
+		Limit(query.Limit + 1).
+		Find(&users).Error
+	if err != nil {
+		return entities.UserPage{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	page := entities.UserPage{Limit: query.Limit}
+	if len(users) > query.Limit {
+		users = users[:query.Limit]
+		page.Next = entities.CursorAfter(users[len(users)-1], sort)
+	}
+	page.Users = users
+
+	return page, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+// Iterate walks every matching user in id order, handing batches to fn. Each
+// batch is a keyset query after the last seen id, so rows are never skipped or
+// repeated while others are inserted or deleted concurrently.
+func (r *userRepository) Iterate(ctx context.Context, filter entities.UserFilter, batchSize int, fn func(users []entities.User) error) error {
+	if batchSize <= 0 {
+		batchSize = entities.DefaultUserBatchSize
+	}
+
+	var lastID uint64
+	for {
+		users := []entities.User{}
+		err := r.filtered(ctx, filter).
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(batchSize).
+			Find(&users).Error
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		if len(users) == 0 {
+			return nil
+		}
+
+		err = fn(users)
+		if err != nil {
+			return err
+		}
+
+		if len(users) < batchSize {
+			return nil
+		}
+		lastID = users[len(users)-1].ID
+	}
+}
+
+// filtered returns a fresh users query with the filter conditions applied, so
+// the count and the page query don't share statement state.
+func (r *userRepository) filtered(ctx context.Context, filter entities.UserFilter) *gorm.DB {
+	tx := connection(ctx, r.db).Model(&entities.User{})
+	if filter.IncludeDeleted {
+		tx = tx.Unscoped()
+	}
+
+	if filter.Name != "" {
+		tx = tx.Where(`LOWER(name) LIKE ? ESCAPE '\'`, containsPattern(filter.Name))
+	}
+	if filter.Family != "" {
+		tx = tx.Where(`LOWER(family) LIKE ? ESCAPE '\'`, containsPattern(filter.Family))
+	}
+	if filter.Email != "" {
+		tx = tx.Where(`LOWER(email) LIKE ? ESCAPE '\'`, containsPattern(filter.Email))
+	}
+	if filter.MinAge != nil {
+		tx = tx.Where("age >= ?", *filter.MinAge)
+	}
+	if filter.MaxAge != nil {
+		tx = tx.Where("age <= ?", *filter.MaxAge)
 	}
 
-	return users, nil
+	return tx
+}
+
+// userSortColumns whitelists the columns a listing may be ordered by.
+var userSortColumns = map[entities.UserSortField]string{
+	entities.UserSortByID:     "id",
+	entities.UserSortByName:   "name",
+	entities.UserSortByFamily: "family",
+	entities.UserSortByEmail:  "email",
+	entities.UserSortByAge:    "age",
+}
+
+// applyUserSort orders by the requested fields and always ends with id so
+// pages are stable when sort keys collide.
+func applyUserSort(tx *gorm.DB, sorts []entities.UserSort) *gorm.DB {
+	orderedByID := false
+	for _, sort := range sorts {
+		column, ok := userSortColumns[sort.Field]
+		if !ok {
+			continue
+		}
+
+		direction := "ASC"
+		if sort.Direction == entities.SortDesc {
+			direction = "DESC"
+		}
+
+		tx = tx.Order(column + " " + direction)
+		if sort.Field == entities.UserSortByID {
+			orderedByID = true
+		}
+	}
+
+	if !orderedByID {
+		tx = tx.Order("id ASC")
+	}
+
+	return tx
+}
+
+// applyUserCursor restricts the query to rows strictly after the cursor in
+// (sort column, id) order.
+func applyUserCursor(tx *gorm.DB, after entities.UserCursor) (*gorm.DB, error) {
+	column, ok := userSortColumns[after.Field]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown cursor field %q", ErrMsgInvalidCursor, after.Field)
+	}
+
+	operator := ">"
+	if after.Direction == entities.SortDesc {
+		operator = "<"
+	}
+
+	if after.Field == entities.UserSortByID {
+		return tx.Where("id "+operator+" ?", after.ID), nil
+	}
+
+	var value interface{} = after.Value
+	if after.Field == entities.UserSortByAge {
+		age, err := strconv.Atoi(after.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w:%w", ErrMsgInvalidCursor, err)
+		}
+		value = age
+	}
+
+	return tx.Where(
+		fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, operator),
+		value, value, after.ID,
+	), nil
+}
+
+// isDuplicateKey reports a unique constraint violation. Connections opened with
+// TranslateError report gorm.ErrDuplicatedKey for every driver, the SQLSTATE
+// check covers a Postgres connection opened without it.
+func isDuplicateKey(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	var sqlStateErr interface{ SQLState() string }
+	return errors.As(err, &sqlStateErr) && sqlStateErr.SQLState() == "23505"
+}
+
+// containsPattern builds a lower-cased LIKE pattern matching value anywhere,
+// escaping the LIKE wildcards it may contain.
+func containsPattern(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return "%" + replacer.Replace(strings.ToLower(value)) + "%"
 }
 
 // Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
 func (r *userRepository) Delete(ctx context.Context, id uint64) error {
 	// first check record is exists
 	var user entities.User
-	err := r.db.WithContext(ctx).First(&user, id).Error
+	err := connection(ctx, r.db).First(&user, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
@@ -113,11 +495,100 @@ func (r *userRepository) Delete(ctx context.Context, id uint64) error {
 		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
 	}
 
-	// delete section
-	err = r.db.WithContext(ctx).Delete(&user).Error
+	// delete section, soft delete by setting deleted_at
+	now := r.clock.Now()
+	err = connection(ctx, r.db).Model(&user).Updates(map[string]interface{}{
+		"deleted_at": now,
+		"updated_at": now,
+		"updated_by": actor.FromContext(ctx),
+		"version":    gorm.Expr("version + 1"),
+	}).Error
 	if err != nil {
 		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
 	}
 
 	return nil
 }
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+// DeleteBatch soft-deletes the active users among ids and returns the ids it
+// deleted; ids that are missing or already deleted are left out.
+func (r *userRepository) DeleteBatch(ctx context.Context, ids []uint64) ([]uint64, error) {
+	deleted := []uint64{}
+	if len(ids) == 0 {
+		return deleted, nil
+	}
+
+	err := connection(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entities.User{}).Where("id IN ?", ids).Order("id ASC").Pluck("id", &deleted).Error
+		if err != nil || len(deleted) == 0 {
+			return err
+		}
+
+		now := r.clock.Now()
+		return tx.Model(&entities.User{}).Where("id IN ?", deleted).Updates(map[string]interface{}{
+			"deleted_at": now,
+			"updated_at": now,
+			"updated_by": actor.FromContext(ctx),
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return deleted, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+// Restore clears deleted_at. Restoring a user that isn't deleted is a no-op.
+func (r *userRepository) Restore(ctx context.Context, id uint64) (entities.User, error) {
+	var user entities.User
+	err := connection(ctx, r.db).Unscoped().First(&user, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
+		}
+
+		return entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	if !user.DeletedAt.Valid {
+		return user, nil
+	}
+
+	user.UpdatedAt = r.clock.Now()
+	user.UpdatedBy = actor.FromContext(ctx)
+
+	err = connection(ctx, r.db).Unscoped().Model(&user).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"updated_at": user.UpdatedAt,
+		"updated_by": user.UpdatedBy,
+		"version":    gorm.Expr("version + 1"),
+	}).Error
+	if err != nil {
+		// another active user took the email in the meantime
+		if isDuplicateKey(err) {
+			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgDuplicateUser, err)
+		}
+
+		return entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	user.DeletedAt = gorm.DeletedAt{}
+	user.Version++
+	return user, nil
+}
+
+// Definition Implement Methods (Save, Update, Delete, FindByID, FindAll)
+// Purge permanently removes users soft-deleted before deletedBefore.
+func (r *userRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	tx := connection(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore.UTC()).
+		Delete(&entities.User{})
+	if tx.Error != nil {
+		return 0, fmt.Errorf("%w:%w", ErrMsgInternalServerError, tx.Error)
+	}
+
+	return tx.RowsAffected, nil
+}
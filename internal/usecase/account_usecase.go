@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/mailer"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/token"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+)
+
+// Definition Error Message
+var (
+	ErrMsgInvalidOneTimeToken  = errors.New("invalid or expired token")
+	ErrMsgEmailAlreadyVerified = errors.New("email is already verified")
+)
+
+// AccountOptions configures the links mailed to users.
+type AccountOptions struct {
+	// LinkBaseURL is where the pages opening the links live, e.g.
+	// "https://app.example.com". They post the token back to the API.
+	LinkBaseURL string
+	// EmailVerificationTTL and PasswordResetTTL are how long a link works.
+	EmailVerificationTTL time.Duration
+	PasswordResetTTL     time.Duration
+	// Go runs the work RequestPasswordReset leaves for after it returned. Nil
+	// starts a goroutine; tests run it right away.
+	Go func(fn func())
+	// MaxPendingMails reset mails are looked up and sent at once at most,
+	// requests finding them all busy are dropped. 0 allows one.
+	MaxPendingMails int
+	// ResetLimit reset mails to one address within ResetWindow are sent,
+	// requests after that are dropped for a window. 0 sends them all.
+	ResetLimit  int
+	ResetWindow time.Duration
+}
+
+// Definition Interface (Rules)
+type AccountUsecase interface {
+	RequestEmailVerification(ctx context.Context, userID uint64) error
+	VerifyEmail(ctx context.Context, rawToken string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, rawToken string, newPassword string) error
+}
+
+// Definition Struct (Class)
+type accountUsecase struct {
+	users      repository.UserRepository
+	tokens     repository.OneTimeTokenRepository
+	sessions   repository.SessionRepository
+	txManager  repository.TxManager
+	authorizer Authorizer
+	mailer     mailer.Mailer
+	logger     *slog.Logger
+	clock      clock.Clock
+	options    AccountOptions
+	throttle   throttle
+	pending    chan struct{}
+}
+
+// Definition Constructor
+// Requesting a verification mail needs users:update on the user, the other
+// methods are for anonymous callers holding a mailed token or an email.
+// Mails sent after the method answered log their failures to logger. Reset
+// mails to one address are counted in lockouts.
+func NewAccountUsecase(users repository.UserRepository, tokens repository.OneTimeTokenRepository, sessions repository.SessionRepository, lockouts repository.LockoutRepository, txManager repository.TxManager, authorizer Authorizer, mailer mailer.Mailer, logger *slog.Logger, clock clock.Clock, options AccountOptions) AccountUsecase {
+	if options.Go == nil {
+		options.Go = func(fn func()) { go fn() }
+	}
+	if options.MaxPendingMails <= 0 {
+		options.MaxPendingMails = 1
+	}
+
+	return &accountUsecase{
+		users:      users,
+		tokens:     tokens,
+		sessions:   sessions,
+		txManager:  txManager,
+		authorizer: authorizer,
+		mailer:     mailer,
+		logger:     logger.With(slog.String("component", "usecase")),
+		clock:      clock,
+		options:    options,
+		throttle: throttle{lockouts: lockouts, options: LockoutOptions{
+			Window:       options.ResetWindow,
+			BaseDuration: options.ResetWindow,
+			MaxDuration:  options.ResetWindow,
+		}},
+		pending: make(chan struct{}, options.MaxPendingMails),
+	}
+}
+
+// Definition Implement Methods (RequestEmailVerification, VerifyEmail, RequestPasswordReset, ResetPassword)
+// RequestEmailVerification mails the user a link proving they own their
+// email. Links sent before stop working.
+func (u *accountUsecase) RequestEmailVerification(ctx context.Context, userID uint64) error {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersUpdate, userID)
+	if err != nil {
+		return err
+	}
+
+	user, err := u.users.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgUserNotFound) {
+			return fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
+		}
+
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return ErrMsgEmailAlreadyVerified
+	}
+
+	raw, err := u.issue(ctx, user, entities.TokenPurposeEmailVerification, u.options.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return u.send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nopen this link to verify your email:\n%s\n\nIt works once and expires in %s.\n",
+			user.Name, u.link("/verify-email", raw), u.options.EmailVerificationTTL),
+	})
+}
+
+// Definition Implement Methods (RequestEmailVerification, VerifyEmail, RequestPasswordReset, ResetPassword)
+// VerifyEmail consumes a verification token. A token mailed to an address
+// the user has changed since verifies nothing.
+func (u *accountUsecase) VerifyEmail(ctx context.Context, rawToken string) error {
+	return u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		consumed, err := u.consume(ctx, entities.TokenPurposeEmailVerification, rawToken)
+		if err != nil {
+			return err
+		}
+		ctx = asTokenHolder(ctx, consumed)
+
+		err = u.users.MarkEmailVerified(ctx, consumed.UserID, consumed.Email, u.clock.Now())
+		if err != nil {
+			if errors.Is(err, repository.ErrMsgUserNotFound) || errors.Is(err, repository.ErrMsgEmailChanged) {
+				return fmt.Errorf("%w:%w", ErrMsgInvalidOneTimeToken, err)
+			}
+
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		return nil
+	})
+}
+
+// Definition Implement Methods (RequestEmailVerification, VerifyEmail, RequestPasswordReset, ResetPassword)
+// RequestPasswordReset mails a reset link to the user holding email. It
+// returns at once and looks the user up afterwards, so neither its answer nor
+// the time it takes tells which addresses have an account. Requests finding
+// MaxPendingMails mails in the works are dropped, as are those past the
+// limit of the address.
+func (u *accountUsecase) RequestPasswordReset(ctx context.Context, email string) error {
+	select {
+	case u.pending <- struct{}{}:
+	default:
+		u.logger.WarnContext(ctx, "password reset mail dropped, too many pending")
+		return nil
+	}
+
+	u.later(ctx, "password reset mail failed", func(ctx context.Context) error {
+		defer func() { <-u.pending }()
+
+		return u.mailPasswordReset(ctx, email)
+	})
+
+	return nil
+}
+
+// mailPasswordReset mails a reset link to the user holding email, nothing
+// when there is none or the address reached its limit.
+func (u *accountUsecase) mailPasswordReset(ctx context.Context, email string) error {
+	now := u.clock.Now()
+	key := entities.LockoutKeyPasswordReset(email)
+
+	var locked *LockedError
+	err := u.throttle.check(ctx, key, now)
+	if errors.As(err, &locked) {
+		u.logger.InfoContext(ctx, "password reset mail dropped, limit of the address reached")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// every request counts, whether the address has an account or not
+	err = u.throttle.fail(ctx, key, u.options.ResetLimit, now)
+	if err != nil {
+		return err
+	}
+
+	user, err := u.users.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgUserNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	raw, err := u.issue(ctx, user, entities.TokenPurposePasswordReset, u.options.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return u.send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nopen this link to choose a new password:\n%s\n\nIt works once and expires in %s. If you didn't ask for it, ignore this mail.\n",
+			user.Name, u.link("/reset-password", raw), u.options.PasswordResetTTL),
+	})
+}
+
+// Definition Implement Methods (RequestEmailVerification, VerifyEmail, RequestPasswordReset, ResetPassword)
+// ResetPassword consumes a reset token and sets newPassword. A token mailed
+// to an address the user has changed since resets nothing. Every session of
+// the user is revoked and other reset links stop working, then the user is
+// told by mail that the password changed; a failure to send it is logged.
+// The password is only hashed once the token proved good, so anonymous
+// callers without one can't keep bcrypt busy.
+func (u *accountUsecase) ResetPassword(ctx context.Context, rawToken string, newPassword string) error {
+	var user entities.User
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		consumed, err := u.consume(ctx, entities.TokenPurposePasswordReset, rawToken)
+		if err != nil {
+			return err
+		}
+		ctx = asTokenHolder(ctx, consumed)
+
+		user, err = u.users.FindByID(ctx, consumed.UserID)
+		if err != nil {
+			if errors.Is(err, repository.ErrMsgUserNotFound) {
+				return fmt.Errorf("%w:%w", ErrMsgInvalidOneTimeToken, err)
+			}
+
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		if user.Email != consumed.Email {
+			return fmt.Errorf("%w:%w", ErrMsgInvalidOneTimeToken, repository.ErrMsgEmailChanged)
+		}
+
+		hash, err := passwordHash(newPassword)
+		if err != nil {
+			return err
+		}
+
+		err = u.users.UpdatePassword(ctx, user.ID, hash)
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		_, err = u.tokens.InvalidateAll(ctx, user.ID, entities.TokenPurposePasswordReset)
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		_, err = u.sessions.RevokeAll(ctx, user.ID, entities.RevokeReasonPasswordReset)
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	u.later(ctx, "password changed mail failed", func(ctx context.Context) error {
+		return u.send(ctx, mailer.Message{
+			To:      user.Email,
+			Subject: "Your password was changed",
+			Body:    fmt.Sprintf("Hi %s,\n\nyour password was reset and every session was logged out.\n", user.Name),
+		})
+	})
+
+	return nil
+}
+
+// issue stores a new token of purpose for user, replacing the ones sent
+// before, and returns its raw value for the link.
+func (u *accountUsecase) issue(ctx context.Context, user entities.User, purpose entities.TokenPurpose, ttl time.Duration) (string, error) {
+	raw, err := token.NewOpaque()
+	if err != nil {
+		return "", fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := u.tokens.InvalidateAll(ctx, user.ID, purpose)
+		if err != nil {
+			return err
+		}
+
+		_, err = u.tokens.Create(ctx, entities.OneTimeToken{
+			UserID:    user.ID,
+			Email:     user.Email,
+			Purpose:   purpose,
+			TokenHash: token.Hash(raw),
+			ExpiresAt: u.clock.Now().Add(ttl),
+		})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return raw, nil
+}
+
+// consume marks the token raw of purpose used, failing with
+// ErrMsgInvalidOneTimeToken whatever is wrong with it.
+func (u *accountUsecase) consume(ctx context.Context, purpose entities.TokenPurpose, raw string) (entities.OneTimeToken, error) {
+	consumed, err := u.tokens.Consume(ctx, purpose, token.Hash(raw))
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgOneTimeTokenNotFound) || errors.Is(err, repository.ErrMsgOneTimeTokenSpent) {
+			return entities.OneTimeToken{}, fmt.Errorf("%w:%w", ErrMsgInvalidOneTimeToken, err)
+		}
+
+		return entities.OneTimeToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return consumed, nil
+}
+
+// asTokenHolder records the user a consumed token was mailed to as the actor
+// of the changes it allows, the callers being anonymous otherwise.
+func asTokenHolder(ctx context.Context, consumed entities.OneTimeToken) context.Context {
+	return actor.WithActor(ctx, entities.Principal{UserID: consumed.UserID}.Actor())
+}
+
+// link returns the page at path of LinkBaseURL carrying raw.
+func (u *accountUsecase) link(path string, raw string) string {
+	return u.options.LinkBaseURL + path + "?token=" + url.QueryEscape(raw)
+}
+
+// later runs fn through options.Go with the values of ctx but not its
+// cancellation, which comes with the answer to the caller. An error of fn is
+// logged with message.
+func (u *accountUsecase) later(ctx context.Context, message string, fn func(ctx context.Context) error) {
+	ctx = context.WithoutCancel(ctx)
+	u.options.Go(func() {
+		err := fn(ctx)
+		if err != nil {
+			u.logger.ErrorContext(ctx, message, slog.Any("error", err))
+		}
+	})
+}
+
+// send delivers msg, a failure is an internal error.
+func (u *accountUsecase) send(ctx context.Context, msg mailer.Message) error {
+	err := u.mailer.Send(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
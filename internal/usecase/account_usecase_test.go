@@ -0,0 +1,344 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/mailer"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/password"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outbox is a mailer keeping what was sent, or failing with err.
+type outbox struct {
+	sent []mailer.Message
+	err  error
+}
+
+func (o *outbox) Send(ctx context.Context, msg mailer.Message) error {
+	if o.err != nil {
+		return o.err
+	}
+
+	o.sent = append(o.sent, msg)
+	return nil
+}
+
+// background runs the work the account usecase leaves for later right away,
+// or keeps it in pending while held.
+type background struct {
+	held    bool
+	pending []func()
+}
+
+func (b *background) Go(fn func()) {
+	if b.held {
+		b.pending = append(b.pending, fn)
+		return
+	}
+
+	fn()
+}
+
+var linkPattern = regexp.MustCompile(`https?://\S+`)
+
+// token returns the token in the link of the last mail sent.
+func (o *outbox) token(t *testing.T) string {
+	t.Helper()
+
+	require.NotEmpty(t, o.sent)
+	link, err := url.Parse(linkPattern.FindString(o.sent[len(o.sent)-1].Body))
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+type accountFixture struct {
+	account  usecase.AccountUsecase
+	users    repository.UserRepository
+	sessions repository.SessionRepository
+	outbox   *outbox
+	later    *background
+	logs     *bytes.Buffer
+	clock    *movingClock
+	user     entities.User
+	caller   context.Context
+}
+
+// newAccountFixture wires the account usecase to memory repositories holding
+// one user with a password, with caller carrying that user as the principal.
+func newAccountFixture(t *testing.T) accountFixture {
+	t.Helper()
+
+	ctx := context.Background()
+	clk := &movingClock{now: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}
+
+	hash, err := password.Hash("old password")
+	require.NoError(t, err)
+
+	users := repository.NewMemoryUserRepository(clk)
+	user, err := users.Save(ctx, entities.User{Name: "Ali", Family: "Ahmadi", Email: "ali@gmail.com", Age: 30, PasswordHash: hash})
+	require.NoError(t, err)
+
+	sessions := repository.NewMemorySessionRepository(clk)
+	box := &outbox{}
+	later := &background{}
+	logs := &bytes.Buffer{}
+	authorizer := usecase.NewAuthorizer(repository.NewMemoryRoleRepository(clk))
+	options := usecase.AccountOptions{
+		LinkBaseURL:          "https://app.example.com",
+		EmailVerificationTTL: 24 * time.Hour,
+		PasswordResetTTL:     time.Hour,
+		Go:                   later.Go,
+		MaxPendingMails:      1,
+		ResetLimit:           3,
+		ResetWindow:          time.Hour,
+	}
+	logger := slog.New(slog.NewJSONHandler(logs, nil))
+
+	return accountFixture{
+		account:  usecase.NewAccountUsecase(users, repository.NewMemoryOneTimeTokenRepository(clk), sessions, repository.NewMemoryLockoutRepository(clk), repository.NewMemoryTxManager(), authorizer, box, logger, clk, options),
+		users:    users,
+		sessions: sessions,
+		outbox:   box,
+		later:    later,
+		logs:     logs,
+		clock:    clk,
+		user:     user,
+		caller:   principal.WithPrincipal(ctx, entities.Principal{UserID: user.ID}),
+	}
+}
+
+func TestAccountUsecase_VerifyEmail(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		f := newAccountFixture(t)
+
+		require.NoError(t, f.account.RequestEmailVerification(f.caller, f.user.ID))
+		require.Len(t, f.outbox.sent, 1)
+		assert.Equal(t, "ali@gmail.com", f.outbox.sent[0].To)
+		assert.Contains(t, f.outbox.sent[0].Body, "https://app.example.com/verify-email?token=")
+
+		raw := f.outbox.token(t)
+		require.NoError(t, f.account.VerifyEmail(context.Background(), raw))
+
+		user, err := f.users.FindByID(context.Background(), f.user.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, user.EmailVerifiedAt)
+
+		err = f.account.VerifyEmail(context.Background(), raw)
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidOneTimeToken), "a token works once, got %v", err)
+
+		err = f.account.RequestEmailVerification(f.caller, f.user.ID)
+		assert.True(t, errors.Is(err, usecase.ErrMsgEmailAlreadyVerified), "got %v", err)
+	})
+
+	t.Run("OnlyLatestLinkWorks", func(t *testing.T) {
+		f := newAccountFixture(t)
+
+		require.NoError(t, f.account.RequestEmailVerification(f.caller, f.user.ID))
+		first := f.outbox.token(t)
+		require.NoError(t, f.account.RequestEmailVerification(f.caller, f.user.ID))
+
+		err := f.account.VerifyEmail(context.Background(), first)
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidOneTimeToken), "got %v", err)
+
+		assert.NoError(t, f.account.VerifyEmail(context.Background(), f.outbox.token(t)))
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		f := newAccountFixture(t)
+
+		require.NoError(t, f.account.RequestEmailVerification(f.caller, f.user.ID))
+		f.clock.now = f.clock.now.Add(25 * time.Hour)
+
+		err := f.account.VerifyEmail(context.Background(), f.outbox.token(t))
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidOneTimeToken), "got %v", err)
+	})
+
+	t.Run("EmailChanged", func(t *testing.T) {
+		f := newAccountFixture(t)
+
+		require.NoError(t, f.account.RequestEmailVerification(f.caller, f.user.ID))
+
+		f.user.Email = "new@gmail.com"
+		_, err := f.users.Update(context.Background(), f.user)
+		require.NoError(t, err)
+
+		err = f.account.VerifyEmail(context.Background(), f.outbox.token(t))
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidOneTimeToken), "a link to the old address verifies nothing, got %v", err)
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		f := newAccountFixture(t)
+		other := principal.WithPrincipal(context.Background(), entities.Principal{UserID: f.user.ID + 1})
+
+		err := f.account.RequestEmailVerification(other, f.user.ID)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+		assert.Empty(t, f.outbox.sent)
+	})
+}
+
+func TestAccountUsecase_ResetPassword(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		f := newAccountFixture(t)
+		ctx := context.Background()
+		_, err := f.sessions.Create(ctx, entities.Session{UserID: f.user.ID, ExpiresAt: f.clock.now.Add(time.Hour)}, "refresh-hash")
+		require.NoError(t, err)
+
+		require.NoError(t, f.account.RequestPasswordReset(ctx, "ali@gmail.com"))
+		require.Len(t, f.outbox.sent, 1)
+		assert.Contains(t, f.outbox.sent[0].Body, "https://app.example.com/reset-password?token=")
+		raw := f.outbox.token(t)
+
+		require.NoError(t, f.account.ResetPassword(ctx, raw, "new password"))
+
+		user, err := f.users.FindByID(ctx, f.user.ID)
+		require.NoError(t, err)
+		assert.NoError(t, password.Compare(user.PasswordHash, "new password"))
+		assert.Equal(t, fmt.Sprintf("user:%d", f.user.ID), user.UpdatedBy, "the token holder changed it")
+
+		active, err := f.sessions.ListActive(ctx, f.user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, active, "every session is logged out")
+
+		require.Len(t, f.outbox.sent, 2)
+		assert.Equal(t, "Your password was changed", f.outbox.sent[1].Subject)
+
+		err = f.account.ResetPassword(ctx, raw, "another password")
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidOneTimeToken), "got %v", err)
+	})
+
+	t.Run("UnknownEmail", func(t *testing.T) {
+		f := newAccountFixture(t)
+
+		assert.NoError(t, f.account.RequestPasswordReset(context.Background(), "nobody@gmail.com"))
+		assert.Empty(t, f.outbox.sent)
+	})
+
+	t.Run("AnswersBeforeMailing", func(t *testing.T) {
+		f := newAccountFixture(t)
+		f.later.held = true
+		ctx, cancel := context.WithCancel(context.Background())
+
+		require.NoError(t, f.account.RequestPasswordReset(ctx, "ali@gmail.com"))
+		cancel()
+		assert.Empty(t, f.outbox.sent, "the user is looked up after answering")
+
+		require.Len(t, f.later.pending, 1)
+		f.later.pending[0]()
+		require.Len(t, f.outbox.sent, 1, "the answer doesn't cancel the mail")
+		assert.Equal(t, "ali@gmail.com", f.outbox.sent[0].To)
+	})
+
+	t.Run("TooManyPending", func(t *testing.T) {
+		f := newAccountFixture(t)
+		f.later.held = true
+
+		require.NoError(t, f.account.RequestPasswordReset(context.Background(), "ali@gmail.com"))
+		require.NoError(t, f.account.RequestPasswordReset(context.Background(), "nobody@gmail.com"))
+		require.Len(t, f.later.pending, 1, "the second request finds the only slot taken")
+		assert.Contains(t, f.logs.String(), "too many pending")
+
+		f.later.pending[0]()
+		f.later.held = false
+		require.NoError(t, f.account.RequestPasswordReset(context.Background(), "ali@gmail.com"))
+		assert.Len(t, f.outbox.sent, 2, "the slot is free again once the mail went out")
+	})
+
+	t.Run("LimitPerAddress", func(t *testing.T) {
+		f := newAccountFixture(t)
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, f.account.RequestPasswordReset(context.Background(), "ali@gmail.com"))
+		}
+		assert.Len(t, f.outbox.sent, 3)
+		assert.Contains(t, f.logs.String(), "limit of the address reached")
+
+		require.NoError(t, f.account.RequestPasswordReset(context.Background(), "ALI@gmail.com"))
+		assert.Len(t, f.outbox.sent, 3, "the case of the address doesn't reset the limit")
+
+		f.clock.now = f.clock.now.Add(time.Hour)
+		require.NoError(t, f.account.RequestPasswordReset(context.Background(), "ali@gmail.com"))
+		assert.Len(t, f.outbox.sent, 4, "the limit lasts one window")
+	})
+
+	t.Run("MailerFails", func(t *testing.T) {
+		f := newAccountFixture(t)
+		f.outbox.err = errors.New("connection refused")
+
+		assert.NoError(t, f.account.RequestPasswordReset(context.Background(), "ali@gmail.com"))
+		assert.Contains(t, f.logs.String(), "password reset mail failed")
+		assert.Contains(t, f.logs.String(), "connection refused")
+	})
+
+	t.Run("EmailChanged", func(t *testing.T) {
+		f := newAccountFixture(t)
+
+		require.NoError(t, f.account.RequestPasswordReset(context.Background(), "ali@gmail.com"))
+
+		f.user.Email = "new@gmail.com"
+		_, err := f.users.Update(context.Background(), f.user)
+		require.NoError(t, err)
+
+		err = f.account.ResetPassword(context.Background(), f.outbox.token(t), "new password")
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidOneTimeToken), "a link to the old address resets nothing, got %v", err)
+		assert.True(t, errors.Is(err, repository.ErrMsgEmailChanged), "got %v", err)
+
+		user, err := f.users.FindByID(context.Background(), f.user.ID)
+		require.NoError(t, err)
+		assert.NoError(t, password.Compare(user.PasswordHash, "old password"))
+	})
+
+	t.Run("WrongPurpose", func(t *testing.T) {
+		f := newAccountFixture(t)
+
+		require.NoError(t, f.account.RequestEmailVerification(f.caller, f.user.ID))
+
+		err := f.account.ResetPassword(context.Background(), f.outbox.token(t), "new password")
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidOneTimeToken), "got %v", err)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		f := newAccountFixture(t)
+
+		require.NoError(t, f.account.RequestPasswordReset(context.Background(), "ali@gmail.com"))
+		f.clock.now = f.clock.now.Add(2 * time.Hour)
+
+		err := f.account.ResetPassword(context.Background(), f.outbox.token(t), "new password")
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidOneTimeToken), "got %v", err)
+
+		user, err := f.users.FindByID(context.Background(), f.user.ID)
+		require.NoError(t, err)
+		assert.NoError(t, password.Compare(user.PasswordHash, "old password"))
+	})
+
+	t.Run("PasswordTooLong", func(t *testing.T) {
+		f := newAccountFixture(t)
+
+		require.NoError(t, f.account.RequestPasswordReset(context.Background(), "ali@gmail.com"))
+
+		err := f.account.ResetPassword(context.Background(), f.outbox.token(t), strings.Repeat("رمز", 25))
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidPassword), "got %v", err)
+
+		assert.NoError(t, f.account.ResetPassword(context.Background(), f.outbox.token(t), "new password"), "the token is left for a shorter password")
+	})
+
+	t.Run("TokenCheckedFirst", func(t *testing.T) {
+		f := newAccountFixture(t)
+
+		err := f.account.ResetPassword(context.Background(), "forged", strings.Repeat("رمز", 25))
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidOneTimeToken), "the password isn't hashed without a good token, got %v", err)
+	})
+}
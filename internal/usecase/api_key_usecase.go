@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/token"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+)
+
+// Definition Error Message
+var (
+	ErrMsgInvalidAPIKey     = errors.New("invalid, expired or revoked api key")
+	ErrMsgAPIKeyNotFound    = errors.New("api key not found")
+	ErrMsgInvalidAPIKeySpec = errors.New("invalid api key")
+)
+
+// lastUsedResolution is how stale last_used_at may get, so a busy key isn't
+// written on every request.
+const lastUsedResolution = time.Minute
+
+// Definition Interface (Rules)
+type APIKeyUsecase interface {
+	Create(ctx context.Context, serviceAccount string, scopes entities.Scopes, expiresAt *time.Time) (entities.APIKey, string, error)
+	List(ctx context.Context) ([]entities.APIKey, error)
+	Revoke(ctx context.Context, id uint64) error
+	Authenticate(ctx context.Context, raw string) (entities.Principal, error)
+}
+
+// Definition Struct (Class)
+type apiKeyUsecase struct {
+	repo       repository.APIKeyRepository
+	authorizer Authorizer
+	clock      clock.Clock
+}
+
+// Definition Constructor
+// Creating, listing and revoking keys needs the apikeys:manage permission.
+func NewAPIKeyUsecase(repo repository.APIKeyRepository, authorizer Authorizer, clock clock.Clock) APIKeyUsecase {
+	return &apiKeyUsecase{
+		repo:       repo,
+		authorizer: authorizer,
+		clock:      clock,
+	}
+}
+
+// Definition Implement Methods (Create, List, Revoke, Authenticate)
+// Create issues a key for serviceAccount. The returned string is the key
+// itself, the only time it is available; only its hash is stored. A nil
+// expiresAt makes a key that never expires.
+func (u *apiKeyUsecase) Create(ctx context.Context, serviceAccount string, scopes entities.Scopes, expiresAt *time.Time) (entities.APIKey, string, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermAPIKeysManage, 0)
+	if err != nil {
+		return entities.APIKey{}, "", err
+	}
+
+	serviceAccount = strings.TrimSpace(serviceAccount)
+	if serviceAccount == "" {
+		return entities.APIKey{}, "", fmt.Errorf("%w: a service account is required", ErrMsgInvalidAPIKeySpec)
+	}
+
+	if len(scopes) == 0 {
+		return entities.APIKey{}, "", fmt.Errorf("%w: at least one scope is required", ErrMsgInvalidAPIKeySpec)
+	}
+
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return entities.APIKey{}, "", fmt.Errorf("%w: unknown scope %q", ErrMsgInvalidAPIKeySpec, scope)
+		}
+	}
+
+	if expiresAt != nil && !expiresAt.After(u.clock.Now()) {
+		return entities.APIKey{}, "", fmt.Errorf("%w: the expiry is in the past", ErrMsgInvalidAPIKeySpec)
+	}
+
+	raw, prefix, err := token.NewAPIKey()
+	if err != nil {
+		return entities.APIKey{}, "", fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	key, err := u.repo.Create(ctx, entities.APIKey{
+		ServiceAccount: serviceAccount,
+		Prefix:         prefix,
+		KeyHash:        token.Hash(raw),
+		Scopes:         scopes,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		return entities.APIKey{}, "", fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return key, raw, nil
+}
+
+// Definition Implement Methods (Create, List, Revoke, Authenticate)
+func (u *apiKeyUsecase) List(ctx context.Context) ([]entities.APIKey, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermAPIKeysManage, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := u.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return keys, nil
+}
+
+// Definition Implement Methods (Create, List, Revoke, Authenticate)
+func (u *apiKeyUsecase) Revoke(ctx context.Context, id uint64) error {
+	err := u.authorizer.Authorize(ctx, entities.PermAPIKeysManage, 0)
+	if err != nil {
+		return err
+	}
+
+	err = u.repo.Revoke(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgAPIKeyNotFound) {
+			return fmt.Errorf("%w:%w", ErrMsgAPIKeyNotFound, err)
+		}
+
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (Create, List, Revoke, Authenticate)
+// Authenticate returns the principal of the service account owning the key
+// raw. Unknown, expired and revoked keys all fail with ErrMsgInvalidAPIKey.
+func (u *apiKeyUsecase) Authenticate(ctx context.Context, raw string) (entities.Principal, error) {
+	prefix, ok := token.APIKeyPrefix(raw)
+	if !ok {
+		return entities.Principal{}, ErrMsgInvalidAPIKey
+	}
+
+	key, err := u.repo.FindByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgAPIKeyNotFound) {
+			return entities.Principal{}, fmt.Errorf("%w:%w", ErrMsgInvalidAPIKey, err)
+		}
+
+		return entities.Principal{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(token.Hash(raw))) != 1 {
+		return entities.Principal{}, ErrMsgInvalidAPIKey
+	}
+
+	now := u.clock.Now()
+	if !key.Active(now) {
+		return entities.Principal{}, ErrMsgInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		// tracking usage must not fail the request
+		_ = u.repo.Touch(ctx, key.ID, now)
+	}
+
+	return entities.Principal{
+		APIKeyID:       key.ID,
+		ServiceAccount: key.ServiceAccount,
+		Scopes:         key.Scopes,
+	}, nil
+}
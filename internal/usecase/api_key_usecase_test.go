@@ -0,0 +1,161 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type apiKeyFixture struct {
+	keys  usecase.APIKeyUsecase
+	repo  repository.APIKeyRepository
+	clock *movingClock
+	admin context.Context
+}
+
+// newAPIKeyFixture wires the API key usecase to memory repositories, with
+// admin carrying a principal allowed to manage keys.
+func newAPIKeyFixture(t *testing.T) apiKeyFixture {
+	t.Helper()
+
+	ctx := context.Background()
+	clk := &movingClock{now: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}
+	roles := repository.NewMemoryRoleRepository(clk)
+	require.NoError(t, roles.Assign(ctx, 1, entities.RoleAdmin))
+	repo := repository.NewMemoryAPIKeyRepository(clk)
+
+	return apiKeyFixture{
+		keys:  usecase.NewAPIKeyUsecase(repo, usecase.NewAuthorizer(roles), clk),
+		repo:  repo,
+		clock: clk,
+		admin: principal.WithPrincipal(ctx, entities.Principal{UserID: 1}),
+	}
+}
+
+func TestAPIKeyUsecase_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		f := newAPIKeyFixture(t)
+
+		key, raw, err := f.keys.Create(f.admin, " billing ", entities.Scopes{entities.ScopeUsersRead}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "billing", key.ServiceAccount)
+		assert.Contains(t, raw, key.Prefix)
+		assert.NotContains(t, key.KeyHash, raw, "only the hash is stored")
+		assert.Nil(t, key.ExpiresAt)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		f := newAPIKeyFixture(t)
+		past := f.clock.now.Add(-time.Hour)
+
+		cases := map[string]struct {
+			serviceAccount string
+			scopes         entities.Scopes
+			expiresAt      *time.Time
+		}{
+			"NoServiceAccount": {serviceAccount: " ", scopes: entities.Scopes{entities.ScopeUsersRead}},
+			"NoScopes":         {serviceAccount: "billing"},
+			"UnknownScope":     {serviceAccount: "billing", scopes: entities.Scopes{"users:admin"}},
+			"Expired":          {serviceAccount: "billing", scopes: entities.Scopes{entities.ScopeUsersRead}, expiresAt: &past},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				_, _, err := f.keys.Create(f.admin, tc.serviceAccount, tc.scopes, tc.expiresAt)
+				assert.True(t, errors.Is(err, usecase.ErrMsgInvalidAPIKeySpec), "got %v", err)
+			})
+		}
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		f := newAPIKeyFixture(t)
+		caller := principal.WithPrincipal(context.Background(), entities.Principal{UserID: 2})
+
+		_, _, err := f.keys.Create(caller, "billing", entities.Scopes{entities.ScopeUsersRead}, nil)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		_, err = f.keys.List(caller)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+	})
+}
+
+func TestAPIKeyUsecase_Authenticate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		f := newAPIKeyFixture(t)
+		key, raw, err := f.keys.Create(f.admin, "billing", entities.Scopes{entities.ScopeUsersRead, entities.ScopeUsersWrite}, nil)
+		require.NoError(t, err)
+
+		caller, err := f.keys.Authenticate(ctx, raw)
+		require.NoError(t, err)
+		assert.Equal(t, key.ID, caller.APIKeyID)
+		assert.Equal(t, "service:billing", caller.Actor())
+		assert.Equal(t, key.Scopes, caller.Scopes)
+
+		stored, err := f.repo.FindByPrefix(ctx, key.Prefix)
+		require.NoError(t, err)
+		require.NotNil(t, stored.LastUsedAt)
+		assert.Equal(t, f.clock.now, *stored.LastUsedAt)
+	})
+
+	t.Run("LastUsedResolution", func(t *testing.T) {
+		f := newAPIKeyFixture(t)
+		key, raw, err := f.keys.Create(f.admin, "billing", entities.Scopes{entities.ScopeUsersRead}, nil)
+		require.NoError(t, err)
+		first := f.clock.now
+
+		_, err = f.keys.Authenticate(ctx, raw)
+		require.NoError(t, err)
+
+		f.clock.now = first.Add(10 * time.Second)
+		_, err = f.keys.Authenticate(ctx, raw)
+		require.NoError(t, err)
+
+		stored, err := f.repo.FindByPrefix(ctx, key.Prefix)
+		require.NoError(t, err)
+		assert.Equal(t, first, *stored.LastUsedAt, "recent use is not written again")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		f := newAPIKeyFixture(t)
+		_, raw, err := f.keys.Create(f.admin, "billing", entities.Scopes{entities.ScopeUsersRead}, nil)
+		require.NoError(t, err)
+
+		for _, candidate := range []string{"", "not-a-key", raw + "x", raw[:len(raw)-1] + "?"} {
+			_, err := f.keys.Authenticate(ctx, candidate)
+			assert.True(t, errors.Is(err, usecase.ErrMsgInvalidAPIKey), "%q: got %v", candidate, err)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		f := newAPIKeyFixture(t)
+		expiresAt := f.clock.now.Add(time.Hour)
+		_, raw, err := f.keys.Create(f.admin, "billing", entities.Scopes{entities.ScopeUsersRead}, &expiresAt)
+		require.NoError(t, err)
+
+		f.clock.now = expiresAt
+		_, err = f.keys.Authenticate(ctx, raw)
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidAPIKey), "got %v", err)
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		f := newAPIKeyFixture(t)
+		key, raw, err := f.keys.Create(f.admin, "billing", entities.Scopes{entities.ScopeUsersRead}, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, f.keys.Revoke(f.admin, key.ID))
+		_, err = f.keys.Authenticate(ctx, raw)
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidAPIKey), "got %v", err)
+
+		err = f.keys.Revoke(f.admin, key.ID+1)
+		assert.True(t, errors.Is(err, usecase.ErrMsgAPIKeyNotFound), "got %v", err)
+	})
+}
@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+)
+
+// Definition Interface (Rules)
+type AuditUsecase interface {
+	List(ctx context.Context, query entities.AuditQuery) (entities.AuditPage, error)
+}
+
+// Definition Struct (Class)
+type auditUsecase struct {
+	audit      repository.AuditRepository
+	authorizer Authorizer
+}
+
+// Definition Constructor
+// Reading the audit log needs the audit:read permission. Events are written
+// by the usecases making the changes, not through this one.
+func NewAuditUsecase(audit repository.AuditRepository, authorizer Authorizer) AuditUsecase {
+	return &auditUsecase{
+		audit:      audit,
+		authorizer: authorizer,
+	}
+}
+
+// Definition Implement Methods (List)
+func (u *auditUsecase) List(ctx context.Context, query entities.AuditQuery) (entities.AuditPage, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermAuditRead, 0)
+	if err != nil {
+		return entities.AuditPage{}, err
+	}
+
+	page, err := u.audit.List(ctx, query.Normalize())
+	if err != nil {
+		return entities.AuditPage{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,51 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditUsecase_List(t *testing.T) {
+	ctx := context.Background()
+	now := clock.New()
+
+	roles := repository.NewMemoryRoleRepository(now)
+	audit := repository.NewMemoryAuditRepository(now)
+	auditUsecase := usecase.NewAuditUsecase(audit, usecase.NewAuthorizer(roles))
+
+	for userID := uint64(1); userID <= 3; userID++ {
+		_, err := audit.Record(ctx, entities.AuditEvent{Action: entities.AuditUserCreated, UserID: userID})
+		require.NoError(t, err)
+	}
+	require.NoError(t, roles.Assign(ctx, 1, entities.RoleAdmin))
+
+	t.Run("Admin", func(t *testing.T) {
+		userID := uint64(2)
+		page, err := auditUsecase.List(principal.WithPrincipal(ctx, entities.Principal{UserID: 1}), entities.AuditQuery{UserID: &userID})
+
+		require.NoError(t, err)
+		require.Len(t, page.Events, 1)
+		assert.Equal(t, userID, page.Events[0].UserID)
+	})
+
+	t.Run("OwnEventsForbidden", func(t *testing.T) {
+		userID := uint64(2)
+		_, err := auditUsecase.List(principal.WithPrincipal(ctx, entities.Principal{UserID: 2}), entities.AuditQuery{UserID: &userID})
+
+		assert.ErrorIs(t, err, usecase.ErrMsgForbidden, "the audit log is for reviewers, not the users in it")
+	})
+
+	t.Run("Anonymous", func(t *testing.T) {
+		_, err := auditUsecase.List(ctx, entities.AuditQuery{})
+
+		assert.ErrorIs(t, err, usecase.ErrMsgUnauthenticated)
+	})
+}
@@ -0,0 +1,461 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/password"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/token"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+)
+
+// Definition Error Message
+var (
+	ErrMsgInvalidCredentials  = errors.New("invalid email or password")
+	ErrMsgInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrMsgRefreshTokenReused  = errors.New("refresh token was reused, the session is revoked")
+	ErrMsgSessionNotFound     = errors.New("session not found")
+	ErrMsgForbidden           = errors.New("forbidden")
+	ErrMsgTooManyAttempts     = errors.New("too many failed attempts")
+)
+
+// LockedError is returned while a user or a client IP is locked out after
+// too many failed credential checks. It matches ErrMsgTooManyAttempts.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrMsgTooManyAttempts, e.RetryAfter)
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrMsgTooManyAttempts
+}
+
+// LockoutOptions configures how failed credential checks are throttled.
+type LockoutOptions struct {
+	// Threshold failed checks of one user within Window lock the user, 0
+	// never does.
+	Threshold int
+	// IPThreshold failed checks from one client IP within Window lock out
+	// the IP, whichever users were tried, 0 never does.
+	IPThreshold int
+	Window      time.Duration
+	// The first lock lasts BaseDuration and every further one twice as long
+	// as the one before, up to MaxDuration.
+	BaseDuration time.Duration
+	MaxDuration  time.Duration
+}
+
+// duration is how long a subject locked lockouts times before is locked.
+func (o LockoutOptions) duration(lockouts int) time.Duration {
+	d := o.BaseDuration
+	for i := 0; i < lockouts && d < o.MaxDuration; i++ {
+		d *= 2
+	}
+
+	return min(d, o.MaxDuration)
+}
+
+// Definition Struct (Class)
+// throttle locks subjects out after too many failed checks within the
+// window of options.
+type throttle struct {
+	lockouts repository.LockoutRepository
+	options  LockoutOptions
+}
+
+// check fails with a LockedError while key is locked at now.
+func (t throttle) check(ctx context.Context, key string, now time.Time) error {
+	lockout, err := t.lockouts.Find(ctx, key)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgLockoutNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	if lockout.Locked(now) {
+		return &LockedError{RetryAfter: lockout.LockedUntil.Sub(now)}
+	}
+
+	return nil
+}
+
+// fail counts a failed check of key and locks it once threshold failures
+// fell within the window.
+func (t throttle) fail(ctx context.Context, key string, threshold int, now time.Time) error {
+	if threshold <= 0 {
+		return nil
+	}
+
+	lockout, err := t.lockouts.RecordFailure(ctx, key, now.Add(-t.options.Window))
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	if lockout.Failures < threshold {
+		return nil
+	}
+
+	err = t.lockouts.Lock(ctx, key, now.Add(t.options.duration(lockout.Lockouts)))
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
+
+// Definition Interface (Rules)
+type AuthUsecase interface {
+	Login(ctx context.Context, email string, password string, client entities.SessionClient) (entities.AuthToken, error)
+	Refresh(ctx context.Context, refreshToken string) (entities.AuthToken, error)
+	Logout(ctx context.Context, sessionID uint64) error
+	LogoutAll(ctx context.Context, userID uint64) (int64, error)
+	Sessions(ctx context.Context, userID uint64) ([]entities.Session, error)
+	VerifyPassword(ctx context.Context, email string, password string, clientIP string) (entities.User, error)
+	LockStatus(ctx context.Context, userID uint64) (entities.LockStatus, error)
+	Unlock(ctx context.Context, userID uint64) error
+}
+
+// Definition Struct (Class)
+type authUsecase struct {
+	repo       repository.UserRepository
+	sessions   repository.SessionRepository
+	lockouts   repository.LockoutRepository
+	authorizer Authorizer
+	tokens     token.Manager
+	clock      clock.Clock
+	refreshTTL time.Duration
+	lockout    LockoutOptions
+	throttle   throttle
+}
+
+// Definition Constructor
+// Sessions stay alive for refreshTTL after their last refresh. Login and
+// VerifyPassword share the lockout of users and client IPs.
+func NewAuthUsecase(repo repository.UserRepository, sessions repository.SessionRepository, lockouts repository.LockoutRepository, authorizer Authorizer, tokens token.Manager, clock clock.Clock, refreshTTL time.Duration, lockout LockoutOptions) AuthUsecase {
+	return &authUsecase{
+		repo:       repo,
+		sessions:   sessions,
+		lockouts:   lockouts,
+		authorizer: authorizer,
+		tokens:     tokens,
+		clock:      clock,
+		refreshTTL: refreshTTL,
+		lockout:    lockout,
+		throttle:   throttle{lockouts: lockouts, options: lockout},
+	}
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+// Login starts a session for the active user owning email. An unknown email,
+// a user without a password and a wrong password all fail the same way, and
+// take about as long, so callers can't probe for accounts.
+func (u *authUsecase) Login(ctx context.Context, email string, plain string, client entities.SessionClient) (entities.AuthToken, error) {
+	user, err := u.checkCredentials(ctx, email, plain, client.IPAddress)
+	if err != nil {
+		return entities.AuthToken{}, err
+	}
+
+	refreshToken, err := token.NewOpaque()
+	if err != nil {
+		return entities.AuthToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	session, err := u.sessions.Create(ctx, entities.Session{
+		UserID:    user.ID,
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+		ExpiresAt: u.clock.Now().Add(u.refreshTTL),
+	}, token.Hash(refreshToken))
+	if err != nil {
+		return entities.AuthToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return u.issue(user, session.ID, refreshToken, session.ExpiresAt)
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+// Refresh exchanges a refresh token for a new access and refresh token. Each
+// refresh token works once: presenting one that was already exchanged means
+// it leaked, so the whole session is revoked and every token of it, the
+// latest one included, stops working.
+func (u *authUsecase) Refresh(ctx context.Context, refreshToken string) (entities.AuthToken, error) {
+	session, current, err := u.sessions.FindByTokenHash(ctx, token.Hash(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgSessionNotFound) {
+			return entities.AuthToken{}, fmt.Errorf("%w:%w", ErrMsgInvalidRefreshToken, err)
+		}
+
+		return entities.AuthToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	if current.RotatedAt != nil {
+		return entities.AuthToken{}, u.revokeReused(ctx, session)
+	}
+
+	now := u.clock.Now()
+	if !session.Active(now) {
+		return entities.AuthToken{}, ErrMsgInvalidRefreshToken
+	}
+
+	user, err := u.repo.FindByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgUserNotFound) {
+			_ = u.sessions.Revoke(ctx, session.ID, entities.RevokeReasonUserGone)
+			return entities.AuthToken{}, fmt.Errorf("%w:%w", ErrMsgInvalidRefreshToken, err)
+		}
+
+		return entities.AuthToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	next, err := token.NewOpaque()
+	if err != nil {
+		return entities.AuthToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	expiresAt := now.Add(u.refreshTTL)
+	err = u.sessions.Rotate(ctx, current, token.Hash(next), expiresAt)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgTokenRotated) {
+			// a concurrent refresh with the same token won the race
+			return entities.AuthToken{}, u.revokeReused(ctx, session)
+		}
+
+		return entities.AuthToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return u.issue(user, session.ID, next, expiresAt)
+}
+
+// revokeReused revokes the session of a reused refresh token.
+func (u *authUsecase) revokeReused(ctx context.Context, session entities.Session) error {
+	err := u.sessions.Revoke(ctx, session.ID, entities.RevokeReasonReuse)
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return ErrMsgRefreshTokenReused
+}
+
+// issue signs an access token for user in session.
+func (u *authUsecase) issue(user entities.User, sessionID uint64, refreshToken string, refreshExpiresAt time.Time) (entities.AuthToken, error) {
+	issued, err := u.tokens.Issue(entities.Principal{UserID: user.ID, Email: user.Email, SessionID: sessionID})
+	if err != nil {
+		return entities.AuthToken{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	issued.RefreshToken = refreshToken
+	issued.RefreshExpiresAt = refreshExpiresAt
+
+	return issued, nil
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+// Logout revokes one session, which needs users:update on its owner. Access
+// tokens already issued for it stay valid until they expire.
+func (u *authUsecase) Logout(ctx context.Context, sessionID uint64) error {
+	session, err := u.sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgSessionNotFound) {
+			return fmt.Errorf("%w:%w", ErrMsgSessionNotFound, err)
+		}
+
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	err = u.authorizer.Authorize(ctx, entities.PermUsersUpdate, session.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = u.sessions.Revoke(ctx, sessionID, entities.RevokeReasonLogout)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgSessionNotFound) {
+			return fmt.Errorf("%w:%w", ErrMsgSessionNotFound, err)
+		}
+
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+// LogoutAll revokes every session of userID, which needs users:update on it.
+func (u *authUsecase) LogoutAll(ctx context.Context, userID uint64) (int64, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersUpdate, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked, err := u.sessions.RevokeAll(ctx, userID, entities.RevokeReasonLogoutAll)
+	if err != nil {
+		return 0, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return revoked, nil
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+// Sessions lists the open sessions of userID.
+func (u *authUsecase) Sessions(ctx context.Context, userID uint64) ([]entities.Session, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermSessionsRead, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := u.sessions.ListActive(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return sessions, nil
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+// VerifyPassword checks the password of the user owning email for another
+// service, counting failures like Login does. clientIP is the address the
+// user connected from.
+func (u *authUsecase) VerifyPassword(ctx context.Context, email string, plain string, clientIP string) (entities.User, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermCredentialsVerify, 0)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	return u.checkCredentials(ctx, email, plain, clientIP)
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+func (u *authUsecase) LockStatus(ctx context.Context, userID uint64) (entities.LockStatus, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersRead, userID)
+	if err != nil {
+		return entities.LockStatus{}, err
+	}
+
+	err = u.userExists(ctx, userID)
+	if err != nil {
+		return entities.LockStatus{}, err
+	}
+
+	lockout, err := u.lockouts.Find(ctx, entities.LockoutKeyUser(userID))
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgLockoutNotFound) {
+			return entities.LockStatus{}, nil
+		}
+
+		return entities.LockStatus{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	now := u.clock.Now()
+	status := entities.LockStatus{Locked: lockout.Locked(now), Lockouts: lockout.Lockouts}
+	if status.Locked {
+		status.LockedUntil = lockout.LockedUntil
+	}
+	if !lockout.LastFailureAt.Before(now.Add(-u.lockout.Window)) {
+		status.FailedAttempts = lockout.Failures
+	}
+
+	return status, nil
+}
+
+// Definition Implement Methods (Login, Refresh, Logout, LogoutAll, Sessions)
+// Unlock lifts the lock of a user, of their password and of their mfa
+// codes, and forgets their failures, so the next lock starts at the shortest
+// duration again.
+func (u *authUsecase) Unlock(ctx context.Context, userID uint64) error {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersUnlock, userID)
+	if err != nil {
+		return err
+	}
+
+	err = u.userExists(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range []string{entities.LockoutKeyUser(userID), entities.LockoutKeyMFA(userID)} {
+		err = u.lockouts.Reset(ctx, key)
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+	}
+
+	return nil
+}
+
+// checkCredentials returns the user owning email if plain is their password.
+// A locked user or client IP fails with a LockedError before the password is
+// looked at. A wrong password counts against both; a right one forgets the
+// failures of the user, not of the IP, so one known password can't be used
+// to keep guessing others from the same address.
+func (u *authUsecase) checkCredentials(ctx context.Context, email string, plain string, clientIP string) (entities.User, error) {
+	now := u.clock.Now()
+
+	if clientIP != "" {
+		err := u.throttle.check(ctx, entities.LockoutKeyIP(clientIP), now)
+		if err != nil {
+			return entities.User{}, err
+		}
+	}
+
+	user, err := u.repo.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, repository.ErrMsgUserNotFound) {
+		return entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+	found := err == nil
+
+	if found {
+		err = u.throttle.check(ctx, entities.LockoutKeyUser(user.ID), now)
+		if err != nil {
+			return entities.User{}, err
+		}
+	}
+
+	mismatch := password.Compare(user.PasswordHash, plain)
+	if mismatch != nil {
+		if clientIP != "" {
+			err = u.throttle.fail(ctx, entities.LockoutKeyIP(clientIP), u.lockout.IPThreshold, now)
+			if err != nil {
+				return entities.User{}, err
+			}
+		}
+
+		if found {
+			err = u.throttle.fail(ctx, entities.LockoutKeyUser(user.ID), u.lockout.Threshold, now)
+			if err != nil {
+				return entities.User{}, err
+			}
+		}
+
+		return entities.User{}, fmt.Errorf("%w:%w", ErrMsgInvalidCredentials, mismatch)
+	}
+
+	err = u.lockouts.Reset(ctx, entities.LockoutKeyUser(user.ID))
+	if err != nil {
+		return entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return user, nil
+}
+
+// userExists fails with ErrMsgUserNotFound unless userID is an active user.
+func (u *authUsecase) userExists(ctx context.Context, userID uint64) error {
+	_, err := u.repo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgUserNotFound) {
+			return fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
+		}
+
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
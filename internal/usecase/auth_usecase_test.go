@@ -0,0 +1,426 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/token"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// movingClock is a Clock tests can move forward.
+type movingClock struct {
+	now time.Time
+}
+
+func (c *movingClock) Now() time.Time {
+	return c.now
+}
+
+type authFixture struct {
+	auth     usecase.AuthUsecase
+	users    usecase.UserUsecase
+	sessions repository.SessionRepository
+	roles    repository.RoleRepository
+	lockouts repository.LockoutRepository
+	tokens   token.Manager
+	clock    *movingClock
+	user     entities.User
+}
+
+// lockoutOptions lock a user after 3 failures and an IP after 5, for one
+// minute at first and at most 4.
+var lockoutOptions = usecase.LockoutOptions{Threshold: 3, IPThreshold: 5, Window: 15 * time.Minute, BaseDuration: time.Minute, MaxDuration: 4 * time.Minute}
+
+// newAuthFixture wires the auth usecase to memory repositories holding one
+// user with the password "correct horse" and one without a password.
+func newAuthFixture(t *testing.T) authFixture {
+	t.Helper()
+
+	ctx := context.Background()
+	now := &movingClock{now: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}
+
+	repo := repository.NewMemoryUserRepository(now)
+	sessions := repository.NewMemorySessionRepository(now)
+	roles := repository.NewMemoryRoleRepository(now)
+	lockouts := repository.NewMemoryLockoutRepository(now)
+	tokens := token.NewManager(token.Options{Secret: "test-secret", Issuer: "user-manager", TTL: time.Minute}, now)
+	users := usecase.NewUserUsecase(repo, repository.NewMemoryAuditRepository(now), repository.NewMemoryTxManager(), usecase.AllowAll(), now, validator.New())
+
+	user, err := users.Create(ctx, entities.User{Name: "Ali", Family: "Ahmadi", Email: "ali@gmail.com", Age: 30, Password: "correct horse"})
+	require.NoError(t, err)
+
+	_, err = users.Create(ctx, entities.User{Name: "Sara", Family: "Karimi", Email: "sara@gmail.com", Age: 25})
+	require.NoError(t, err)
+
+	return authFixture{
+		auth:     usecase.NewAuthUsecase(repo, sessions, lockouts, usecase.NewAuthorizer(roles), tokens, now, time.Hour, lockoutOptions),
+		users:    users,
+		sessions: sessions,
+		roles:    roles,
+		lockouts: lockouts,
+		tokens:   tokens,
+		clock:    now,
+		user:     user,
+	}
+}
+
+func TestAuthUsecase_Login(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthFixture(t)
+
+	assert.NotEmpty(t, f.user.PasswordHash)
+	assert.NotEqual(t, "correct horse", f.user.PasswordHash)
+	assert.Empty(t, f.user.Password)
+
+	t.Run("Success", func(t *testing.T) {
+		issued, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{UserAgent: "curl/8.0", IPAddress: "10.0.0.1"})
+		require.NoError(t, err)
+		assert.NotEmpty(t, issued.RefreshToken)
+		assert.Equal(t, f.clock.now.Add(time.Hour), issued.RefreshExpiresAt)
+
+		caller, err := f.tokens.Parse(issued.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, f.user.ID, caller.UserID)
+		assert.NotZero(t, caller.SessionID)
+
+		sessions, err := f.sessions.ListActive(ctx, f.user.ID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, caller.SessionID, sessions[0].ID)
+		assert.Equal(t, "curl/8.0", sessions[0].UserAgent)
+		assert.Equal(t, "10.0.0.1", sessions[0].IPAddress)
+	})
+
+	t.Run("WrongPassword", func(t *testing.T) {
+		_, err := f.auth.Login(ctx, "ali@gmail.com", "wrong", entities.SessionClient{})
+
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidCredentials))
+	})
+
+	t.Run("UnknownEmail", func(t *testing.T) {
+		_, err := f.auth.Login(ctx, "nobody@gmail.com", "correct horse", entities.SessionClient{})
+
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidCredentials))
+	})
+
+	t.Run("UserWithoutPassword", func(t *testing.T) {
+		_, err := f.auth.Login(ctx, "sara@gmail.com", "", entities.SessionClient{})
+
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidCredentials))
+	})
+}
+
+func TestAuthUsecase_Refresh(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Rotates", func(t *testing.T) {
+		f := newAuthFixture(t)
+		first, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{})
+		require.NoError(t, err)
+
+		f.clock.now = f.clock.now.Add(30 * time.Minute)
+		second, err := f.auth.Refresh(ctx, first.RefreshToken)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+		assert.Equal(t, f.clock.now.Add(time.Hour), second.RefreshExpiresAt, "every refresh extends the session")
+
+		caller, err := f.tokens.Parse(second.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, f.user.ID, caller.UserID)
+
+		f.clock.now = f.clock.now.Add(45 * time.Minute)
+		_, err = f.auth.Refresh(ctx, second.RefreshToken)
+		assert.NoError(t, err, "an hour and a quarter after login the extended session is still open")
+	})
+
+	t.Run("ReuseRevokesTheFamily", func(t *testing.T) {
+		f := newAuthFixture(t)
+		first, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{})
+		require.NoError(t, err)
+		other, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{})
+		require.NoError(t, err)
+
+		second, err := f.auth.Refresh(ctx, first.RefreshToken)
+		require.NoError(t, err)
+
+		_, err = f.auth.Refresh(ctx, first.RefreshToken)
+		assert.True(t, errors.Is(err, usecase.ErrMsgRefreshTokenReused), "got %v", err)
+
+		_, err = f.auth.Refresh(ctx, second.RefreshToken)
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidRefreshToken), "the latest token of the family is revoked too, got %v", err)
+
+		_, err = f.auth.Refresh(ctx, other.RefreshToken)
+		assert.NoError(t, err, "other sessions are untouched")
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		f := newAuthFixture(t)
+		issued, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{})
+		require.NoError(t, err)
+
+		f.clock.now = f.clock.now.Add(time.Hour + time.Second)
+		_, err = f.auth.Refresh(ctx, issued.RefreshToken)
+
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidRefreshToken), "got %v", err)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		f := newAuthFixture(t)
+
+		_, err := f.auth.Refresh(ctx, "made-up")
+
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidRefreshToken), "got %v", err)
+	})
+
+	t.Run("DeletedUser", func(t *testing.T) {
+		f := newAuthFixture(t)
+		issued, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{})
+		require.NoError(t, err)
+		require.NoError(t, f.users.Delete(ctx, f.user.ID))
+
+		_, err = f.auth.Refresh(ctx, issued.RefreshToken)
+
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidRefreshToken), "got %v", err)
+	})
+}
+
+func TestAuthUsecase_Logout(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Logout", func(t *testing.T) {
+		f := newAuthFixture(t)
+		issued, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{})
+		require.NoError(t, err)
+		caller, err := f.tokens.Parse(issued.AccessToken)
+		require.NoError(t, err)
+
+		own := principal.WithPrincipal(ctx, caller)
+
+		err = f.auth.Logout(principal.WithPrincipal(ctx, entities.Principal{UserID: f.user.ID + 1}), caller.SessionID)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		require.NoError(t, f.auth.Logout(own, caller.SessionID))
+
+		_, err = f.auth.Refresh(ctx, issued.RefreshToken)
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidRefreshToken), "got %v", err)
+
+		err = f.auth.Logout(own, caller.SessionID+100)
+		assert.True(t, errors.Is(err, usecase.ErrMsgSessionNotFound), "got %v", err)
+	})
+
+	t.Run("LogoutAll", func(t *testing.T) {
+		f := newAuthFixture(t)
+		for i := 0; i < 3; i++ {
+			_, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{})
+			require.NoError(t, err)
+		}
+
+		_, err := f.auth.LogoutAll(principal.WithPrincipal(ctx, entities.Principal{UserID: f.user.ID + 1}), f.user.ID)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		revoked, err := f.auth.LogoutAll(principal.WithPrincipal(ctx, entities.Principal{UserID: f.user.ID}), f.user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), revoked)
+
+		sessions, err := f.sessions.ListActive(ctx, f.user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, sessions)
+	})
+}
+
+func TestAuthUsecase_Sessions(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthFixture(t)
+
+	issued, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{})
+	require.NoError(t, err)
+	caller, err := f.tokens.Parse(issued.AccessToken)
+	require.NoError(t, err)
+
+	t.Run("Own", func(t *testing.T) {
+		sessions, err := f.auth.Sessions(principal.WithPrincipal(ctx, caller), f.user.ID)
+
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1)
+	})
+
+	t.Run("SomeoneElses", func(t *testing.T) {
+		_, err := f.auth.Sessions(principal.WithPrincipal(ctx, entities.Principal{UserID: f.user.ID + 1}), f.user.ID)
+
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden))
+	})
+
+	t.Run("SupportReadsSomeoneElses", func(t *testing.T) {
+		require.NoError(t, f.roles.Assign(ctx, f.user.ID+1, entities.RoleSupport))
+
+		sessions, err := f.auth.Sessions(principal.WithPrincipal(ctx, entities.Principal{UserID: f.user.ID + 1}), f.user.ID)
+
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1)
+	})
+
+	t.Run("System", func(t *testing.T) {
+		sessions, err := f.auth.Sessions(principal.WithPrincipal(ctx, entities.SystemPrincipal), f.user.ID)
+
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1, "the cli lists any user's sessions")
+	})
+
+	t.Run("WithoutPrincipal", func(t *testing.T) {
+		_, err := f.auth.Sessions(ctx, f.user.ID)
+
+		assert.True(t, errors.Is(err, usecase.ErrMsgUnauthenticated))
+	})
+}
+
+func TestAuthUsecase_Lockout(t *testing.T) {
+	ctx := context.Background()
+	client := entities.SessionClient{IPAddress: "10.0.0.1"}
+
+	// fail logs in as ali with a wrong password n times
+	fail := func(t *testing.T, f authFixture, n int, client entities.SessionClient) {
+		t.Helper()
+
+		for i := 0; i < n; i++ {
+			_, err := f.auth.Login(ctx, "ali@gmail.com", "wrong", client)
+			require.True(t, errors.Is(err, usecase.ErrMsgInvalidCredentials), "attempt %d, got %v", i+1, err)
+		}
+	}
+
+	t.Run("LocksUser", func(t *testing.T) {
+		f := newAuthFixture(t)
+		fail(t, f, 3, client)
+
+		_, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{IPAddress: "10.0.0.2"})
+		var locked *usecase.LockedError
+		require.True(t, errors.As(err, &locked), "the right password is refused while locked, got %v", err)
+		assert.True(t, errors.Is(err, usecase.ErrMsgTooManyAttempts))
+		assert.Equal(t, time.Minute, locked.RetryAfter)
+
+		f.clock.now = f.clock.now.Add(time.Minute)
+		_, err = f.auth.Login(ctx, "ali@gmail.com", "correct horse", client)
+		require.NoError(t, err)
+
+		status, err := f.auth.LockStatus(principal.WithPrincipal(ctx, entities.SystemPrincipal), f.user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entities.LockStatus{}, status, "a successful login forgets the failures")
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		f := newAuthFixture(t)
+		var locked *usecase.LockedError
+
+		for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+			fail(t, f, 3, entities.SessionClient{})
+
+			_, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{})
+			require.True(t, errors.As(err, &locked), "got %v", err)
+			assert.Equal(t, want, locked.RetryAfter)
+
+			f.clock.now = f.clock.now.Add(want)
+		}
+
+		status, err := f.auth.LockStatus(principal.WithPrincipal(ctx, entities.SystemPrincipal), f.user.ID)
+		require.NoError(t, err)
+		assert.False(t, status.Locked)
+		assert.Equal(t, 4, status.Lockouts)
+	})
+
+	t.Run("WindowForgetsOldFailures", func(t *testing.T) {
+		f := newAuthFixture(t)
+		fail(t, f, 2, entities.SessionClient{})
+
+		f.clock.now = f.clock.now.Add(16 * time.Minute)
+		fail(t, f, 2, entities.SessionClient{})
+
+		_, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("LocksClientIP", func(t *testing.T) {
+		f := newAuthFixture(t)
+
+		for i := 0; i < 5; i++ {
+			_, err := f.auth.Login(ctx, "nobody@gmail.com", "wrong", client)
+			require.True(t, errors.Is(err, usecase.ErrMsgInvalidCredentials), "got %v", err)
+		}
+
+		_, err := f.auth.Login(ctx, "ali@gmail.com", "correct horse", client)
+		assert.True(t, errors.Is(err, usecase.ErrMsgTooManyAttempts), "got %v", err)
+
+		_, err = f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{IPAddress: "10.0.0.2"})
+		assert.NoError(t, err, "other addresses are not affected")
+	})
+
+	t.Run("StatusAndUnlock", func(t *testing.T) {
+		f := newAuthFixture(t)
+		fail(t, f, 3, entities.SessionClient{})
+
+		own := principal.WithPrincipal(ctx, entities.Principal{UserID: f.user.ID})
+		status, err := f.auth.LockStatus(own, f.user.ID)
+		require.NoError(t, err)
+		assert.True(t, status.Locked)
+		require.NotNil(t, status.LockedUntil)
+		assert.Equal(t, f.clock.now.Add(time.Minute), *status.LockedUntil)
+		assert.Equal(t, 1, status.Lockouts)
+
+		err = f.auth.Unlock(own, f.user.ID)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "users can't unlock themselves, got %v", err)
+
+		_, err = f.lockouts.RecordFailure(ctx, entities.LockoutKeyMFA(f.user.ID), f.clock.now.Add(-time.Hour))
+		require.NoError(t, err)
+
+		support := f.user.ID + 1
+		require.NoError(t, f.roles.Assign(ctx, support, entities.RoleSupport))
+		require.NoError(t, f.auth.Unlock(principal.WithPrincipal(ctx, entities.Principal{UserID: support}), f.user.ID))
+
+		_, err = f.auth.Login(ctx, "ali@gmail.com", "correct horse", entities.SessionClient{})
+		assert.NoError(t, err)
+
+		_, err = f.lockouts.Find(ctx, entities.LockoutKeyMFA(f.user.ID))
+		assert.True(t, errors.Is(err, repository.ErrMsgLockoutNotFound), "the mfa lock is lifted too, got %v", err)
+
+		err = f.auth.Unlock(principal.WithPrincipal(ctx, entities.SystemPrincipal), 999)
+		assert.True(t, errors.Is(err, usecase.ErrMsgUserNotFound), "got %v", err)
+	})
+}
+
+func TestAuthUsecase_VerifyPassword(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthFixture(t)
+	service := principal.WithPrincipal(ctx, entities.Principal{APIKeyID: 1, Scopes: entities.Scopes{entities.ScopeCredentialsVerify}})
+
+	t.Run("Success", func(t *testing.T) {
+		user, err := f.auth.VerifyPassword(service, "ali@gmail.com", "correct horse", "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, f.user.ID, user.ID)
+	})
+
+	t.Run("WrongPassword", func(t *testing.T) {
+		_, err := f.auth.VerifyPassword(service, "ali@gmail.com", "wrong", "10.0.0.1")
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidCredentials), "got %v", err)
+
+		status, err := f.auth.LockStatus(principal.WithPrincipal(ctx, entities.SystemPrincipal), f.user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, status.FailedAttempts, "counted like a failed login")
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		reader := principal.WithPrincipal(ctx, entities.Principal{APIKeyID: 2, Scopes: entities.Scopes{entities.ScopeUsersRead}})
+
+		_, err := f.auth.VerifyPassword(reader, "ali@gmail.com", "correct horse", "10.0.0.1")
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		_, err = f.auth.VerifyPassword(principal.WithPrincipal(ctx, entities.Principal{UserID: f.user.ID}), "ali@gmail.com", "correct horse", "10.0.0.1")
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+	})
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+)
+
+// Definition Error Message
+var (
+	ErrMsgUnauthenticated = errors.New("authentication required")
+)
+
+// Definition Interface (Rules)
+// Authorizer decides whether the principal in ctx may perform an action.
+// targetUserID names the user the action is about, or is 0 when it isn't
+// about one user, e.g. listing or creating users.
+type Authorizer interface {
+	Authorize(ctx context.Context, permission entities.Permission, targetUserID uint64) error
+}
+
+// Definition Struct (Class)
+type authorizer struct {
+	roles repository.RoleRepository
+}
+
+// Definition Constructor
+func NewAuthorizer(roles repository.RoleRepository) Authorizer {
+	return &authorizer{roles: roles}
+}
+
+// Definition Implement Methods (Authorize)
+// Authorize fails with ErrMsgUnauthenticated without a principal and with
+// ErrMsgForbidden when the principal holds neither permission nor, for its
+// own record, the ":self" variant of it. System principals may do anything,
+// service accounts what the scopes of their API key allow.
+func (a *authorizer) Authorize(ctx context.Context, permission entities.Permission, targetUserID uint64) error {
+	caller, ok := principal.FromContext(ctx)
+	if !ok {
+		return ErrMsgUnauthenticated
+	}
+
+	if caller.System {
+		return nil
+	}
+
+	if caller.APIKeyID != 0 {
+		if caller.Scopes.Allows(permission) {
+			return nil
+		}
+
+		return fmt.Errorf("%w: %s", ErrMsgForbidden, permission)
+	}
+
+	permissions, err := a.roles.Permissions(ctx, caller.UserID)
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	if slices.Contains(permissions, permission) {
+		return nil
+	}
+
+	if targetUserID != 0 && targetUserID == caller.UserID && slices.Contains(permissions, permission.Self()) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrMsgForbidden, permission)
+}
+
+// allowAll is an Authorizer that allows everything.
+type allowAll struct{}
+
+// AllowAll returns an Authorizer that allows every action, even without a
+// principal. It is meant for tests and tools that run with full trust.
+func AllowAll() Authorizer {
+	return allowAll{}
+}
+
+// Definition Implement Methods (Authorize)
+func (allowAll) Authorize(ctx context.Context, permission entities.Permission, targetUserID uint64) error {
+	return nil
+}
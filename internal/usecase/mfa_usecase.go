@@ -0,0 +1,495 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/secretbox"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/token"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/totp"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+)
+
+// Definition Error Message
+var (
+	ErrMsgMFANotEnrolled    = errors.New("mfa is not enrolled")
+	ErrMsgMFANotEnabled     = errors.New("mfa is not enabled")
+	ErrMsgMFAAlreadyEnabled = errors.New("mfa is already enabled")
+	ErrMsgInvalidMFACode    = errors.New("invalid mfa code")
+)
+
+// Definition Recovery Codes
+// Activation hands out recoveryCodeCount codes of recoveryCodeSize base32
+// characters, shown split in two halves, e.g. "k3m9q-x2c7t".
+const (
+	recoveryCodeCount = 10
+	recoveryCodeSize  = 10
+)
+
+// MFAOptions configures TOTP codes.
+type MFAOptions struct {
+	// Issuer names the service in authenticator apps.
+	Issuer string
+	// Skew is how many time steps a code may be off either way, to allow
+	// for clock drift between the server and the authenticator.
+	Skew int
+	// Lockout throttles wrong codes of a user as it does their passwords,
+	// under a lockout of its own. IPThreshold is not used.
+	Lockout LockoutOptions
+}
+
+// Definition Interface (Rules)
+type MFAUsecase interface {
+	Status(ctx context.Context, userID uint64) (entities.MFAStatus, error)
+	Enroll(ctx context.Context, userID uint64) (entities.MFAEnrollment, error)
+	Activate(ctx context.Context, userID uint64, code string) ([]string, error)
+	Verify(ctx context.Context, userID uint64, code string) (string, error)
+	Disable(ctx context.Context, userID uint64, code string) error
+}
+
+// Definition Struct (Class)
+type mfaUsecase struct {
+	repo       repository.MFARepository
+	users      repository.UserRepository
+	lockouts   repository.LockoutRepository
+	audit      repository.AuditRepository
+	txManager  repository.TxManager
+	authorizer Authorizer
+	box        *secretbox.Box
+	clock      clock.Clock
+	options    MFAOptions
+	throttle   throttle
+}
+
+// Definition Constructor
+// Secrets are sealed by box before they are stored. Reading the status needs
+// users:read and checking codes mfa:verify, each also granted for the
+// caller's own user by ":self". Changing the enrollment needs users:update
+// for the caller's own and mfa:manage for anyone else's, and is audited.
+// Wrong codes lock the user out of mfa in lockouts.
+func NewMFAUsecase(repo repository.MFARepository, users repository.UserRepository, lockouts repository.LockoutRepository, audit repository.AuditRepository, txManager repository.TxManager, authorizer Authorizer, box *secretbox.Box, clock clock.Clock, options MFAOptions) MFAUsecase {
+	return &mfaUsecase{
+		repo:       repo,
+		users:      users,
+		lockouts:   lockouts,
+		audit:      audit,
+		txManager:  txManager,
+		authorizer: authorizer,
+		box:        box,
+		clock:      clock,
+		options:    options,
+		throttle:   throttle{lockouts: lockouts, options: options.Lockout},
+	}
+}
+
+// Definition Implement Methods (Status, Enroll, Activate, Verify, Disable)
+func (u *mfaUsecase) Status(ctx context.Context, userID uint64) (entities.MFAStatus, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersRead, userID)
+	if err != nil {
+		return entities.MFAStatus{}, err
+	}
+
+	_, err = u.user(ctx, userID)
+	if err != nil {
+		return entities.MFAStatus{}, err
+	}
+
+	mfa, err := u.repo.Find(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgMFANotFound) {
+			return entities.MFAStatus{}, nil
+		}
+
+		return entities.MFAStatus{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	if !mfa.Enabled() {
+		return entities.MFAStatus{}, nil
+	}
+
+	left, err := u.repo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return entities.MFAStatus{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return entities.MFAStatus{Enabled: true, EnabledAt: mfa.EnabledAt, RecoveryCodesLeft: left}, nil
+}
+
+// Definition Implement Methods (Status, Enroll, Activate, Verify, Disable)
+// Enroll creates a new secret for the user, replacing a pending one. Codes
+// are only checked once Activate proved the authenticator works.
+func (u *mfaUsecase) Enroll(ctx context.Context, userID uint64) (entities.MFAEnrollment, error) {
+	_, err := u.authorizeChange(ctx, userID)
+	if err != nil {
+		return entities.MFAEnrollment{}, err
+	}
+
+	user, err := u.user(ctx, userID)
+	if err != nil {
+		return entities.MFAEnrollment{}, err
+	}
+
+	mfa, err := u.repo.Find(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrMsgMFANotFound) {
+		return entities.MFAEnrollment{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+	if err == nil && mfa.Enabled() {
+		return entities.MFAEnrollment{}, ErrMsgMFAAlreadyEnabled
+	}
+	pending := err == nil
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return entities.MFAEnrollment{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	sealed, err := u.box.Seal(secret)
+	if err != nil {
+		return entities.MFAEnrollment{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := u.repo.Enroll(ctx, userID, sealed)
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		return u.record(ctx, entities.AuditUserMFAEnrolled, userID, entities.AuditChange{Field: "mfa_enrolled", Before: pending, After: true})
+	})
+	if err != nil {
+		return entities.MFAEnrollment{}, err
+	}
+
+	return entities.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.URI(u.options.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Definition Implement Methods (Status, Enroll, Activate, Verify, Disable)
+// Activate enables a pending enrollment given a current code of its secret,
+// and returns the recovery codes. They are only stored hashed, this is the
+// one time they can be shown.
+func (u *mfaUsecase) Activate(ctx context.Context, userID uint64, code string) ([]string, error) {
+	_, err := u.authorizeChange(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mfa, err := u.repo.Find(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgMFANotFound) {
+			return nil, fmt.Errorf("%w:%w", ErrMsgMFANotEnrolled, err)
+		}
+
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	if mfa.Enabled() {
+		return nil, ErrMsgMFAAlreadyEnabled
+	}
+
+	step, err := u.match(mfa, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		err := u.repo.Enable(ctx, userID, step, hashes)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrMsgMFAAlreadyEnabled):
+				return fmt.Errorf("%w:%w", ErrMsgMFAAlreadyEnabled, err)
+			case errors.Is(err, repository.ErrMsgMFANotFound):
+				return fmt.Errorf("%w:%w", ErrMsgMFANotEnrolled, err)
+			default:
+				return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+			}
+		}
+
+		return u.record(ctx, entities.AuditUserMFAEnabled, userID, entities.AuditChange{Field: "mfa_enabled", Before: false, After: true})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Definition Implement Methods (Status, Enroll, Activate, Verify, Disable)
+// Verify checks a TOTP code or an unused recovery code of the user and
+// returns which one it was. Every TOTP code and every recovery code passes
+// once; presenting it again fails with ErrMsgInvalidMFACode. Too many wrong
+// codes fail with a LockedError until the lock passes.
+func (u *mfaUsecase) Verify(ctx context.Context, userID uint64, code string) (string, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermMFAVerify, userID)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = u.user(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	mfa, err := u.repo.Find(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrMsgMFANotFound) {
+		return "", fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+	if err != nil || !mfa.Enabled() {
+		return "", ErrMsgMFANotEnabled
+	}
+
+	var method string
+	err = u.guarded(ctx, userID, func() error {
+		method, err = u.use(ctx, userID, mfa, code)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return method, nil
+}
+
+// Definition Implement Methods (Status, Enroll, Activate, Verify, Disable)
+// Disable removes the second factor of the user, enabled or pending, and
+// records it in the audit log. Users turning off their own enabled factor
+// prove they still hold it with a code, as Verify takes it, so a stolen
+// session can't, and are locked out like Verify after too many wrong codes;
+// turning off anyone else's needs mfa:manage.
+func (u *mfaUsecase) Disable(ctx context.Context, userID uint64, code string) error {
+	own, err := u.authorizeChange(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if own {
+		return u.guarded(ctx, userID, func() error {
+			return u.disable(ctx, userID, code, true)
+		})
+	}
+
+	return u.disable(ctx, userID, code, false)
+}
+
+// disable deletes the mfa of the user and records it, within one
+// transaction. own callers turning off an enabled factor must give a code.
+func (u *mfaUsecase) disable(ctx context.Context, userID uint64, code string, own bool) error {
+	return u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		mfa, err := u.repo.Find(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrMsgMFANotFound) {
+				return fmt.Errorf("%w:%w", ErrMsgMFANotEnrolled, err)
+			}
+
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		if own && mfa.Enabled() {
+			_, err = u.use(ctx, userID, mfa, code)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = u.repo.Delete(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrMsgMFANotFound) {
+				return fmt.Errorf("%w:%w", ErrMsgMFANotEnrolled, err)
+			}
+
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		return u.record(ctx, entities.AuditUserMFADisabled, userID, entities.AuditChange{Field: "mfa_enabled", Before: mfa.Enabled(), After: false})
+	})
+}
+
+// authorizeChange checks that the principal in ctx may change the mfa of
+// userID: users:update for their own, mfa:manage for anyone else's, so
+// holding users:update on others never hands out their secret. It reports
+// whether the mfa is the caller's own.
+func (u *mfaUsecase) authorizeChange(ctx context.Context, userID uint64) (bool, error) {
+	caller, _ := principal.FromContext(ctx)
+	own := caller.UserID != 0 && caller.UserID == userID
+
+	permission := entities.PermMFAManage
+	if own {
+		permission = entities.PermUsersUpdate
+	}
+
+	err := u.authorizer.Authorize(ctx, permission, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return own, nil
+}
+
+// record writes an audit event of action on the mfa of userID.
+func (u *mfaUsecase) record(ctx context.Context, action entities.AuditAction, userID uint64, change entities.AuditChange) error {
+	_, err := u.audit.Record(ctx, entities.AuditEvent{Action: action, UserID: userID, Changes: []entities.AuditChange{change}})
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
+
+// guarded runs check, which checks a code of the user, unless the user is
+// locked out of mfa. A wrong code counts a failure, a right one forgets the
+// failures. They are counted after check returned, so the rollback of a
+// transaction check ran in doesn't undo them.
+func (u *mfaUsecase) guarded(ctx context.Context, userID uint64, check func() error) error {
+	key := entities.LockoutKeyMFA(userID)
+	now := u.clock.Now()
+
+	err := u.throttle.check(ctx, key, now)
+	if err != nil {
+		return err
+	}
+
+	err = check()
+	if err != nil {
+		if errors.Is(err, ErrMsgInvalidMFACode) {
+			failed := u.throttle.fail(ctx, key, u.options.Lockout.Threshold, now)
+			if failed != nil {
+				return failed
+			}
+		}
+
+		return err
+	}
+
+	err = u.lockouts.Reset(ctx, key)
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
+
+// use checks code against the enabled mfa of the user, using it up, and
+// returns which kind of code it was.
+func (u *mfaUsecase) use(ctx context.Context, userID uint64, mfa entities.MFA, code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if !isTOTPCode(code) {
+		err := u.repo.UseRecoveryCode(ctx, userID, token.Hash(normalizeRecoveryCode(code)))
+		if err != nil {
+			if errors.Is(err, repository.ErrMsgRecoveryCodeNotFound) {
+				return "", fmt.Errorf("%w:%w", ErrMsgInvalidMFACode, err)
+			}
+
+			return "", fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		return entities.MFAMethodRecoveryCode, nil
+	}
+
+	step, err := u.match(mfa, code)
+	if err != nil {
+		return "", err
+	}
+
+	err = u.repo.UseStep(ctx, userID, step)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgMFAStepUsed) {
+			return "", fmt.Errorf("%w:%w", ErrMsgInvalidMFACode, err)
+		}
+
+		return "", fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return entities.MFAMethodTOTP, nil
+}
+
+// match returns the time step code of the secret of mfa belongs to, or
+// ErrMsgInvalidMFACode.
+func (u *mfaUsecase) match(mfa entities.MFA, code string) (int64, error) {
+	secret, err := u.box.Open(mfa.Secret)
+	if err != nil {
+		return 0, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	step, ok, err := totp.Match(secret, code, u.clock.Now(), u.options.Skew)
+	if err != nil {
+		return 0, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+	if !ok {
+		return 0, ErrMsgInvalidMFACode
+	}
+
+	return step, nil
+}
+
+// user returns the active user with userID, or fails with ErrMsgUserNotFound.
+func (u *mfaUsecase) user(ctx context.Context, userID uint64) (entities.User, error) {
+	user, err := u.users.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgUserNotFound) {
+			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
+		}
+
+		return entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return user, nil
+}
+
+// isTOTPCode reports whether code is shaped like a TOTP code rather than a
+// recovery code.
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newRecoveryCodes returns fresh recovery codes and the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		// 8 bytes encode to 13 characters, enough for the 50 bits kept
+		raw := make([]byte, 8)
+		_, err := rand.Read(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(raw))[:recoveryCodeSize]
+		codes[i] = code[:recoveryCodeSize/2] + "-" + code[recoveryCodeSize/2:]
+		hashes[i] = token.Hash(code)
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode undoes the formatting users may add or drop when
+// typing a recovery code.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
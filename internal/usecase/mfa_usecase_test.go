@@ -0,0 +1,374 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/secretbox"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/totp"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mfaFixture struct {
+	mfa    usecase.MFAUsecase
+	clock  *movingClock
+	users  repository.UserRepository
+	roles  repository.RoleRepository
+	audit  repository.AuditRepository
+	user   entities.User
+	caller context.Context
+}
+
+// newMFAFixture wires the MFA usecase to memory repositories holding one
+// user, with caller carrying that user as the principal.
+func newMFAFixture(t *testing.T) mfaFixture {
+	t.Helper()
+
+	ctx := context.Background()
+	clk := &movingClock{now: time.Date(2026, 10, 17, 12, 0, 10, 0, time.UTC)}
+
+	users := repository.NewMemoryUserRepository(clk)
+	user, err := users.Save(ctx, entities.User{Name: "Ali", Family: "Ahmadi", Email: "ali@gmail.com", Age: 30})
+	require.NoError(t, err)
+
+	roles := repository.NewMemoryRoleRepository(clk)
+	audit := repository.NewMemoryAuditRepository(clk)
+	authorizer := usecase.NewAuthorizer(roles)
+	options := usecase.MFAOptions{Issuer: "user-manager", Skew: 1, Lockout: usecase.LockoutOptions{
+		Threshold:    5,
+		Window:       15 * time.Minute,
+		BaseDuration: time.Minute,
+		MaxDuration:  time.Hour,
+	}}
+
+	return mfaFixture{
+		mfa:    usecase.NewMFAUsecase(repository.NewMemoryMFARepository(clk), users, repository.NewMemoryLockoutRepository(clk), audit, repository.NewMemoryTxManager(), authorizer, secretbox.NewBox("test-key"), clk, options),
+		clock:  clk,
+		users:  users,
+		roles:  roles,
+		audit:  audit,
+		user:   user,
+		caller: principal.WithPrincipal(ctx, entities.Principal{UserID: user.ID}),
+	}
+}
+
+// code returns the code of secret at the fixture's time, moved by steps.
+func (f mfaFixture) code(t *testing.T, secret string, steps int64) string {
+	t.Helper()
+
+	code, err := totp.Code(secret, totp.Step(f.clock.now)+steps)
+	require.NoError(t, err)
+	return code
+}
+
+// enable enrolls and activates MFA for the fixture's user and returns the
+// secret and recovery codes.
+func (f mfaFixture) enable(t *testing.T) (string, []string) {
+	t.Helper()
+
+	enrollment, err := f.mfa.Enroll(f.caller, f.user.ID)
+	require.NoError(t, err)
+
+	codes, err := f.mfa.Activate(f.caller, f.user.ID, f.code(t, enrollment.Secret, 0))
+	require.NoError(t, err)
+
+	return enrollment.Secret, codes
+}
+
+// callerWith returns the principal of a new user holding role.
+func (f mfaFixture) callerWith(t *testing.T, email string, role string) context.Context {
+	t.Helper()
+
+	other, err := f.users.Save(context.Background(), entities.User{Name: "Sara", Family: "Rezaei", Email: email, Age: 30})
+	require.NoError(t, err)
+	require.NoError(t, f.roles.Assign(context.Background(), other.ID, role))
+	return principal.WithPrincipal(actor.WithActor(context.Background(), entities.Principal{UserID: other.ID}.Actor()), entities.Principal{UserID: other.ID})
+}
+
+// events returns the audit events of action on the fixture's user.
+func (f mfaFixture) events(t *testing.T, action entities.AuditAction) []entities.AuditEvent {
+	t.Helper()
+
+	page, err := f.audit.List(context.Background(), entities.AuditQuery{UserID: &f.user.ID, Action: action})
+	require.NoError(t, err)
+	return page.Events
+}
+
+func TestMFAUsecase_Enroll(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		f := newMFAFixture(t)
+
+		enrollment, err := f.mfa.Enroll(f.caller, f.user.ID)
+		require.NoError(t, err)
+
+		uri, err := url.Parse(enrollment.ProvisioningURI)
+		require.NoError(t, err)
+		assert.Equal(t, "otpauth", uri.Scheme)
+		assert.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+		assert.Contains(t, uri.Path, "ali@gmail.com")
+
+		status, err := f.mfa.Status(f.caller, f.user.ID)
+		require.NoError(t, err)
+		assert.False(t, status.Enabled, "enrolled, not yet activated")
+
+		events := f.events(t, entities.AuditUserMFAEnrolled)
+		require.Len(t, events, 1)
+		assert.Equal(t, []entities.AuditChange{{Field: "mfa_enrolled", Before: false, After: true}}, events[0].Changes)
+	})
+
+	t.Run("AlreadyEnabled", func(t *testing.T) {
+		f := newMFAFixture(t)
+		f.enable(t)
+
+		_, err := f.mfa.Enroll(f.caller, f.user.ID)
+		assert.True(t, errors.Is(err, usecase.ErrMsgMFAAlreadyEnabled), "got %v", err)
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		f := newMFAFixture(t)
+		other := principal.WithPrincipal(context.Background(), entities.Principal{UserID: f.user.ID + 1})
+
+		_, err := f.mfa.Enroll(other, f.user.ID)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		_, err = f.mfa.Enroll(f.callerWith(t, "support@gmail.com", entities.RoleSupport), f.user.ID)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "users:update on others doesn't reach their secret, got %v", err)
+		assert.Empty(t, f.events(t, entities.AuditUserMFAEnrolled))
+
+		admin := f.callerWith(t, "admin@gmail.com", entities.RoleAdmin)
+		_, err = f.mfa.Enroll(admin, f.user.ID)
+		require.NoError(t, err)
+
+		events := f.events(t, entities.AuditUserMFAEnrolled)
+		require.Len(t, events, 1)
+		assert.Equal(t, actor.FromContext(admin), events[0].Actor)
+	})
+}
+
+func TestMFAUsecase_Activate(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		f := newMFAFixture(t)
+
+		_, codes := f.enable(t)
+		assert.Len(t, codes, 10)
+
+		status, err := f.mfa.Status(f.caller, f.user.ID)
+		require.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.Equal(t, int64(10), status.RecoveryCodesLeft)
+
+		events := f.events(t, entities.AuditUserMFAEnabled)
+		require.Len(t, events, 1)
+		assert.Equal(t, []entities.AuditChange{{Field: "mfa_enabled", Before: false, After: true}}, events[0].Changes)
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		f := newMFAFixture(t)
+		enrollment, err := f.mfa.Enroll(f.caller, f.user.ID)
+		require.NoError(t, err)
+
+		_, err = f.mfa.Activate(f.callerWith(t, "support@gmail.com", entities.RoleSupport), f.user.ID, f.code(t, enrollment.Secret, 0))
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+		assert.Empty(t, f.events(t, entities.AuditUserMFAEnabled))
+	})
+
+	t.Run("WrongCode", func(t *testing.T) {
+		f := newMFAFixture(t)
+		enrollment, err := f.mfa.Enroll(f.caller, f.user.ID)
+		require.NoError(t, err)
+
+		_, err = f.mfa.Activate(f.caller, f.user.ID, f.code(t, enrollment.Secret, 5))
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidMFACode), "got %v", err)
+	})
+
+	t.Run("NotEnrolled", func(t *testing.T) {
+		f := newMFAFixture(t)
+
+		_, err := f.mfa.Activate(f.caller, f.user.ID, "123456")
+		assert.True(t, errors.Is(err, usecase.ErrMsgMFANotEnrolled), "got %v", err)
+	})
+}
+
+func TestMFAUsecase_Verify(t *testing.T) {
+	t.Run("TOTP", func(t *testing.T) {
+		f := newMFAFixture(t)
+		secret, _ := f.enable(t)
+
+		f.clock.now = f.clock.now.Add(totp.Period)
+		method, err := f.mfa.Verify(f.caller, f.user.ID, f.code(t, secret, 0))
+		require.NoError(t, err)
+		assert.Equal(t, entities.MFAMethodTOTP, method)
+	})
+
+	t.Run("Drift", func(t *testing.T) {
+		f := newMFAFixture(t)
+		secret, _ := f.enable(t)
+		f.clock.now = f.clock.now.Add(3 * totp.Period)
+
+		_, err := f.mfa.Verify(f.caller, f.user.ID, f.code(t, secret, 1))
+		assert.NoError(t, err, "one step ahead is accepted")
+
+		_, err = f.mfa.Verify(f.caller, f.user.ID, f.code(t, secret, -2))
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidMFACode), "got %v", err)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		f := newMFAFixture(t)
+		secret, _ := f.enable(t)
+
+		_, err := f.mfa.Verify(f.caller, f.user.ID, f.code(t, secret, 0))
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidMFACode), "the activation code is used, got %v", err)
+
+		f.clock.now = f.clock.now.Add(totp.Period)
+		code := f.code(t, secret, 0)
+		_, err = f.mfa.Verify(f.caller, f.user.ID, code)
+		require.NoError(t, err)
+
+		_, err = f.mfa.Verify(f.caller, f.user.ID, code)
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidMFACode), "got %v", err)
+
+		_, err = f.mfa.Verify(f.caller, f.user.ID, f.code(t, secret, -1))
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidMFACode), "steps before the last used one are refused, got %v", err)
+	})
+
+	t.Run("RecoveryCode", func(t *testing.T) {
+		f := newMFAFixture(t)
+		_, codes := f.enable(t)
+
+		method, err := f.mfa.Verify(f.caller, f.user.ID, strings.ToUpper(strings.ReplaceAll(codes[0], "-", "")))
+		require.NoError(t, err)
+		assert.Equal(t, entities.MFAMethodRecoveryCode, method)
+
+		_, err = f.mfa.Verify(f.caller, f.user.ID, codes[0])
+		assert.True(t, errors.Is(err, usecase.ErrMsgInvalidMFACode), "got %v", err)
+
+		status, err := f.mfa.Status(f.caller, f.user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(9), status.RecoveryCodesLeft)
+	})
+
+	t.Run("NotEnabled", func(t *testing.T) {
+		f := newMFAFixture(t)
+		_, err := f.mfa.Enroll(f.caller, f.user.ID)
+		require.NoError(t, err)
+
+		_, err = f.mfa.Verify(f.caller, f.user.ID, "123456")
+		assert.True(t, errors.Is(err, usecase.ErrMsgMFANotEnabled), "got %v", err)
+	})
+
+	t.Run("LockedOut", func(t *testing.T) {
+		f := newMFAFixture(t)
+		secret, _ := f.enable(t)
+		f.clock.now = f.clock.now.Add(totp.Period)
+
+		for i := 0; i < 3; i++ {
+			_, err := f.mfa.Verify(f.caller, f.user.ID, "000000")
+			require.True(t, errors.Is(err, usecase.ErrMsgInvalidMFACode), "got %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			err := f.mfa.Disable(f.caller, f.user.ID, "000000")
+			require.True(t, errors.Is(err, usecase.ErrMsgInvalidMFACode), "failures outlive the rollback of Disable, got %v", err)
+		}
+
+		_, err := f.mfa.Verify(f.caller, f.user.ID, f.code(t, secret, 0))
+		var locked *usecase.LockedError
+		require.True(t, errors.As(err, &locked), "the right code is refused while locked, got %v", err)
+		assert.True(t, errors.Is(err, usecase.ErrMsgTooManyAttempts))
+		assert.Equal(t, time.Minute, locked.RetryAfter)
+
+		err = f.mfa.Disable(f.caller, f.user.ID, f.code(t, secret, 0))
+		assert.True(t, errors.Is(err, usecase.ErrMsgTooManyAttempts), "nor can the factor be turned off, got %v", err)
+
+		f.clock.now = f.clock.now.Add(time.Minute)
+		_, err = f.mfa.Verify(f.caller, f.user.ID, f.code(t, secret, 0))
+		assert.NoError(t, err, "the lock passes")
+	})
+
+	t.Run("Service", func(t *testing.T) {
+		f := newMFAFixture(t)
+		secret, _ := f.enable(t)
+		f.clock.now = f.clock.now.Add(totp.Period)
+
+		reader := principal.WithPrincipal(context.Background(), entities.Principal{APIKeyID: 1, Scopes: entities.Scopes{entities.ScopeUsersRead}})
+		_, err := f.mfa.Verify(reader, f.user.ID, f.code(t, secret, 0))
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		verifier := principal.WithPrincipal(context.Background(), entities.Principal{APIKeyID: 2, Scopes: entities.Scopes{entities.ScopeMFAVerify}})
+		_, err = f.mfa.Verify(verifier, f.user.ID, f.code(t, secret, 0))
+		assert.NoError(t, err)
+	})
+}
+
+func TestMFAUsecase_Disable(t *testing.T) {
+	t.Run("OwnWithCode", func(t *testing.T) {
+		f := newMFAFixture(t)
+		secret, _ := f.enable(t)
+		f.clock.now = f.clock.now.Add(30 * time.Second)
+
+		require.NoError(t, f.mfa.Disable(f.caller, f.user.ID, f.code(t, secret, 0)))
+
+		status, err := f.mfa.Status(f.caller, f.user.ID)
+		require.NoError(t, err)
+		assert.False(t, status.Enabled)
+
+		err = f.mfa.Disable(f.caller, f.user.ID, "")
+		assert.True(t, errors.Is(err, usecase.ErrMsgMFANotEnrolled), "got %v", err)
+	})
+
+	t.Run("OwnWithRecoveryCode", func(t *testing.T) {
+		f := newMFAFixture(t)
+		_, codes := f.enable(t)
+
+		assert.NoError(t, f.mfa.Disable(f.caller, f.user.ID, codes[0]))
+	})
+
+	t.Run("OwnWithoutCode", func(t *testing.T) {
+		f := newMFAFixture(t)
+		f.enable(t)
+
+		for _, code := range []string{"", "000000", "aaaaa-bbbbb"} {
+			err := f.mfa.Disable(f.caller, f.user.ID, code)
+			assert.True(t, errors.Is(err, usecase.ErrMsgInvalidMFACode), "got %v", err)
+		}
+
+		status, err := f.mfa.Status(f.caller, f.user.ID)
+		require.NoError(t, err)
+		assert.True(t, status.Enabled, "a stolen session can't turn it off")
+	})
+
+	t.Run("OwnPending", func(t *testing.T) {
+		f := newMFAFixture(t)
+		_, err := f.mfa.Enroll(f.caller, f.user.ID)
+		require.NoError(t, err)
+
+		assert.NoError(t, f.mfa.Disable(f.caller, f.user.ID, ""), "there is no code to prove yet")
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		f := newMFAFixture(t)
+		f.enable(t)
+
+		err := f.mfa.Disable(f.callerWith(t, "support@gmail.com", entities.RoleSupport), f.user.ID, "")
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		admin := f.callerWith(t, "admin@gmail.com", entities.RoleAdmin)
+		require.NoError(t, f.mfa.Disable(admin, f.user.ID, ""))
+
+		page, err := f.audit.List(context.Background(), entities.AuditQuery{UserID: &f.user.ID, Action: entities.AuditUserMFADisabled})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 1)
+		assert.Equal(t, entities.AuditUserMFADisabled, page.Events[0].Action)
+		assert.Equal(t, actor.FromContext(admin), page.Events[0].Actor)
+		assert.Equal(t, []entities.AuditChange{{Field: "mfa_enabled", Before: true, After: false}}, page.Events[0].Changes)
+	})
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+)
+
+// Definition Error Message
+var (
+	ErrMsgRoleNotFound    = errors.New("role not found")
+	ErrMsgRoleNotAssigned = errors.New("role not assigned to user")
+	ErrMsgLastAdmin       = errors.New("the last admin can not lose the admin role")
+)
+
+// Definition Interface (Rules)
+type RoleUsecase interface {
+	List(ctx context.Context) ([]entities.Role, error)
+	UserRoles(ctx context.Context, userID uint64) ([]entities.Role, error)
+	Assign(ctx context.Context, userID uint64, role string) error
+	Revoke(ctx context.Context, userID uint64, role string) error
+}
+
+// Definition Struct (Class)
+type roleUsecase struct {
+	roles      repository.RoleRepository
+	users      repository.UserRepository
+	txManager  repository.TxManager
+	authorizer Authorizer
+}
+
+// Definition Constructor
+// Every method needs the roles:manage permission.
+func NewRoleUsecase(roles repository.RoleRepository, users repository.UserRepository, txManager repository.TxManager, authorizer Authorizer) RoleUsecase {
+	return &roleUsecase{
+		roles:      roles,
+		users:      users,
+		txManager:  txManager,
+		authorizer: authorizer,
+	}
+}
+
+// Definition Implement Methods (List, UserRoles, Assign, Revoke)
+func (u *roleUsecase) List(ctx context.Context) ([]entities.Role, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermRolesManage, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := u.roles.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return roles, nil
+}
+
+// Definition Implement Methods (List, UserRoles, Assign, Revoke)
+// UserRoles lists the roles assigned to an active user. Every user also holds
+// the user role without it being listed.
+func (u *roleUsecase) UserRoles(ctx context.Context, userID uint64) ([]entities.Role, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermRolesManage, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.userExists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := u.roles.UserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return roles, nil
+}
+
+// Definition Implement Methods (List, UserRoles, Assign, Revoke)
+// Assign gives an active user a role. Assigning a role the user already
+// holds changes nothing.
+func (u *roleUsecase) Assign(ctx context.Context, userID uint64, role string) error {
+	err := u.authorizer.Authorize(ctx, entities.PermRolesManage, userID)
+	if err != nil {
+		return err
+	}
+
+	err = u.userExists(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return u.roleError(u.roles.Assign(ctx, userID, role))
+}
+
+// Definition Implement Methods (List, UserRoles, Assign, Revoke)
+// Revoke takes a role from a user. Taking the admin role from the only user
+// holding it fails with ErrMsgLastAdmin, so nobody is locked out of managing
+// roles.
+func (u *roleUsecase) Revoke(ctx context.Context, userID uint64, role string) error {
+	err := u.authorizer.Authorize(ctx, entities.PermRolesManage, userID)
+	if err != nil {
+		return err
+	}
+
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		err := u.roles.Revoke(ctx, userID, role)
+		if err != nil {
+			return err
+		}
+
+		if role != entities.RoleAdmin {
+			return nil
+		}
+
+		admins, err := u.roles.CountHolders(ctx, entities.RoleAdmin)
+		if err != nil {
+			return err
+		}
+
+		if admins == 0 {
+			return ErrMsgLastAdmin
+		}
+
+		return nil
+	})
+	if errors.Is(err, ErrMsgLastAdmin) {
+		return err
+	}
+
+	return u.roleError(err)
+}
+
+// userExists fails with ErrMsgUserNotFound unless userID names an active user.
+func (u *roleUsecase) userExists(ctx context.Context, userID uint64) error {
+	_, err := u.users.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgUserNotFound) {
+			return fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
+		}
+
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
+
+// roleError maps a repository error from changing role assignments to a
+// usecase error.
+func (u *roleUsecase) roleError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrMsgRoleNotFound):
+		return fmt.Errorf("%w:%w", ErrMsgRoleNotFound, err)
+	case errors.Is(err, repository.ErrMsgRoleNotAssigned):
+		return fmt.Errorf("%w:%w", ErrMsgRoleNotAssigned, err)
+	default:
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+}
@@ -0,0 +1,244 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/principal"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rbacFixture struct {
+	users usecase.UserUsecase
+	roles usecase.RoleUsecase
+	admin entities.User
+	staff entities.User
+	plain entities.User
+}
+
+// as returns ctx carrying user as the principal.
+func (f rbacFixture) as(ctx context.Context, user entities.User) context.Context {
+	return principal.WithPrincipal(ctx, entities.Principal{UserID: user.ID, Email: user.Email})
+}
+
+// newRBACFixture wires the user and role usecases to memory repositories
+// holding an admin, a support user and a user without assigned roles.
+func newRBACFixture(t *testing.T) rbacFixture {
+	t.Helper()
+
+	ctx := principal.WithPrincipal(context.Background(), entities.SystemPrincipal)
+	now := clock.Fixed(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+
+	repo := repository.NewMemoryUserRepository(now)
+	roles := repository.NewMemoryRoleRepository(now)
+	txManager := repository.NewMemoryTxManager()
+	authorizer := usecase.NewAuthorizer(roles)
+
+	f := rbacFixture{
+		users: usecase.NewUserUsecase(repo, repository.NewMemoryAuditRepository(now), txManager, authorizer, now, validator.New()),
+		roles: usecase.NewRoleUsecase(roles, repo, txManager, authorizer),
+	}
+
+	created := make([]entities.User, 0, 3)
+	for _, email := range []string{"admin@gmail.com", "support@gmail.com", "user@gmail.com"} {
+		user, err := f.users.Create(ctx, entities.User{Name: "Ali", Family: "Ahmadi", Email: email, Age: 30})
+		require.NoError(t, err)
+		created = append(created, user)
+	}
+	f.admin, f.staff, f.plain = created[0], created[1], created[2]
+
+	require.NoError(t, f.roles.Assign(ctx, f.admin.ID, entities.RoleAdmin))
+	require.NoError(t, f.roles.Assign(ctx, f.staff.ID, entities.RoleSupport))
+
+	return f
+}
+
+func TestAuthorizer(t *testing.T) {
+	ctx := context.Background()
+	roles := repository.NewMemoryRoleRepository(clock.New())
+	require.NoError(t, roles.Assign(ctx, 2, entities.RoleSupport))
+	authorizer := usecase.NewAuthorizer(roles)
+
+	as := func(userID uint64) context.Context {
+		return principal.WithPrincipal(ctx, entities.Principal{UserID: userID})
+	}
+
+	t.Run("WithoutPrincipal", func(t *testing.T) {
+		err := authorizer.Authorize(ctx, entities.PermUsersRead, 1)
+
+		assert.True(t, errors.Is(err, usecase.ErrMsgUnauthenticated), "got %v", err)
+	})
+
+	t.Run("System", func(t *testing.T) {
+		err := authorizer.Authorize(principal.WithPrincipal(ctx, entities.SystemPrincipal), entities.PermUsersDelete, 1)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		assert.NoError(t, authorizer.Authorize(as(1), entities.PermUsersUpdate, 1))
+		assert.True(t, errors.Is(authorizer.Authorize(as(1), entities.PermUsersUpdate, 3), usecase.ErrMsgForbidden))
+		assert.True(t, errors.Is(authorizer.Authorize(as(1), entities.PermUsersRead, 0), usecase.ErrMsgForbidden), "listing users is not about one's own record")
+		assert.True(t, errors.Is(authorizer.Authorize(as(1), entities.PermUsersDelete, 1), usecase.ErrMsgForbidden))
+	})
+
+	t.Run("Role", func(t *testing.T) {
+		assert.NoError(t, authorizer.Authorize(as(2), entities.PermUsersUpdate, 3))
+		assert.NoError(t, authorizer.Authorize(as(2), entities.PermUsersRead, 0))
+		assert.True(t, errors.Is(authorizer.Authorize(as(2), entities.PermUsersDelete, 3), usecase.ErrMsgForbidden))
+	})
+
+	t.Run("APIKey", func(t *testing.T) {
+		service := principal.WithPrincipal(ctx, entities.Principal{APIKeyID: 1, ServiceAccount: "billing", Scopes: entities.Scopes{entities.ScopeUsersRead}})
+
+		assert.NoError(t, authorizer.Authorize(service, entities.PermUsersRead, 0))
+		assert.True(t, errors.Is(authorizer.Authorize(service, entities.PermUsersUpdate, 3), usecase.ErrMsgForbidden))
+		assert.True(t, errors.Is(authorizer.Authorize(service, entities.PermRolesManage, 0), usecase.ErrMsgForbidden))
+	})
+}
+
+func TestUserUsecase_Authorization(t *testing.T) {
+	ctx := context.Background()
+	name := "Sara"
+
+	t.Run("RegularUser", func(t *testing.T) {
+		f := newRBACFixture(t)
+		caller := f.as(ctx, f.plain)
+
+		_, err := f.users.FindByID(caller, f.plain.ID)
+		assert.NoError(t, err)
+
+		_, err = f.users.Patch(caller, f.plain.ID, entities.UserPatch{Name: &name})
+		assert.NoError(t, err)
+
+		_, err = f.users.FindByID(caller, f.admin.ID)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		_, err = f.users.Patch(caller, f.admin.ID, entities.UserPatch{Name: &name})
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		_, err = f.users.FindAll(caller, entities.UserListQuery{})
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		err = f.users.Delete(caller, f.plain.ID)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+	})
+
+	t.Run("Support", func(t *testing.T) {
+		f := newRBACFixture(t)
+		caller := f.as(ctx, f.staff)
+
+		_, err := f.users.FindAll(caller, entities.UserListQuery{})
+		assert.NoError(t, err)
+
+		deleted := entities.UserFilter{IncludeDeleted: true}
+		_, err = f.users.FindAll(caller, entities.UserListQuery{Filter: deleted})
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "only those who may delete users see deleted ones, got %v", err)
+
+		err = f.users.Stream(caller, deleted, func(users []entities.User) error { return nil })
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		updated, err := f.users.Update(caller, entities.User{ID: f.plain.ID, Name: name, Family: "Karimi", Email: f.plain.Email, Age: 25, Version: f.plain.Version})
+		require.NoError(t, err)
+		assert.Equal(t, name, updated.Name)
+
+		err = f.users.Delete(caller, f.plain.ID)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		_, err = f.users.DeleteBatch(caller, []uint64{f.plain.ID}, entities.BulkBestEffort)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		_, err = f.users.Create(caller, entities.User{Name: "New", Family: "User", Email: "new@gmail.com", Age: 20})
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		f := newRBACFixture(t)
+		caller := f.as(ctx, f.admin)
+
+		assert.NoError(t, f.users.Delete(caller, f.plain.ID))
+
+		page, err := f.users.FindAll(caller, entities.UserListQuery{Filter: entities.UserFilter{IncludeDeleted: true}})
+		require.NoError(t, err)
+		assert.Len(t, page.Users, 3)
+	})
+
+	t.Run("PatchBatchChecksEachItem", func(t *testing.T) {
+		f := newRBACFixture(t)
+
+		results, err := f.users.PatchBatch(f.as(ctx, f.plain), []entities.UserBatchPatch{
+			{ID: f.plain.ID, Patch: entities.UserPatch{Name: &name}},
+			{ID: f.admin.ID, Patch: entities.UserPatch{Name: &name}},
+		}, entities.BulkBestEffort)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[0].Err)
+		assert.True(t, errors.Is(results[1].Err, usecase.ErrMsgForbidden), "got %v", results[1].Err)
+	})
+
+	t.Run("WithoutPrincipal", func(t *testing.T) {
+		f := newRBACFixture(t)
+
+		_, err := f.users.FindByID(ctx, f.plain.ID)
+
+		assert.True(t, errors.Is(err, usecase.ErrMsgUnauthenticated), "got %v", err)
+	})
+}
+
+func TestRoleUsecase(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("OnlyAdminsManageRoles", func(t *testing.T) {
+		f := newRBACFixture(t)
+
+		err := f.roles.Assign(f.as(ctx, f.staff), f.staff.ID, entities.RoleAdmin)
+		assert.True(t, errors.Is(err, usecase.ErrMsgForbidden), "got %v", err)
+
+		require.NoError(t, f.roles.Assign(f.as(ctx, f.admin), f.plain.ID, entities.RoleSupport))
+
+		roles, err := f.roles.UserRoles(f.as(ctx, f.admin), f.plain.ID)
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, entities.RoleSupport, roles[0].Name)
+
+		_, err = f.users.FindAll(f.as(ctx, f.plain), entities.UserListQuery{})
+		assert.NoError(t, err, "the new role applies to the next call")
+	})
+
+	t.Run("UnknownRoleOrUser", func(t *testing.T) {
+		f := newRBACFixture(t)
+		caller := f.as(ctx, f.admin)
+
+		err := f.roles.Assign(caller, f.plain.ID, "owner")
+		assert.True(t, errors.Is(err, usecase.ErrMsgRoleNotFound), "got %v", err)
+
+		err = f.roles.Assign(caller, f.plain.ID+100, entities.RoleSupport)
+		assert.True(t, errors.Is(err, usecase.ErrMsgUserNotFound), "got %v", err)
+
+		err = f.roles.Revoke(caller, f.plain.ID, entities.RoleSupport)
+		assert.True(t, errors.Is(err, usecase.ErrMsgRoleNotAssigned), "got %v", err)
+	})
+
+	t.Run("LastAdmin", func(t *testing.T) {
+		f := newRBACFixture(t)
+		caller := f.as(ctx, f.admin)
+
+		err := f.roles.Revoke(caller, f.admin.ID, entities.RoleAdmin)
+		assert.True(t, errors.Is(err, usecase.ErrMsgLastAdmin), "got %v", err)
+
+		roles, err := f.roles.UserRoles(caller, f.admin.ID)
+		require.NoError(t, err)
+		assert.Len(t, roles, 1, "the failed revoke is rolled back")
+
+		require.NoError(t, f.roles.Assign(caller, f.staff.ID, entities.RoleAdmin))
+		assert.NoError(t, f.roles.Revoke(caller, f.admin.ID, entities.RoleAdmin))
+	})
+}
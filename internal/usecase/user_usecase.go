@@ -4,8 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/password"
 	"github.com/alirezaghasemi/user-manager/internal/repository"
 	"github.com/go-playground/validator/v10"
 )
@@ -17,6 +22,32 @@ var (
 	ErrMsgInternalServerError = errors.New("internal server")
 	ErrMsgFailedToUpdateUser  = errors.New("failed to update user")
 	ErrMsgUserNotFound        = errors.New("user not found")
+	ErrMsgInvalidCursor       = errors.New("invalid cursor")
+	ErrMsgVersionConflict     = errors.New("user was modified concurrently")
+	ErrMsgBulkAborted         = errors.New("bulk operation aborted")
+	ErrMsgBulkRolledBack      = errors.New("not applied, another item of the batch failed")
+	ErrMsgInvalidPassword     = errors.New("invalid password")
+
+	// errDryRun rolls back the transaction of a dry-run import.
+	errDryRun = errors.New("dry run")
+)
+
+// Definition Operations
+// The operations of UserUsecase as logs and metrics name them.
+const (
+	OpUserCreate      = "user.create"
+	OpUserFind        = "user.find"
+	OpUserUpdate      = "user.update"
+	OpUserPatch       = "user.patch"
+	OpUserList        = "user.list"
+	OpUserStream      = "user.stream"
+	OpUserDelete      = "user.delete"
+	OpUserCreateBatch = "user.create_batch"
+	OpUserPatchBatch  = "user.patch_batch"
+	OpUserDeleteBatch = "user.delete_batch"
+	OpUserImport      = "user.import"
+	OpUserRestore     = "user.restore"
+	OpUserPurge       = "user.purge"
 )
 
 // Definition Interface (Rules)
@@ -24,44 +55,185 @@ type UserUsecase interface {
 	Create(ctx context.Context, user entities.User) (entities.User, error)
 	FindByID(ctx context.Context, id uint64) (entities.User, error)
 	Update(ctx context.Context, user entities.User) (entities.User, error)
-	FindAll(ctx context.Context) ([]entities.User, error)
+	Patch(ctx context.Context, id uint64, patch entities.UserPatch) (entities.User, error)
+	FindAll(ctx context.Context, query entities.UserListQuery) (entities.UserPage, error)
+	Stream(ctx context.Context, filter entities.UserFilter, fn func(users []entities.User) error) error
 	Delete(ctx context.Context, id uint64) error
+	CreateBatch(ctx context.Context, users []entities.User, mode entities.BulkMode) ([]entities.BulkResult, error)
+	PatchBatch(ctx context.Context, patches []entities.UserBatchPatch, mode entities.BulkMode) ([]entities.BulkResult, error)
+	DeleteBatch(ctx context.Context, ids []uint64, mode entities.BulkMode) ([]entities.BulkResult, error)
+	Import(ctx context.Context, users []entities.User, options entities.ImportOptions) ([]entities.ImportResult, error)
+	Restore(ctx context.Context, id uint64) (entities.User, error)
+	Purge(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// VersionConflictError is returned when a patch was based on another version
+// than the stored one, Current. It matches ErrMsgVersionConflict.
+type VersionConflictError struct {
+	Current int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s, the current version is %d", ErrMsgVersionConflict, e.Current)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return ErrMsgVersionConflict
 }
 
 // Definition Struct (Class)
 type userUsecase struct {
-	repo     repository.UserRepository
-	validate *validator.Validate
+	repo       repository.UserRepository
+	audit      repository.AuditRepository
+	txManager  repository.TxManager
+	authorizer Authorizer
+	clock      clock.Clock
+	validate   *validator.Validate
 }
 
 // Definition Constructor
-func NewUserUsecase(repo repository.UserRepository, validate *validator.Validate) UserUsecase {
+// Every method checks with authorizer that the principal in ctx may do it.
+// Every change to a user is recorded in audit within the transaction making
+// it.
+func NewUserUsecase(repo repository.UserRepository, audit repository.AuditRepository, txManager repository.TxManager, authorizer Authorizer, clock clock.Clock, validate *validator.Validate) UserUsecase {
 	return &userUsecase{
-		repo:     repo,
-		validate: validate,
+		repo:       repo,
+		audit:      audit,
+		txManager:  txManager,
+		authorizer: authorizer,
+		clock:      clock,
+		validate:   validate,
 	}
 }
 
 // Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
 func (u *userUsecase) Create(ctx context.Context, user entities.User) (entities.User, error) {
-	userSaved, err := u.repo.Save(ctx, user)
+	err := u.authorizer.Authorize(ctx, entities.PermUsersCreate, 0)
 	if err != nil {
-		if errors.Is(err, repository.ErrMsgDuplicateUser) {
-			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgDuplicateUser, err)
-		}
+		return entities.User{}, err
+	}
+
+	return u.create(ctx, user)
+}
 
-		if errors.Is(err, repository.ErrMsgFailedToSaveUser) {
-			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgFailedToSaveUser, err)
+// create saves a new user without checking permissions.
+func (u *userUsecase) create(ctx context.Context, user entities.User) (entities.User, error) {
+	user, err := hashPassword(user)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	var userSaved entities.User
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		userSaved, err = u.repo.Save(ctx, user)
+		if err != nil {
+			return u.createError(err)
 		}
 
-		return entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		return u.record(ctx, entities.AuditUserCreated, userSaved.ID, entities.UserChanges(nil, &userSaved))
+	})
+	if err != nil {
+		return entities.User{}, err
 	}
 
 	return userSaved, nil
 }
 
+// hashPassword replaces the plain text password of a new user by its hash.
+func hashPassword(user entities.User) (entities.User, error) {
+	if user.Password == "" {
+		return user, nil
+	}
+
+	hash, err := passwordHash(user.Password)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	user.PasswordHash = hash
+	user.Password = ""
+
+	return user, nil
+}
+
+// hashPasswords hashes the users at indexes like hashPassword, on as many
+// goroutines as may run at once since bcrypt is slow by design. It returns
+// the users and errors in the order of indexes, or the error of ctx when it
+// is done before every user is hashed.
+func hashPasswords(ctx context.Context, users []entities.User, indexes []int) ([]entities.User, []error, error) {
+	hashed := make([]entities.User, len(indexes))
+	errs := make([]error, len(indexes))
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(indexes)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range next {
+				hashed[j], errs[j] = hashPassword(users[indexes[j]])
+			}
+		}()
+	}
+
+feed:
+	for j := range indexes {
+		select {
+		case next <- j:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	err := ctx.Err()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return hashed, errs, nil
+}
+
+// passwordHash returns the bcrypt hash of plain, ErrMsgInvalidPassword when it is
+// longer than bcrypt takes.
+func passwordHash(plain string) (string, error) {
+	hash, err := password.Hash(plain)
+	if errors.Is(err, password.ErrMsgTooLong) {
+		return "", fmt.Errorf("%w:%w", ErrMsgInvalidPassword, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return hash, nil
+}
+
+// createError maps a repository error from saving users to a usecase error.
+func (u *userUsecase) createError(err error) error {
+	if errors.Is(err, repository.ErrMsgDuplicateUser) {
+		return fmt.Errorf("%w:%w", ErrMsgDuplicateUser, err)
+	}
+
+	if errors.Is(err, repository.ErrMsgFailedToSaveUser) {
+		return fmt.Errorf("%w:%w", ErrMsgFailedToSaveUser, err)
+	}
+
+	return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+}
+
 // Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
 func (u *userUsecase) FindByID(ctx context.Context, id uint64) (entities.User, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersRead, id)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	return u.findByID(ctx, id)
+}
+
+// findByID reads a user without checking permissions.
+func (u *userUsecase) findByID(ctx context.Context, id uint64) (entities.User, error) {
 	user, err := u.repo.FindByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, repository.ErrMsgUserNotFound) {
@@ -76,6 +248,32 @@ func (u *userUsecase) FindByID(ctx context.Context, id uint64) (entities.User, e
 
 // Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
 func (u *userUsecase) Update(ctx context.Context, user entities.User) (entities.User, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersUpdate, user.ID)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	var updated entities.User
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		stored, err := u.findByID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		updated, err = u.update(ctx, stored, user)
+		return err
+	})
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	return updated, nil
+}
+
+// update writes a user back over stored, the user as read before, without
+// checking permissions, and records what changed. ctx must carry a
+// transaction for the record to be written with the change.
+func (u *userUsecase) update(ctx context.Context, stored entities.User, user entities.User) (entities.User, error) {
 	userUpdated, err := u.repo.Update(ctx, user)
 	if err != nil {
 		switch {
@@ -85,30 +283,120 @@ func (u *userUsecase) Update(ctx context.Context, user entities.User) (entities.
 			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgFailedToUpdateUser, err)
 		case errors.Is(err, repository.ErrMsgUserNotFound):
 			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
+		case errors.Is(err, repository.ErrMsgVersionConflict):
+			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgVersionConflict, err)
 		default:
 			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
 		}
 	}
 
+	// the repository writes the profile fields only, which a patch of all of
+	// them applies to the stored user the same way
+	written := entities.UserPatch{Name: &user.Name, Family: &user.Family, Email: &user.Email, Age: &user.Age}.Apply(stored)
+	err = u.record(ctx, entities.AuditUserUpdated, user.ID, entities.UserChanges(&stored, &written))
+	if err != nil {
+		return entities.User{}, err
+	}
+
 	return userUpdated, nil
 }
 
+// recordCreated appends an audit event for each of the users just saved.
+func (u *userUsecase) recordCreated(ctx context.Context, users []entities.User) error {
+	for i := range users {
+		err := u.record(ctx, entities.AuditUserCreated, users[i].ID, entities.UserChanges(nil, &users[i]))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// record appends an audit event of action on the user with userID.
+func (u *userUsecase) record(ctx context.Context, action entities.AuditAction, userID uint64, changes []entities.AuditChange) error {
+	_, err := u.audit.Record(ctx, entities.AuditEvent{Action: action, UserID: userID, Changes: changes})
+	if err != nil {
+		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return nil
+}
+
 // Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
-func (u *userUsecase) FindAll(ctx context.Context) ([]entities.User, error) {
-	users, err := u.repo.FindAll(ctx)
+// Patch reads the user, applies patch and writes it back in one transaction.
+// When patch doesn't accept the stored version nothing is written and a
+// VersionConflictError is returned.
+func (u *userUsecase) Patch(ctx context.Context, id uint64, patch entities.UserPatch) (entities.User, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersUpdate, id)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	var patched entities.User
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := u.findByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !patch.Accepts(user.Version) {
+			return &VersionConflictError{Current: user.Version}
+		}
+
+		patched, err = u.update(ctx, user, patch.Apply(user))
+		return err
+	})
 	if err != nil {
-		return []entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		switch {
+		case errors.Is(err, ErrMsgDuplicateUser), errors.Is(err, ErrMsgFailedToUpdateUser),
+			errors.Is(err, ErrMsgUserNotFound), errors.Is(err, ErrMsgVersionConflict),
+			errors.Is(err, ErrMsgInternalServerError):
+			return entities.User{}, err
+		default:
+			return entities.User{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
 	}
 
-	return users, nil
+	return patched, nil
 }
 
 // Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
-func (u *userUsecase) Delete(ctx context.Context, id uint64) error {
-	err := u.repo.Delete(ctx, id)
+func (u *userUsecase) FindAll(ctx context.Context, query entities.UserListQuery) (entities.UserPage, error) {
+	err := u.authorizeList(ctx, query.Filter)
 	if err != nil {
-		if errors.Is(err, repository.ErrMsgUserNotFound) {
-			return fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
+		return entities.UserPage{}, err
+	}
+
+	page, err := u.repo.FindAll(ctx, query.Normalize())
+	if err != nil {
+		if errors.Is(err, repository.ErrMsgInvalidCursor) {
+			return entities.UserPage{}, fmt.Errorf("%w:%w", ErrMsgInvalidCursor, err)
+		}
+
+		return entities.UserPage{}, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return page, nil
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+// Stream hands every matching user to fn batch by batch without loading the
+// whole table. Errors returned by fn are passed through unchanged.
+func (u *userUsecase) Stream(ctx context.Context, filter entities.UserFilter, fn func(users []entities.User) error) error {
+	err := u.authorizeList(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	var callbackErr error
+	err = u.repo.Iterate(ctx, filter, entities.DefaultUserBatchSize, func(users []entities.User) error {
+		callbackErr = fn(users)
+		return callbackErr
+	})
+	if err != nil {
+		if callbackErr != nil {
+			return callbackErr
 		}
 
 		return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
@@ -116,3 +404,444 @@ func (u *userUsecase) Delete(ctx context.Context, id uint64) error {
 
 	return nil
 }
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *userUsecase) Delete(ctx context.Context, id uint64) error {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersDelete, id)
+	if err != nil {
+		return err
+	}
+
+	return u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		err := u.repo.Delete(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrMsgUserNotFound) {
+				return fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
+			}
+
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		return u.record(ctx, entities.AuditUserDeleted, id, entities.DeletedChange(true))
+	})
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+// CreateBatch saves users with batched inserts. Emails repeated in the batch
+// or already taken fail their item; in atomic mode any failure saves nothing
+// and ErrMsgBulkAborted wraps the first item error.
+func (u *userUsecase) CreateBatch(ctx context.Context, users []entities.User, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersCreate, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]entities.BulkResult, len(users))
+
+	emails := make([]string, len(users))
+	for i, user := range users {
+		emails[i] = user.Email
+	}
+
+	existing, err := u.repo.ExistingEmails(ctx, emails)
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	taken := make(map[string]bool, len(users))
+	for _, email := range existing {
+		taken[email] = true
+	}
+
+	var firstErr error
+	fail := func(i int, err error) {
+		results[i].Err = err
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	unique := make([]int, 0, len(users))
+	for i, user := range users {
+		if taken[user.Email] {
+			fail(i, fmt.Errorf("%w: email %q already exists", ErrMsgDuplicateUser, user.Email))
+			continue
+		}
+
+		taken[user.Email] = true
+		unique = append(unique, i)
+	}
+
+	if mode == entities.BulkAtomic && firstErr != nil {
+		rollBack(results, unique)
+		return results, fmt.Errorf("%w:%w", ErrMsgBulkAborted, firstErr)
+	}
+
+	hashed, hashErrs, err := hashPasswords(ctx, users, unique)
+	if err != nil {
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	// a password bcrypt can't hash fails its own item only
+	pending := make([]int, 0, len(unique))
+	batch := make([]entities.User, 0, len(unique))
+	for j, i := range unique {
+		if hashErrs[j] != nil {
+			fail(i, hashErrs[j])
+			continue
+		}
+
+		pending = append(pending, i)
+		batch = append(batch, hashed[j])
+	}
+
+	if mode == entities.BulkAtomic && firstErr != nil {
+		rollBack(results, pending)
+		return results, fmt.Errorf("%w:%w", ErrMsgBulkAborted, firstErr)
+	}
+
+	var saved []entities.User
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		saved, err = u.repo.SaveBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		return u.recordCreated(ctx, saved)
+	})
+	if err == nil {
+		for j, i := range pending {
+			results[i].User = saved[j]
+		}
+
+		return results, nil
+	}
+
+	if mode == entities.BulkAtomic {
+		err = u.createError(err)
+		for _, i := range pending {
+			results[i].Err = err
+		}
+
+		return results, fmt.Errorf("%w:%w", ErrMsgBulkAborted, err)
+	}
+
+	// an email was taken after the check, find out which one by saving one at a time
+	for j, i := range pending {
+		results[i].User, results[i].Err = u.create(ctx, batch[j])
+	}
+
+	return results, nil
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+// PatchBatch applies each patch like Patch. In atomic mode all patches share
+// one transaction that rolls back on the first failure. Permissions are
+// checked per patch, so a caller may patch their own record in a batch.
+func (u *userUsecase) PatchBatch(ctx context.Context, patches []entities.UserBatchPatch, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	results := make([]entities.BulkResult, len(patches))
+
+	if mode != entities.BulkAtomic {
+		for i, patch := range patches {
+			results[i].User, results[i].Err = u.Patch(ctx, patch.ID, patch.Patch)
+		}
+
+		return results, nil
+	}
+
+	failed := -1
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		for i, patch := range patches {
+			user, err := u.Patch(ctx, patch.ID, patch.Patch)
+			if err != nil {
+				failed = i
+				return err
+			}
+			results[i].User = user
+		}
+
+		return nil
+	})
+	if err != nil {
+		if failed < 0 {
+			return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		results[failed].Err = err
+		rollBack(results, indexesExcept(len(patches), failed))
+		return results, fmt.Errorf("%w:%w", ErrMsgBulkAborted, err)
+	}
+
+	return results, nil
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+// DeleteBatch soft-deletes ids in one statement. Ids that don't name an active
+// user fail with ErrMsgUserNotFound; in atomic mode they abort the batch.
+func (u *userUsecase) DeleteBatch(ctx context.Context, ids []uint64, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersDelete, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]entities.BulkResult, len(ids))
+
+	var missing []int
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		deleted, err := u.repo.DeleteBatch(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+		}
+
+		found := make(map[uint64]bool, len(deleted))
+		for _, id := range deleted {
+			found[id] = true
+
+			err = u.record(ctx, entities.AuditUserDeleted, id, entities.DeletedChange(true))
+			if err != nil {
+				return err
+			}
+		}
+
+		for i, id := range ids {
+			results[i].User.ID = id
+			if !found[id] {
+				results[i].Err = fmt.Errorf("%w: id %d", ErrMsgUserNotFound, id)
+				missing = append(missing, i)
+			}
+		}
+
+		if mode == entities.BulkAtomic && len(missing) > 0 {
+			return results[missing[0]].Err
+		}
+
+		return nil
+	})
+	if err != nil {
+		if len(missing) == 0 {
+			return nil, err
+		}
+
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = ErrMsgBulkRolledBack
+			}
+		}
+		return results, fmt.Errorf("%w:%w", ErrMsgBulkAborted, err)
+	}
+
+	return results, nil
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+// Import creates users in batches inside one transaction, handling emails
+// that are already taken according to options.OnConflict. A user repeating
+// the email of an earlier one is rejected as a duplicate whatever the policy.
+// With ConflictFail the first taken email rolls the whole import back and
+// ErrMsgBulkAborted wraps the cause. Updating taken emails needs the
+// permission to update any user on top of creating them.
+func (u *userUsecase) Import(ctx context.Context, users []entities.User, options entities.ImportOptions) ([]entities.ImportResult, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersCreate, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.OnConflict == entities.ConflictUpdate {
+		err = u.authorizer.Authorize(ctx, entities.PermUsersUpdate, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]entities.ImportResult, len(users))
+
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		seen := make(map[string]bool, len(users))
+		for start := 0; start < len(users); start += entities.DefaultUserBatchSize {
+			end := min(start+entities.DefaultUserBatchSize, len(users))
+
+			err := u.importBatch(ctx, users[start:end], results[start:end], seen, options.OnConflict)
+			if err != nil {
+				return err
+			}
+		}
+
+		if options.DryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		return results, nil
+	case errors.Is(err, errDryRun):
+		// nothing was inserted, so created users have no id to report
+		for i := range results {
+			if results[i].Outcome == entities.ImportCreated {
+				results[i].User.ID = 0
+			}
+		}
+		return results, nil
+	case errors.Is(err, ErrMsgDuplicateUser):
+		for i := range results {
+			if results[i].Err == nil {
+				results[i] = entities.ImportResult{User: users[i], Err: ErrMsgBulkRolledBack}
+			}
+		}
+		return results, fmt.Errorf("%w:%w", ErrMsgBulkAborted, err)
+	default:
+		return nil, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+}
+
+// importBatch imports one batch of users into the matching slice of results.
+// seen holds the emails of earlier rows of the import.
+func (u *userUsecase) importBatch(ctx context.Context, users []entities.User, results []entities.ImportResult, seen map[string]bool, onConflict entities.ConflictPolicy) error {
+	emails := make([]string, len(users))
+	for i, user := range users {
+		emails[i] = user.Email
+	}
+
+	existing, err := u.repo.ExistingEmails(ctx, emails)
+	if err != nil {
+		return err
+	}
+
+	taken := make(map[string]bool, len(existing))
+	for _, email := range existing {
+		taken[email] = true
+	}
+
+	fresh := make([]int, 0, len(users))
+	for i, user := range users {
+		switch {
+		case seen[user.Email]:
+			results[i] = entities.ImportResult{User: user, Err: fmt.Errorf("%w: email %q repeats an earlier row", ErrMsgDuplicateUser, user.Email)}
+		case !taken[user.Email]:
+			fresh = append(fresh, i)
+		case onConflict == entities.ConflictSkip:
+			results[i] = entities.ImportResult{User: user, Outcome: entities.ImportSkipped}
+		case onConflict == entities.ConflictUpdate:
+			stored, err := u.repo.FindByEmail(ctx, user.Email)
+			if err != nil {
+				return err
+			}
+
+			user.ID, user.Version = stored.ID, stored.Version
+			updated, err := u.update(ctx, stored, user)
+			if err != nil {
+				return err
+			}
+			results[i] = entities.ImportResult{User: updated, Outcome: entities.ImportUpdated}
+		default:
+			results[i] = entities.ImportResult{User: user, Err: fmt.Errorf("%w: email %q already exists", ErrMsgDuplicateUser, user.Email)}
+			return results[i].Err
+		}
+
+		seen[user.Email] = true
+	}
+
+	batch := make([]entities.User, len(fresh))
+	for j, i := range fresh {
+		batch[j] = users[i]
+	}
+
+	saved, err := u.repo.SaveBatch(ctx, batch)
+	if err != nil {
+		return u.createError(err)
+	}
+
+	for j, i := range fresh {
+		results[i] = entities.ImportResult{User: saved[j], Outcome: entities.ImportCreated}
+	}
+
+	err = u.recordCreated(ctx, saved)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *userUsecase) Restore(ctx context.Context, id uint64) (entities.User, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersDelete, id)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	var user entities.User
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err = u.repo.Restore(ctx, id)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrMsgUserNotFound):
+				return fmt.Errorf("%w:%w", ErrMsgUserNotFound, err)
+			case errors.Is(err, repository.ErrMsgDuplicateUser):
+				return fmt.Errorf("%w:%w", ErrMsgDuplicateUser, err)
+			default:
+				return fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+			}
+		}
+
+		return u.record(ctx, entities.AuditUserRestored, id, entities.DeletedChange(false))
+	})
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	return user, nil
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+// Purge permanently removes users that were soft-deleted more than olderThan ago.
+// It is not audited per user, their deletion was recorded when it happened.
+func (u *userUsecase) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersDelete, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := u.repo.Purge(ctx, u.clock.Now().Add(-olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("%w:%w", ErrMsgInternalServerError, err)
+	}
+
+	return purged, nil
+}
+
+// authorizeList checks that the principal in ctx may list the users filter
+// matches. Soft-deleted users are only listed to those who may delete users.
+func (u *userUsecase) authorizeList(ctx context.Context, filter entities.UserFilter) error {
+	err := u.authorizer.Authorize(ctx, entities.PermUsersRead, 0)
+	if err != nil {
+		return err
+	}
+
+	if filter.IncludeDeleted {
+		return u.authorizer.Authorize(ctx, entities.PermUsersDelete, 0)
+	}
+
+	return nil
+}
+
+// rollBack marks the results at indexes as not applied.
+func rollBack(results []entities.BulkResult, indexes []int) {
+	for _, i := range indexes {
+		results[i] = entities.BulkResult{Err: ErrMsgBulkRolledBack}
+	}
+}
+
+// indexesExcept returns 0..n-1 without skip.
+func indexesExcept(n int, skip int) []int {
+	indexes := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if i != skip {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
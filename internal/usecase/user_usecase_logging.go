@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/go-playground/validator/v10"
+)
+
+// expectedUserErrors are the outcomes of user operations a caller caused,
+// logged at debug level; any other error is a failure of the service.
+var expectedUserErrors = []error{
+	ErrMsgDuplicateUser,
+	ErrMsgUserNotFound,
+	ErrMsgInvalidCursor,
+	ErrMsgInvalidPassword,
+	ErrMsgVersionConflict,
+	ErrMsgBulkAborted,
+	ErrMsgForbidden,
+	ErrMsgUnauthenticated,
+}
+
+// Definition Struct (Class)
+// loggingUserUsecase logs the operations of next that fail, with the user
+// they were about when there is one. The logger adds who asked from ctx.
+type loggingUserUsecase struct {
+	next   UserUsecase
+	logger *slog.Logger
+}
+
+// Definition Constructor
+func NewLoggingUserUsecase(next UserUsecase, logger *slog.Logger) UserUsecase {
+	return &loggingUserUsecase{next: next, logger: logger.With(slog.String("component", "usecase"))}
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) Create(ctx context.Context, user entities.User) (entities.User, error) {
+	created, err := u.next.Create(ctx, user)
+	u.log(ctx, OpUserCreate, err)
+	return created, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) FindByID(ctx context.Context, id uint64) (entities.User, error) {
+	user, err := u.next.FindByID(ctx, id)
+	u.log(ctx, OpUserFind, err, slog.Uint64("user_id", id))
+	return user, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) Update(ctx context.Context, user entities.User) (entities.User, error) {
+	updated, err := u.next.Update(ctx, user)
+	u.log(ctx, OpUserUpdate, err, slog.Uint64("user_id", user.ID))
+	return updated, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) Patch(ctx context.Context, id uint64, patch entities.UserPatch) (entities.User, error) {
+	patched, err := u.next.Patch(ctx, id, patch)
+	u.log(ctx, OpUserPatch, err, slog.Uint64("user_id", id))
+	return patched, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) FindAll(ctx context.Context, query entities.UserListQuery) (entities.UserPage, error) {
+	page, err := u.next.FindAll(ctx, query)
+	u.log(ctx, OpUserList, err)
+	return page, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) Stream(ctx context.Context, filter entities.UserFilter, fn func(users []entities.User) error) error {
+	err := u.next.Stream(ctx, filter, fn)
+	u.log(ctx, OpUserStream, err)
+	return err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) Delete(ctx context.Context, id uint64) error {
+	err := u.next.Delete(ctx, id)
+	u.log(ctx, OpUserDelete, err, slog.Uint64("user_id", id))
+	return err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) CreateBatch(ctx context.Context, users []entities.User, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	results, err := u.next.CreateBatch(ctx, users, mode)
+	u.log(ctx, OpUserCreateBatch, err, slog.Int("items", len(users)))
+	return results, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) PatchBatch(ctx context.Context, patches []entities.UserBatchPatch, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	results, err := u.next.PatchBatch(ctx, patches, mode)
+	u.log(ctx, OpUserPatchBatch, err, slog.Int("items", len(patches)))
+	return results, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) DeleteBatch(ctx context.Context, ids []uint64, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	results, err := u.next.DeleteBatch(ctx, ids, mode)
+	u.log(ctx, OpUserDeleteBatch, err, slog.Int("items", len(ids)))
+	return results, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) Import(ctx context.Context, users []entities.User, options entities.ImportOptions) ([]entities.ImportResult, error) {
+	results, err := u.next.Import(ctx, users, options)
+	u.log(ctx, OpUserImport, err, slog.Int("items", len(users)))
+	return results, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) Restore(ctx context.Context, id uint64) (entities.User, error) {
+	user, err := u.next.Restore(ctx, id)
+	u.log(ctx, OpUserRestore, err, slog.Uint64("user_id", id))
+	return user, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *loggingUserUsecase) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	purged, err := u.next.Purge(ctx, olderThan)
+	u.log(ctx, OpUserPurge, err)
+	return purged, err
+}
+
+// log records the failure of operation, nothing when err is nil.
+func (u *loggingUserUsecase) log(ctx context.Context, operation string, err error, attrs ...slog.Attr) {
+	if err == nil {
+		return
+	}
+
+	level := slog.LevelError
+	if expectedUserError(err) {
+		level = slog.LevelDebug
+	}
+
+	attrs = append(attrs, slog.String("operation", operation), slog.Any("error", err))
+	u.logger.LogAttrs(ctx, level, "user operation failed", attrs...)
+}
+
+// expectedUserError reports whether err is an outcome the caller caused,
+// such as asking for a missing user or sending an invalid one.
+func expectedUserError(err error) bool {
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		return true
+	}
+
+	for _, expected := range expectedUserErrors {
+		if errors.Is(err, expected) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/config"
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/logger"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingUserUsecase(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+
+	setup := func(t *testing.T, level string) (usecase.UserUsecase, *bytes.Buffer) {
+		var buf bytes.Buffer
+		log, err := logger.New(&buf, config.Log{Level: level, Format: config.LogFormatJSON})
+		require.NoError(t, err)
+
+		next := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validator.New())
+		return usecase.NewLoggingUserUsecase(next, log), &buf
+	}
+
+	ctx := requestid.WithRequestID(context.Background(), "req-1")
+
+	t.Run("Failure", func(t *testing.T) {
+		userUsecase, buf := setup(t, "info")
+		mockRepo.On("FindByID", ctx, uint64(7)).Return(entities.User{}, errors.New("dial tcp: password=hunter2 refused")).Once()
+
+		_, err := userUsecase.FindByID(ctx, 7)
+		require.Error(t, err)
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "ERROR", record["level"])
+		assert.Equal(t, "user.find", record["operation"])
+		assert.Equal(t, float64(7), record["user_id"])
+		assert.Equal(t, "req-1", record["request_id"])
+		assert.NotContains(t, record["error"], "hunter2")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ExpectedOutcome", func(t *testing.T) {
+		userUsecase, buf := setup(t, "info")
+		mockRepo.On("FindByID", ctx, uint64(7)).Return(entities.User{}, repository.ErrMsgUserNotFound).Once()
+
+		_, err := userUsecase.FindByID(ctx, 7)
+		assert.ErrorIs(t, err, usecase.ErrMsgUserNotFound)
+
+		assert.Empty(t, buf.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ExpectedOutcomeAtDebug", func(t *testing.T) {
+		userUsecase, buf := setup(t, "debug")
+		mockRepo.On("FindByID", ctx, uint64(7)).Return(entities.User{}, repository.ErrMsgUserNotFound).Once()
+
+		_, _ = userUsecase.FindByID(ctx, 7)
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "DEBUG", record["level"])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		userUsecase, buf := setup(t, "debug")
+		mockRepo.On("FindByID", ctx, uint64(7)).Return(entities.User{ID: 7}, nil).Once()
+
+		_, err := userUsecase.FindByID(ctx, 7)
+		require.NoError(t, err)
+
+		assert.Empty(t, buf.String())
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/go-playground/validator/v10"
+)
+
+// Definition Outcomes
+// A successful operation ends in the outcome naming what it did, a failed
+// one in the outcome naming why.
+const (
+	OutcomeCreated   = "created"
+	OutcomeFound     = "found"
+	OutcomeUpdated   = "updated"
+	OutcomeDeleted   = "deleted"
+	OutcomeRestored  = "restored"
+	OutcomeOK        = "ok"
+	OutcomeDuplicate = "duplicate"
+	OutcomeNotFound  = "not_found"
+	OutcomeConflict  = "conflict"
+	OutcomeInvalid   = "invalid"
+	OutcomeDenied    = "denied"
+	OutcomeAborted   = "aborted"
+	OutcomeError     = "error"
+)
+
+// Definition Interface (Rules)
+// OperationCounter counts the operations of usecases by outcome.
+type OperationCounter interface {
+	CountOperation(operation string, outcome string)
+}
+
+// Definition Struct (Class)
+// metricsUserUsecase counts every operation of next by its outcome.
+type metricsUserUsecase struct {
+	next    UserUsecase
+	counter OperationCounter
+}
+
+// Definition Constructor
+func NewMetricsUserUsecase(next UserUsecase, counter OperationCounter) UserUsecase {
+	return &metricsUserUsecase{next: next, counter: counter}
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) Create(ctx context.Context, user entities.User) (entities.User, error) {
+	created, err := u.next.Create(ctx, user)
+	u.count(OpUserCreate, OutcomeCreated, err)
+	return created, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) FindByID(ctx context.Context, id uint64) (entities.User, error) {
+	user, err := u.next.FindByID(ctx, id)
+	u.count(OpUserFind, OutcomeFound, err)
+	return user, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) Update(ctx context.Context, user entities.User) (entities.User, error) {
+	updated, err := u.next.Update(ctx, user)
+	u.count(OpUserUpdate, OutcomeUpdated, err)
+	return updated, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) Patch(ctx context.Context, id uint64, patch entities.UserPatch) (entities.User, error) {
+	patched, err := u.next.Patch(ctx, id, patch)
+	u.count(OpUserPatch, OutcomeUpdated, err)
+	return patched, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) FindAll(ctx context.Context, query entities.UserListQuery) (entities.UserPage, error) {
+	page, err := u.next.FindAll(ctx, query)
+	u.count(OpUserList, OutcomeOK, err)
+	return page, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) Stream(ctx context.Context, filter entities.UserFilter, fn func(users []entities.User) error) error {
+	err := u.next.Stream(ctx, filter, fn)
+	u.count(OpUserStream, OutcomeOK, err)
+	return err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) Delete(ctx context.Context, id uint64) error {
+	err := u.next.Delete(ctx, id)
+	u.count(OpUserDelete, OutcomeDeleted, err)
+	return err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) CreateBatch(ctx context.Context, users []entities.User, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	results, err := u.next.CreateBatch(ctx, users, mode)
+	u.count(OpUserCreateBatch, OutcomeOK, err)
+	return results, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) PatchBatch(ctx context.Context, patches []entities.UserBatchPatch, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	results, err := u.next.PatchBatch(ctx, patches, mode)
+	u.count(OpUserPatchBatch, OutcomeOK, err)
+	return results, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) DeleteBatch(ctx context.Context, ids []uint64, mode entities.BulkMode) ([]entities.BulkResult, error) {
+	results, err := u.next.DeleteBatch(ctx, ids, mode)
+	u.count(OpUserDeleteBatch, OutcomeOK, err)
+	return results, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) Import(ctx context.Context, users []entities.User, options entities.ImportOptions) ([]entities.ImportResult, error) {
+	results, err := u.next.Import(ctx, users, options)
+	u.count(OpUserImport, OutcomeOK, err)
+	return results, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) Restore(ctx context.Context, id uint64) (entities.User, error) {
+	user, err := u.next.Restore(ctx, id)
+	u.count(OpUserRestore, OutcomeRestored, err)
+	return user, err
+}
+
+// Definition Implement Methods (Create, Update, Delete, FindByID, FindAll)
+func (u *metricsUserUsecase) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	purged, err := u.next.Purge(ctx, olderThan)
+	u.count(OpUserPurge, OutcomeDeleted, err)
+	return purged, err
+}
+
+// count records operation with success as its outcome, or the outcome err
+// stands for.
+func (u *metricsUserUsecase) count(operation string, success string, err error) {
+	u.counter.CountOperation(operation, outcomeOf(success, err))
+}
+
+// outcomeOf returns success when err is nil and the outcome of err otherwise.
+func outcomeOf(success string, err error) string {
+	var invalid validator.ValidationErrors
+
+	switch {
+	case err == nil:
+		return success
+	case errors.Is(err, ErrMsgDuplicateUser):
+		return OutcomeDuplicate
+	case errors.Is(err, ErrMsgUserNotFound):
+		return OutcomeNotFound
+	case errors.Is(err, ErrMsgVersionConflict):
+		return OutcomeConflict
+	case errors.Is(err, ErrMsgForbidden), errors.Is(err, ErrMsgUnauthenticated):
+		return OutcomeDenied
+	case errors.Is(err, ErrMsgInvalidCursor), errors.Is(err, ErrMsgInvalidPassword), errors.As(err, &invalid):
+		return OutcomeInvalid
+	case errors.Is(err, ErrMsgBulkAborted):
+		return OutcomeAborted
+	default:
+		return OutcomeError
+	}
+}
@@ -0,0 +1,72 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/repository"
+	"github.com/alirezaghasemi/user-manager/internal/usecase"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// countedOperations records what a metrics decorator counted.
+type countedOperations map[string]int
+
+func (c countedOperations) CountOperation(operation string, outcome string) {
+	c[operation+" "+outcome]++
+}
+
+func TestMetricsUserUsecase(t *testing.T) {
+	mockRepo := &MockUserRepository{}
+	counted := countedOperations{}
+
+	next := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validator.New())
+	userUsecase := usecase.NewMetricsUserUsecase(next, counted)
+
+	ctx := context.Background()
+
+	t.Run("Created", func(t *testing.T) {
+		mockRepo.On("Save", mock.Anything, mock.Anything).Return(entities.User{ID: 1, Email: "ali@gmail.com"}, nil).Once()
+
+		_, err := userUsecase.Create(ctx, entities.User{Email: "ali@gmail.com"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, counted[usecase.OpUserCreate+" "+usecase.OutcomeCreated])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		mockRepo.On("Save", mock.Anything, mock.Anything).Return(entities.User{}, repository.ErrMsgDuplicateUser).Once()
+
+		_, err := userUsecase.Create(ctx, entities.User{Email: "ali@gmail.com"})
+
+		assert.ErrorIs(t, err, usecase.ErrMsgDuplicateUser)
+		assert.Equal(t, 1, counted[usecase.OpUserCreate+" "+usecase.OutcomeDuplicate])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockRepo.On("FindByID", ctx, uint64(7)).Return(entities.User{}, repository.ErrMsgUserNotFound).Once()
+
+		_, err := userUsecase.FindByID(ctx, 7)
+
+		assert.ErrorIs(t, err, usecase.ErrMsgUserNotFound)
+		assert.Equal(t, 1, counted[usecase.OpUserFind+" "+usecase.OutcomeNotFound])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockRepo.On("FindByID", ctx, uint64(7)).Return(entities.User{}, errors.New("connection refused")).Once()
+
+		_, err := userUsecase.FindByID(ctx, 7)
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, counted[usecase.OpUserFind+" "+usecase.OutcomeError])
+		mockRepo.AssertExpectations(t)
+	})
+}
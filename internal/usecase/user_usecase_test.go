@@ -3,14 +3,21 @@ package usecase_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alirezaghasemi/user-manager/internal/entities"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/actor"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/clock"
+	"github.com/alirezaghasemi/user-manager/internal/pkg/requestid"
 	"github.com/alirezaghasemi/user-manager/internal/repository"
 	"github.com/alirezaghasemi/user-manager/internal/usecase"
 	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockUserRepository struct {
@@ -22,19 +29,57 @@ func (m *MockUserRepository) FindByID(ctx context.Context, id uint64) (entities.
 	return args.Get(0).(entities.User), args.Error(1)
 }
 
+func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (entities.User, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(entities.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Save(ctx context.Context, user entities.User) (entities.User, error) {
 	args := m.Called(ctx, user)
 	return args.Get(0).(entities.User), args.Error(1)
 }
 
+func (m *MockUserRepository) SaveBatch(ctx context.Context, users []entities.User) ([]entities.User, error) {
+	args := m.Called(ctx, users)
+	saved, _ := args.Get(0).([]entities.User)
+	return saved, args.Error(1)
+}
+
+func (m *MockUserRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	args := m.Called(ctx, emails)
+	existing, _ := args.Get(0).([]string)
+	return existing, args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteBatch(ctx context.Context, ids []uint64) ([]uint64, error) {
+	args := m.Called(ctx, ids)
+	deleted, _ := args.Get(0).([]uint64)
+	return deleted, args.Error(1)
+}
+
 func (m *MockUserRepository) Update(ctx context.Context, user entities.User) (entities.User, error) {
 	args := m.Called(ctx, user)
 	return args.Get(0).(entities.User), args.Error(1)
 }
 
-func (m *MockUserRepository) FindAll(ctx context.Context) ([]entities.User, error) {
-	args := m.Called(ctx)
-	return args.Get(0).([]entities.User), args.Error(1)
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id uint64, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id uint64, email string, at time.Time) error {
+	args := m.Called(ctx, id, email, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindAll(ctx context.Context, query entities.UserListQuery) (entities.UserPage, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(entities.UserPage), args.Error(1)
+}
+
+func (m *MockUserRepository) Iterate(ctx context.Context, filter entities.UserFilter, batchSize int, fn func(users []entities.User) error) error {
+	args := m.Called(ctx, filter, batchSize, fn)
+	return args.Error(0)
 }
 
 func (m *MockUserRepository) Delete(ctx context.Context, id uint64) error {
@@ -42,13 +87,23 @@ func (m *MockUserRepository) Delete(ctx context.Context, id uint64) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) Restore(ctx context.Context, id uint64) (entities.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestUserUsecase_FindByID(t *testing.T) {
 	// make instance of validator
 	validate := validator.New()
 
 	mockRepo := &MockUserRepository{}
 
-	userUsecase := usecase.NewUserUsecase(mockRepo, validate)
+	userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
 
 	// definition variables for test
 	ctx := context.Background()
@@ -100,7 +155,7 @@ func TestUserUsecase_FindAll(t *testing.T) {
 
 	mockRepo := &MockUserRepository{}
 
-	userUsecase := usecase.NewUserUsecase(mockRepo, validate)
+	userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
 
 	ctx := context.Background()
 
@@ -121,24 +176,120 @@ func TestUserUsecase_FindAll(t *testing.T) {
 		},
 	}
 
+	query := entities.UserListQuery{
+		Filter: entities.UserFilter{Name: "test"},
+		Sort:   []entities.UserSort{{Field: entities.UserSortByAge, Direction: entities.SortDesc}},
+		Limit:  10,
+		Offset: 20,
+	}
+
+	expectedPage := entities.UserPage{
+		Users:  expectedUsers,
+		Total:  22,
+		Limit:  10,
+		Offset: 20,
+	}
+
 	t.Run("Success", func(t *testing.T) {
-		mockRepo.On("FindAll", ctx).Return(expectedUsers, nil).Once()
+		mockRepo.On("FindAll", ctx, query).Return(expectedPage, nil).Once()
 
-		users, err := userUsecase.FindAll(ctx)
+		page, err := userUsecase.FindAll(ctx, query)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPage, page)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AppliesDefaultLimit", func(t *testing.T) {
+		normalized := entities.UserListQuery{Limit: entities.DefaultUserListLimit}
+		mockRepo.On("FindAll", ctx, normalized).Return(entities.UserPage{Users: []entities.User{}, Limit: entities.DefaultUserListLimit}, nil).Once()
+
+		_, err := userUsecase.FindAll(ctx, entities.UserListQuery{Offset: -5})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("CapsLimit", func(t *testing.T) {
+		normalized := entities.UserListQuery{Limit: entities.MaxUserListLimit}
+		mockRepo.On("FindAll", ctx, normalized).Return(entities.UserPage{Users: []entities.User{}, Limit: entities.MaxUserListLimit}, nil).Once()
+
+		_, err := userUsecase.FindAll(ctx, entities.UserListQuery{Limit: 5000})
 
 		assert.NoError(t, err)
-		assert.Equal(t, expectedUsers, users)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("InternalServerError", func(t *testing.T) {
-		mockRepo.On("FindAll", ctx).Return([]entities.User{}, repository.ErrMsgInternalServerError).Once()
+		mockRepo.On("FindAll", ctx, query).Return(entities.UserPage{}, repository.ErrMsgInternalServerError).Once()
 
-		users, err := userUsecase.FindAll(ctx)
+		page, err := userUsecase.FindAll(ctx, query)
 
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, usecase.ErrMsgInternalServerError))
-		assert.Equal(t, []entities.User{}, users)
+		assert.Equal(t, entities.UserPage{}, page)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserUsecase_Stream(t *testing.T) {
+	validate := validator.New()
+
+	mockRepo := &MockUserRepository{}
+
+	userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+	ctx := context.Background()
+	filter := entities.UserFilter{Email: "gmail.com"}
+	batches := [][]entities.User{
+		{{ID: 1, Email: "test1@gmail.com"}, {ID: 2, Email: "test2@gmail.com"}},
+		{{ID: 3, Email: "test3@gmail.com"}},
+	}
+
+	iterate := func(args mock.Arguments) {
+		fn := args.Get(3).(func(users []entities.User) error)
+		for _, batch := range batches {
+			if err := fn(batch); err != nil {
+				return
+			}
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.On("Iterate", ctx, filter, entities.DefaultUserBatchSize, mock.Anything).Run(iterate).Return(nil).Once()
+
+		var seen []uint64
+		err := userUsecase.Stream(ctx, filter, func(users []entities.User) error {
+			for _, user := range users {
+				seen = append(seen, user.ID)
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 3}, seen)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("CallbackError", func(t *testing.T) {
+		writeErr := errors.New("client went away")
+		mockRepo.On("Iterate", ctx, filter, entities.DefaultUserBatchSize, mock.Anything).Run(iterate).Return(writeErr).Once()
+
+		err := userUsecase.Stream(ctx, filter, func(users []entities.User) error {
+			return writeErr
+		})
+
+		assert.ErrorIs(t, err, writeErr)
+		assert.False(t, errors.Is(err, usecase.ErrMsgInternalServerError))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InternalServerError", func(t *testing.T) {
+		mockRepo.On("Iterate", ctx, filter, entities.DefaultUserBatchSize, mock.Anything).Return(repository.ErrMsgInternalServerError).Once()
+
+		err := userUsecase.Stream(ctx, filter, func(users []entities.User) error { return nil })
+
+		assert.True(t, errors.Is(err, usecase.ErrMsgInternalServerError))
 		mockRepo.AssertExpectations(t)
 	})
 }
@@ -148,7 +299,7 @@ func TestUserUsecase_Create(t *testing.T) {
 
 	mockRepo := &MockUserRepository{}
 
-	userUsecase := usecase.NewUserUsecase(mockRepo, validate)
+	userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
 
 	ctx := context.Background()
 	createUser := entities.User{
@@ -168,7 +319,7 @@ func TestUserUsecase_Create(t *testing.T) {
 	}
 
 	t.Run("Success", func(t *testing.T) {
-		mockRepo.On("Save", ctx, createUser).Return(createdUser, nil).Once()
+		mockRepo.On("Save", mock.Anything, createUser).Return(createdUser, nil).Once()
 
 		user, err := userUsecase.Create(ctx, createUser)
 
@@ -178,7 +329,7 @@ func TestUserUsecase_Create(t *testing.T) {
 	})
 
 	t.Run("DuplicateUser", func(t *testing.T) {
-		mockRepo.On("Save", ctx, createUser).Return(entities.User{}, repository.ErrMsgDuplicateUser).Once()
+		mockRepo.On("Save", mock.Anything, createUser).Return(entities.User{}, repository.ErrMsgDuplicateUser).Once()
 
 		_, err := userUsecase.Create(ctx, createUser)
 
@@ -189,7 +340,7 @@ func TestUserUsecase_Create(t *testing.T) {
 	})
 
 	t.Run("FailedToSaveUser", func(t *testing.T) {
-		mockRepo.On("Save", ctx, createUser).Return(entities.User{}, repository.ErrMsgFailedToSaveUser).Once()
+		mockRepo.On("Save", mock.Anything, createUser).Return(entities.User{}, repository.ErrMsgFailedToSaveUser).Once()
 
 		_, err := userUsecase.Create(ctx, createUser)
 
@@ -200,7 +351,7 @@ func TestUserUsecase_Create(t *testing.T) {
 	})
 
 	t.Run("InternalServerError", func(t *testing.T) {
-		mockRepo.On("Save", ctx, createUser).Return(entities.User{}, repository.ErrMsgInternalServerError).Once()
+		mockRepo.On("Save", mock.Anything, createUser).Return(entities.User{}, repository.ErrMsgInternalServerError).Once()
 
 		_, err := userUsecase.Create(ctx, createUser)
 
@@ -217,7 +368,7 @@ func TestUserUsecase_Update(t *testing.T) {
 
 	mockRepo := &MockUserRepository{}
 
-	userUsecase := usecase.NewUserUsecase(mockRepo, validate)
+	userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
 
 	ctx := context.Background()
 
@@ -230,7 +381,8 @@ func TestUserUsecase_Update(t *testing.T) {
 	}
 
 	t.Run("Success", func(t *testing.T) {
-		mockRepo.On("Update", ctx, updateUser).Return(updateUser, nil).Once()
+		mockRepo.On("FindByID", mock.Anything, updateUser.ID).Return(updateUser, nil).Once()
+		mockRepo.On("Update", mock.Anything, updateUser).Return(updateUser, nil).Once()
 
 		user, err := userUsecase.Update(ctx, updateUser)
 
@@ -240,7 +392,8 @@ func TestUserUsecase_Update(t *testing.T) {
 	})
 
 	t.Run("DuplicateUser", func(t *testing.T) {
-		mockRepo.On("Update", ctx, updateUser).Return(entities.User{}, repository.ErrMsgDuplicateUser).Once()
+		mockRepo.On("FindByID", mock.Anything, updateUser.ID).Return(updateUser, nil).Once()
+		mockRepo.On("Update", mock.Anything, updateUser).Return(entities.User{}, repository.ErrMsgDuplicateUser).Once()
 
 		user, err := userUsecase.Update(ctx, updateUser)
 
@@ -254,7 +407,8 @@ func TestUserUsecase_Update(t *testing.T) {
 	})
 
 	t.Run("FailedToUpdateUser", func(t *testing.T) {
-		mockRepo.On("Update", ctx, updateUser).Return(entities.User{}, repository.ErrMsgFailedToUpdateUser).Once()
+		mockRepo.On("FindByID", mock.Anything, updateUser.ID).Return(updateUser, nil).Once()
+		mockRepo.On("Update", mock.Anything, updateUser).Return(entities.User{}, repository.ErrMsgFailedToUpdateUser).Once()
 
 		user, err := userUsecase.Update(ctx, updateUser)
 
@@ -268,7 +422,8 @@ func TestUserUsecase_Update(t *testing.T) {
 	})
 
 	t.Run("UserNotFound", func(t *testing.T) {
-		mockRepo.On("Update", ctx, updateUser).Return(entities.User{}, repository.ErrMsgUserNotFound).Once()
+		mockRepo.On("FindByID", mock.Anything, updateUser.ID).Return(updateUser, nil).Once()
+		mockRepo.On("Update", mock.Anything, updateUser).Return(entities.User{}, repository.ErrMsgUserNotFound).Once()
 
 		user, err := userUsecase.Update(ctx, updateUser)
 
@@ -281,10 +436,23 @@ func TestUserUsecase_Update(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("VersionConflict", func(t *testing.T) {
+		mockRepo.On("FindByID", mock.Anything, updateUser.ID).Return(updateUser, nil).Once()
+		mockRepo.On("Update", mock.Anything, updateUser).Return(entities.User{}, repository.ErrMsgVersionConflict).Once()
+
+		user, err := userUsecase.Update(ctx, updateUser)
+
+		assert.ErrorIs(t, err, usecase.ErrMsgVersionConflict, "error should wrap usecase.ErrMsgVersionConflict")
+		assert.ErrorIs(t, err, repository.ErrMsgVersionConflict, "error should wrap repository.ErrMsgVersionConflict")
+		assert.Equal(t, entities.User{}, user)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("InternalServerError", func(t *testing.T) {
 		genericError := errors.New("database error")
 
-		mockRepo.On("Update", ctx, updateUser).Return(entities.User{}, genericError).Once()
+		mockRepo.On("FindByID", mock.Anything, updateUser.ID).Return(updateUser, nil).Once()
+		mockRepo.On("Update", mock.Anything, updateUser).Return(entities.User{}, genericError).Once()
 
 		user, err := userUsecase.Update(ctx, updateUser)
 
@@ -296,18 +464,92 @@ func TestUserUsecase_Update(t *testing.T) {
 	})
 }
 
+func TestUserUsecase_Patch(t *testing.T) {
+	validate := validator.New()
+
+	mockRepo := &MockUserRepository{}
+
+	userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+	ctx := context.Background()
+
+	stored := entities.User{
+		ID:      1,
+		Name:    "Test User 1",
+		Family:  "Test Family 1",
+		Email:   "test1@gmail.com",
+		Age:     31,
+		Version: 2,
+	}
+
+	name := "New Name"
+	version := 2
+	staleVersion := 1
+
+	t.Run("Success", func(t *testing.T) {
+		merged := stored
+		merged.Name = name
+		updated := merged
+		updated.Version = 3
+
+		mockRepo.On("FindByID", mock.Anything, stored.ID).Return(stored, nil).Once()
+		mockRepo.On("Update", mock.Anything, merged).Return(updated, nil).Once()
+
+		user, err := userUsecase.Patch(ctx, stored.ID, entities.UserPatch{Name: &name, Versions: []int{version}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, updated, user)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("StaleVersion", func(t *testing.T) {
+		mockRepo.On("FindByID", mock.Anything, stored.ID).Return(stored, nil).Once()
+
+		user, err := userUsecase.Patch(ctx, stored.ID, entities.UserPatch{Name: &name, Versions: []int{staleVersion}})
+
+		assert.ErrorIs(t, err, usecase.ErrMsgVersionConflict)
+		var conflict *usecase.VersionConflictError
+		if assert.ErrorAs(t, err, &conflict) {
+			assert.Equal(t, stored.Version, conflict.Current)
+		}
+		assert.Equal(t, entities.User{}, user)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("UserNotFound", func(t *testing.T) {
+		mockRepo.On("FindByID", mock.Anything, stored.ID).Return(entities.User{}, repository.ErrMsgUserNotFound).Once()
+
+		user, err := userUsecase.Patch(ctx, stored.ID, entities.UserPatch{Name: &name})
+
+		assert.ErrorIs(t, err, usecase.ErrMsgUserNotFound)
+		assert.Equal(t, entities.User{}, user)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("DuplicateUser", func(t *testing.T) {
+		mockRepo.On("FindByID", mock.Anything, stored.ID).Return(stored, nil).Once()
+		mockRepo.On("Update", mock.Anything, mock.Anything).Return(entities.User{}, repository.ErrMsgDuplicateUser).Once()
+
+		_, err := userUsecase.Patch(ctx, stored.ID, entities.UserPatch{Name: &name})
+
+		assert.ErrorIs(t, err, usecase.ErrMsgDuplicateUser)
+		assert.ErrorIs(t, err, repository.ErrMsgDuplicateUser)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestUserUsecase_Delete(t *testing.T) {
 	validate := validator.New()
 
 	mockRepo := &MockUserRepository{}
 
-	userUsecase := usecase.NewUserUsecase(mockRepo, validate)
+	userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
 
 	ctx := context.Background()
 	userID := uint64(1)
 
 	t.Run("Success", func(t *testing.T) {
-		mockRepo.On("Delete", ctx, userID).Return(nil).Once()
+		mockRepo.On("Delete", mock.Anything, userID).Return(nil).Once()
 
 		err := userUsecase.Delete(ctx, userID)
 
@@ -316,7 +558,7 @@ func TestUserUsecase_Delete(t *testing.T) {
 	})
 
 	t.Run("UserNotFound", func(t *testing.T) {
-		mockRepo.On("Delete", ctx, userID).Return(repository.ErrMsgUserNotFound).Once()
+		mockRepo.On("Delete", mock.Anything, userID).Return(repository.ErrMsgUserNotFound).Once()
 
 		err := userUsecase.Delete(ctx, userID)
 
@@ -327,7 +569,7 @@ func TestUserUsecase_Delete(t *testing.T) {
 	})
 
 	t.Run("InternalServerError", func(t *testing.T) {
-		mockRepo.On("Delete", ctx, userID).Return(repository.ErrMsgInternalServerError).Once()
+		mockRepo.On("Delete", mock.Anything, userID).Return(repository.ErrMsgInternalServerError).Once()
 
 		err := userUsecase.Delete(ctx, userID)
 
@@ -337,3 +579,490 @@ func TestUserUsecase_Delete(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestUserUsecase_Restore(t *testing.T) {
+	validate := validator.New()
+
+	mockRepo := &MockUserRepository{}
+
+	userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+	ctx := context.Background()
+	userID := uint64(1)
+	restoredUser := entities.User{
+		ID:     userID,
+		Name:   "Test User 1",
+		Family: "Test Family 1",
+		Email:  "test1@gmail.com",
+		Age:    31,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.On("Restore", mock.Anything, userID).Return(restoredUser, nil).Once()
+
+		user, err := userUsecase.Restore(ctx, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, restoredUser, user)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("UserNotFound", func(t *testing.T) {
+		mockRepo.On("Restore", mock.Anything, userID).Return(entities.User{}, repository.ErrMsgUserNotFound).Once()
+
+		_, err := userUsecase.Restore(ctx, userID)
+
+		assert.ErrorIs(t, err, usecase.ErrMsgUserNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("EmailTakenMeanwhile", func(t *testing.T) {
+		mockRepo.On("Restore", mock.Anything, userID).Return(entities.User{}, repository.ErrMsgDuplicateUser).Once()
+
+		_, err := userUsecase.Restore(ctx, userID)
+
+		assert.ErrorIs(t, err, usecase.ErrMsgDuplicateUser)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserUsecase_Purge(t *testing.T) {
+	validate := validator.New()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	mockRepo := &MockUserRepository{}
+
+	userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.Fixed(now), validate)
+
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.On("Purge", ctx, now.Add(-30*24*time.Hour)).Return(int64(3), nil).Once()
+
+		purged, err := userUsecase.Purge(ctx, 30*24*time.Hour)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), purged)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InternalServerError", func(t *testing.T) {
+		mockRepo.On("Purge", ctx, mock.Anything).Return(int64(0), repository.ErrMsgInternalServerError).Once()
+
+		_, err := userUsecase.Purge(ctx, time.Hour)
+
+		assert.ErrorIs(t, err, usecase.ErrMsgInternalServerError)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserUsecase_CreateBatch(t *testing.T) {
+	validate := validator.New()
+
+	ctx := context.Background()
+
+	newUser := func(email string) entities.User {
+		return entities.User{Name: "Test User", Family: "Test Family", Email: email, Age: 30}
+	}
+	saved := func(id uint64, user entities.User) entities.User {
+		user.ID = id
+		user.Version = 1
+		return user
+	}
+
+	first, second, taken := newUser("first@gmail.com"), newUser("second@gmail.com"), newUser("taken@gmail.com")
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		mockRepo.On("ExistingEmails", ctx, []string{first.Email, second.Email}).Return([]string{}, nil).Once()
+		mockRepo.On("SaveBatch", mock.Anything, []entities.User{first, second}).Return([]entities.User{saved(1, first), saved(2, second)}, nil).Once()
+
+		results, err := userUsecase.CreateBatch(ctx, []entities.User{first, second}, entities.BulkAtomic)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []entities.BulkResult{{User: saved(1, first)}, {User: saved(2, second)}}, results)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AtomicDuplicateSavesNothing", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		mockRepo.On("ExistingEmails", ctx, mock.Anything).Return([]string{taken.Email}, nil).Once()
+
+		results, err := userUsecase.CreateBatch(ctx, []entities.User{first, taken, first}, entities.BulkAtomic)
+
+		assert.ErrorIs(t, err, usecase.ErrMsgBulkAborted)
+		assert.ErrorIs(t, err, usecase.ErrMsgDuplicateUser)
+		assert.ErrorIs(t, results[0].Err, usecase.ErrMsgBulkRolledBack)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrMsgDuplicateUser)
+		assert.ErrorIs(t, results[2].Err, usecase.ErrMsgDuplicateUser, "an email repeated in the batch is a duplicate")
+		mockRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("BestEffortSavesTheRest", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		mockRepo.On("ExistingEmails", ctx, mock.Anything).Return([]string{taken.Email}, nil).Once()
+		mockRepo.On("SaveBatch", mock.Anything, []entities.User{first, second}).Return([]entities.User{saved(1, first), saved(2, second)}, nil).Once()
+
+		results, err := userUsecase.CreateBatch(ctx, []entities.User{first, taken, second}, entities.BulkBestEffort)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), results[0].User.ID)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrMsgDuplicateUser)
+		assert.Equal(t, uint64(2), results[2].User.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("PasswordTooLongFailsItsItem", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		// 40 characters, 80 bytes, more than bcrypt takes
+		long := newUser("long@gmail.com")
+		long.Password = strings.Repeat("رمز", 13) + "ز"
+
+		mockRepo.On("ExistingEmails", ctx, mock.Anything).Return([]string{}, nil).Once()
+		mockRepo.On("SaveBatch", mock.Anything, []entities.User{first, second}).Return([]entities.User{saved(1, first), saved(2, second)}, nil).Once()
+
+		results, err := userUsecase.CreateBatch(ctx, []entities.User{first, long, second}, entities.BulkBestEffort)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), results[0].User.ID)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrMsgInvalidPassword)
+		assert.Equal(t, uint64(2), results[2].User.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("AtomicPasswordTooLongSavesNothing", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		long := newUser("long@gmail.com")
+		long.Password = strings.Repeat("a", 73)
+
+		mockRepo.On("ExistingEmails", ctx, mock.Anything).Return([]string{}, nil).Once()
+
+		results, err := userUsecase.CreateBatch(ctx, []entities.User{first, long, second}, entities.BulkAtomic)
+
+		assert.ErrorIs(t, err, usecase.ErrMsgBulkAborted)
+		assert.ErrorIs(t, err, usecase.ErrMsgInvalidPassword)
+		assert.ErrorIs(t, results[0].Err, usecase.ErrMsgBulkRolledBack)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrMsgInvalidPassword)
+		assert.ErrorIs(t, results[2].Err, usecase.ErrMsgBulkRolledBack)
+		mockRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("StopsHashingWhenCancelled", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		users := make([]entities.User, 50)
+		for i := range users {
+			users[i] = newUser(fmt.Sprintf("user%d@gmail.com", i))
+			users[i].Password = "secret-password"
+		}
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		mockRepo.On("ExistingEmails", cancelled, mock.Anything).Return([]string{}, nil).Once()
+
+		results, err := userUsecase.CreateBatch(cancelled, users, entities.BulkBestEffort)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, results)
+		mockRepo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("BestEffortFallsBackOnRace", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		mockRepo.On("ExistingEmails", ctx, mock.Anything).Return([]string{}, nil).Once()
+		mockRepo.On("SaveBatch", mock.Anything, mock.Anything).Return(nil, repository.ErrMsgDuplicateUser).Once()
+		mockRepo.On("Save", mock.Anything, first).Return(saved(1, first), nil).Once()
+		mockRepo.On("Save", mock.Anything, second).Return(entities.User{}, repository.ErrMsgDuplicateUser).Once()
+
+		results, err := userUsecase.CreateBatch(ctx, []entities.User{first, second}, entities.BulkBestEffort)
+
+		assert.NoError(t, err)
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrMsgDuplicateUser)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserUsecase_PatchBatch(t *testing.T) {
+	validate := validator.New()
+
+	ctx := context.Background()
+
+	stored := entities.User{ID: 1, Name: "Test User", Family: "Test Family", Email: "test@gmail.com", Age: 30, Version: 1}
+	age := 40
+	patches := []entities.UserBatchPatch{
+		{ID: 1, Patch: entities.UserPatch{Age: &age}},
+		{ID: 2, Patch: entities.UserPatch{Age: &age}},
+	}
+
+	t.Run("AtomicRollsBack", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		updated := stored
+		updated.Age = age
+		mockRepo.On("FindByID", mock.Anything, uint64(1)).Return(stored, nil).Once()
+		mockRepo.On("Update", mock.Anything, updated).Return(updated, nil).Once()
+		mockRepo.On("FindByID", mock.Anything, uint64(2)).Return(entities.User{}, repository.ErrMsgUserNotFound).Once()
+
+		results, err := userUsecase.PatchBatch(ctx, patches, entities.BulkAtomic)
+
+		assert.ErrorIs(t, err, usecase.ErrMsgBulkAborted)
+		assert.ErrorIs(t, err, usecase.ErrMsgUserNotFound)
+		assert.ErrorIs(t, results[0].Err, usecase.ErrMsgBulkRolledBack)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrMsgUserNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("BestEffort", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		updated := stored
+		updated.Age = age
+		mockRepo.On("FindByID", mock.Anything, uint64(1)).Return(stored, nil).Once()
+		mockRepo.On("Update", mock.Anything, updated).Return(updated, nil).Once()
+		mockRepo.On("FindByID", mock.Anything, uint64(2)).Return(entities.User{}, repository.ErrMsgUserNotFound).Once()
+
+		results, err := userUsecase.PatchBatch(ctx, patches, entities.BulkBestEffort)
+
+		assert.NoError(t, err)
+		assert.Equal(t, updated, results[0].User)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrMsgUserNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserUsecase_DeleteBatch(t *testing.T) {
+	validate := validator.New()
+
+	ctx := context.Background()
+
+	t.Run("AtomicMissingID", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		mockRepo.On("DeleteBatch", mock.Anything, []uint64{1, 2}).Return([]uint64{1}, nil).Once()
+
+		results, err := userUsecase.DeleteBatch(ctx, []uint64{1, 2}, entities.BulkAtomic)
+
+		assert.ErrorIs(t, err, usecase.ErrMsgBulkAborted)
+		assert.ErrorIs(t, results[0].Err, usecase.ErrMsgBulkRolledBack)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrMsgUserNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("BestEffortMissingID", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		mockRepo.On("DeleteBatch", mock.Anything, []uint64{1, 2}).Return([]uint64{1}, nil).Once()
+
+		results, err := userUsecase.DeleteBatch(ctx, []uint64{1, 2}, entities.BulkBestEffort)
+
+		assert.NoError(t, err)
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, uint64(1), results[0].User.ID)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrMsgUserNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InternalServerError", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		userUsecase := usecase.NewUserUsecase(mockRepo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		mockRepo.On("DeleteBatch", mock.Anything, []uint64{1}).Return(nil, errors.New("database error")).Once()
+
+		results, err := userUsecase.DeleteBatch(ctx, []uint64{1}, entities.BulkBestEffort)
+
+		assert.ErrorIs(t, err, usecase.ErrMsgInternalServerError)
+		assert.Nil(t, results)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserUsecase_Import(t *testing.T) {
+	validate := validator.New()
+
+	ctx := context.Background()
+
+	newUser := func(email string, age int) entities.User {
+		return entities.User{Name: "Test User", Family: "Test Family", Email: email, Age: age}
+	}
+
+	// the in-memory repository gives the import real conflicts and rollbacks
+	setup := func(t *testing.T) (usecase.UserUsecase, repository.UserRepository) {
+		repo := repository.NewMemoryUserRepository(clock.New())
+		_, err := repo.Save(ctx, newUser("taken@gmail.com", 30))
+		assert.NoError(t, err)
+
+		return usecase.NewUserUsecase(repo, repository.NewMemoryAuditRepository(clock.New()), repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate), repo
+	}
+
+	users := []entities.User{newUser("new@gmail.com", 20), newUser("taken@gmail.com", 40), newUser("new@gmail.com", 50)}
+
+	countUsers := func(t *testing.T, repo repository.UserRepository) int64 {
+		page, err := repo.FindAll(ctx, entities.UserListQuery{Limit: 10})
+		assert.NoError(t, err)
+		return page.Total
+	}
+
+	t.Run("Skip", func(t *testing.T) {
+		userUsecase, repo := setup(t)
+
+		results, err := userUsecase.Import(ctx, users, entities.ImportOptions{OnConflict: entities.ConflictSkip})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ImportCreated, results[0].Outcome)
+		assert.NotZero(t, results[0].User.ID)
+		assert.Equal(t, entities.ImportSkipped, results[1].Outcome)
+		assert.ErrorIs(t, results[2].Err, usecase.ErrMsgDuplicateUser, "an email repeated in the import is a duplicate")
+		assert.Equal(t, int64(2), countUsers(t, repo))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		userUsecase, repo := setup(t)
+
+		results, err := userUsecase.Import(ctx, users[:2], entities.ImportOptions{OnConflict: entities.ConflictUpdate})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ImportUpdated, results[1].Outcome)
+		assert.Equal(t, 40, results[1].User.Age)
+		assert.Equal(t, 2, results[1].User.Version)
+
+		stored, err := repo.FindByEmail(ctx, "taken@gmail.com")
+		assert.NoError(t, err)
+		assert.Equal(t, 40, stored.Age)
+	})
+
+	t.Run("FailKeepsRepeatedEmailAsRow", func(t *testing.T) {
+		userUsecase, repo := setup(t)
+
+		results, err := userUsecase.Import(ctx, []entities.User{users[0], users[2]}, entities.ImportOptions{OnConflict: entities.ConflictFail})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ImportCreated, results[0].Outcome)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrMsgDuplicateUser)
+		assert.Equal(t, int64(2), countUsers(t, repo))
+	})
+
+	t.Run("FailRollsBack", func(t *testing.T) {
+		userUsecase, repo := setup(t)
+
+		results, err := userUsecase.Import(ctx, users[:2], entities.ImportOptions{OnConflict: entities.ConflictFail})
+
+		assert.ErrorIs(t, err, usecase.ErrMsgBulkAborted)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrMsgDuplicateUser)
+		assert.ErrorIs(t, results[0].Err, usecase.ErrMsgBulkRolledBack)
+		assert.Equal(t, int64(1), countUsers(t, repo))
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		userUsecase, repo := setup(t)
+
+		results, err := userUsecase.Import(ctx, users[:2], entities.ImportOptions{OnConflict: entities.ConflictUpdate, DryRun: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ImportCreated, results[0].Outcome)
+		assert.Zero(t, results[0].User.ID, "dry-run creates nothing")
+		assert.Equal(t, entities.ImportUpdated, results[1].Outcome)
+
+		stored, err := repo.FindByEmail(ctx, "taken@gmail.com")
+		assert.NoError(t, err)
+		assert.Equal(t, 30, stored.Age, "dry-run changes nothing")
+		assert.Equal(t, int64(1), countUsers(t, repo))
+	})
+}
+
+// failingAudit is an audit repository that can't write.
+type failingAudit struct {
+	repository.AuditRepository
+}
+
+func (failingAudit) Record(ctx context.Context, event entities.AuditEvent) (entities.AuditEvent, error) {
+	return entities.AuditEvent{}, errors.New("audit table is gone")
+}
+
+func TestUserUsecase_Audit(t *testing.T) {
+	validate := validator.New()
+
+	ctx := requestid.WithRequestID(actor.WithActor(context.Background(), "admin"), "req-1")
+
+	setup := func(t *testing.T) (usecase.UserUsecase, repository.AuditRepository) {
+		audit := repository.NewMemoryAuditRepository(clock.New())
+		return usecase.NewUserUsecase(repository.NewMemoryUserRepository(clock.New()), audit, repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate), audit
+	}
+
+	events := func(t *testing.T, audit repository.AuditRepository) []entities.AuditEvent {
+		page, err := audit.List(ctx, entities.AuditQuery{})
+		require.NoError(t, err)
+		return page.Events
+	}
+
+	t.Run("EveryChange", func(t *testing.T) {
+		userUsecase, audit := setup(t)
+
+		user, err := userUsecase.Create(ctx, entities.User{Name: "Ali", Family: "Ahmadi", Email: "ali@gmail.com", Age: 30, Password: "correct horse"})
+		require.NoError(t, err)
+
+		name := "Reza"
+		_, err = userUsecase.Patch(ctx, user.ID, entities.UserPatch{Name: &name})
+		require.NoError(t, err)
+
+		require.NoError(t, userUsecase.Delete(ctx, user.ID))
+
+		recorded := events(t, audit)
+		require.Len(t, recorded, 3)
+
+		deleted, updated, created := recorded[0], recorded[1], recorded[2]
+		assert.Equal(t, entities.AuditUserCreated, created.Action)
+		assert.Equal(t, user.ID, created.UserID)
+		assert.Equal(t, "admin", created.Actor)
+		assert.Equal(t, "req-1", created.RequestID)
+		assert.Contains(t, created.Changes, entities.AuditChange{Field: "email", Before: nil, After: "ali@gmail.com"})
+		assert.Contains(t, created.Changes, entities.AuditChange{Field: "password", Before: nil, After: "[redacted]"}, "the hash is never recorded")
+
+		assert.Equal(t, entities.AuditUserUpdated, updated.Action)
+		assert.Equal(t, []entities.AuditChange{{Field: "name", Before: "Ali", After: "Reza"}}, updated.Changes, "only changed fields are recorded")
+
+		assert.Equal(t, entities.AuditUserDeleted, deleted.Action)
+		assert.Equal(t, entities.DeletedChange(true), deleted.Changes)
+	})
+
+	t.Run("FailedChangeNotRecorded", func(t *testing.T) {
+		userUsecase, audit := setup(t)
+
+		user, err := userUsecase.Create(ctx, entities.User{Name: "Ali", Family: "Ahmadi", Email: "ali@gmail.com", Age: 30})
+		require.NoError(t, err)
+
+		stale := user.Version + 1
+		_, err = userUsecase.Patch(ctx, user.ID, entities.UserPatch{Versions: []int{stale}})
+		assert.ErrorIs(t, err, usecase.ErrMsgVersionConflict)
+
+		assert.Len(t, events(t, audit), 1)
+	})
+
+	t.Run("AuditFailureRollsBackChange", func(t *testing.T) {
+		repo := repository.NewMemoryUserRepository(clock.New())
+		userUsecase := usecase.NewUserUsecase(repo, failingAudit{}, repository.NewMemoryTxManager(), usecase.AllowAll(), clock.New(), validate)
+
+		_, err := userUsecase.Create(ctx, entities.User{Name: "Ali", Family: "Ahmadi", Email: "ali@gmail.com", Age: 30})
+		assert.ErrorIs(t, err, usecase.ErrMsgInternalServerError)
+
+		_, err = repo.FindByEmail(ctx, "ali@gmail.com")
+		assert.ErrorIs(t, err, repository.ErrMsgUserNotFound, "a change that can't be audited is not made")
+	})
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+-- a soft-deleted user must not block the email for a new account
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+CREATE UNIQUE INDEX users_email_active_key ON users (email) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX users_email_active_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+DROP INDEX idx_users_deleted_at;
+ALTER TABLE users DROP COLUMN deleted_at;
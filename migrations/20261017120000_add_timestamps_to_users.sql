@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE users ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE users ADD COLUMN created_by VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN updated_by VARCHAR(255) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN updated_by;
+ALTER TABLE users DROP COLUMN created_by;
+ALTER TABLE users DROP COLUMN updated_at;
+ALTER TABLE users DROP COLUMN created_at;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN password_hash VARCHAR(255) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN password_hash;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    service_account VARCHAR(255) NOT NULL,
+    prefix VARCHAR(32) NOT NULL UNIQUE,
+    key_hash VARCHAR(64) NOT NULL,
+    scopes VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NULL,
+    last_used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    revoked_at TIMESTAMPTZ NULL
+);
+
+INSERT INTO permissions (name, description) VALUES ('apikeys:manage', 'Create, list and revoke api keys');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'apikeys:manage'
+WHERE r.name = 'admin';
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'apikeys:manage';
+DROP TABLE api_keys;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE user_mfa (
+    user_id INT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret VARCHAR(255) NOT NULL,
+    enabled_at TIMESTAMPTZ NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE mfa_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    used_at TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX idx_mfa_recovery_codes_user_id_code_hash ON mfa_recovery_codes (user_id, code_hash);
+
+INSERT INTO permissions (name, description) VALUES
+    ('mfa:verify', 'Verify the second factor of any user'),
+    ('mfa:verify:self', 'Verify the own second factor');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON
+    (r.name IN ('admin', 'support') AND p.name = 'mfa:verify')
+    OR (r.name = 'user' AND p.name = 'mfa:verify:self');
+
+-- +goose Down
+DELETE FROM permissions WHERE name IN ('mfa:verify', 'mfa:verify:self');
+DROP TABLE mfa_recovery_codes;
+DROP TABLE user_mfa;
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ NULL;
+
+CREATE TABLE one_time_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_one_time_tokens_user_id_purpose ON one_time_tokens (user_id, purpose);
+
+-- +goose Down
+DROP TABLE one_time_tokens;
+ALTER TABLE users DROP COLUMN email_verified_at;
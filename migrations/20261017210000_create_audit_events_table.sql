@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    user_id BIGINT NOT NULL,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    changes TEXT NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- no foreign key on user_id, events outlive the users they describe
+CREATE INDEX idx_audit_events_user_id ON audit_events (user_id, id);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor, id);
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at);
+
+INSERT INTO permissions (name, description) VALUES ('audit:read', 'Read the audit log of user changes');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'audit:read'
+WHERE r.name = 'admin';
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'audit:read';
+DROP TABLE audit_events;
@@ -0,0 +1,9 @@
+-- +goose Up
+INSERT INTO permissions (name, description) VALUES
+    ('mfa:manage', 'Turn off the second factor of any user');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON r.name = 'admin' AND p.name = 'mfa:manage';
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'mfa:manage';
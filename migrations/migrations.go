@@ -4,4 +4,12 @@ import (
 	"embed"
 )
 
+// Postgres migrations live at the root, SQLite ones under SQLiteDir.
+//
+//go:embed *.sql sqlite/*.sql
 var Migration embed.FS
+
+const (
+	PostgresDir = "."
+	SQLiteDir   = "sqlite"
+)
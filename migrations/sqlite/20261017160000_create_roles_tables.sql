@@ -0,0 +1,55 @@
+-- +goose Up
+CREATE TABLE roles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE permissions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE user_roles (
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role_id INTEGER NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (user_id, role_id)
+);
+CREATE INDEX idx_user_roles_role_id ON user_roles (role_id);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Manages every user and their roles'),
+    ('support', 'Reads and updates any user, can not delete'),
+    ('user', 'Reads and updates their own record, held by every user');
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read', 'Read any user'),
+    ('users:create', 'Create users'),
+    ('users:update', 'Update any user'),
+    ('users:delete', 'Delete, restore and purge users'),
+    ('sessions:read', 'List the sessions of any user'),
+    ('roles:manage', 'Assign and revoke roles'),
+    ('users:read:self', 'Read the own user'),
+    ('users:update:self', 'Update the own user'),
+    ('sessions:read:self', 'List the own sessions');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON
+    (r.name = 'admin' AND p.name NOT LIKE '%:self')
+    OR (r.name = 'support' AND p.name IN ('users:read', 'users:update', 'sessions:read'))
+    OR (r.name = 'user' AND p.name IN ('users:read:self', 'users:update:self', 'sessions:read:self'));
+
+-- +goose Down
+DROP TABLE user_roles;
+DROP TABLE role_permissions;
+DROP TABLE permissions;
+DROP TABLE roles;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    service_account TEXT NOT NULL,
+    prefix TEXT NOT NULL UNIQUE,
+    key_hash TEXT NOT NULL,
+    scopes TEXT NOT NULL DEFAULT '',
+    expires_at DATETIME NULL,
+    last_used_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL DEFAULT '',
+    revoked_at DATETIME NULL
+);
+
+INSERT INTO permissions (name, description) VALUES ('apikeys:manage', 'Create, list and revoke api keys');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'apikeys:manage'
+WHERE r.name = 'admin';
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'apikeys:manage';
+DROP TABLE api_keys;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE user_mfa (
+    user_id INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at DATETIME NULL,
+    last_used_step INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE mfa_recovery_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    used_at DATETIME NULL
+);
+CREATE UNIQUE INDEX idx_mfa_recovery_codes_user_id_code_hash ON mfa_recovery_codes (user_id, code_hash);
+
+INSERT INTO permissions (name, description) VALUES
+    ('mfa:verify', 'Verify the second factor of any user'),
+    ('mfa:verify:self', 'Verify the own second factor');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON
+    (r.name IN ('admin', 'support') AND p.name = 'mfa:verify')
+    OR (r.name = 'user' AND p.name = 'mfa:verify:self');
+
+-- +goose Down
+DELETE FROM permissions WHERE name IN ('mfa:verify', 'mfa:verify:self');
+DROP TABLE mfa_recovery_codes;
+DROP TABLE user_mfa;
@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME NULL;
+
+CREATE TABLE one_time_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    purpose TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_one_time_tokens_user_id_purpose ON one_time_tokens (user_id, purpose);
+
+-- +goose Down
+DROP TABLE one_time_tokens;
+ALTER TABLE users DROP COLUMN email_verified_at;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE credential_lockouts (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    lockouts INTEGER NOT NULL DEFAULT 0,
+    last_failure_at DATETIME NOT NULL,
+    locked_until DATETIME NULL
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:unlock', 'Unlock any user locked out after failed logins'),
+    ('credentials:verify', 'Check the password of any user');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON
+    (r.name IN ('admin', 'support') AND p.name = 'users:unlock')
+    OR (r.name = 'admin' AND p.name = 'credentials:verify');
+
+-- +goose Down
+DELETE FROM permissions WHERE name IN ('users:unlock', 'credentials:verify');
+DROP TABLE credential_lockouts;
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    changes TEXT NOT NULL DEFAULT '[]',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- no foreign key on user_id, events outlive the users they describe
+CREATE INDEX idx_audit_events_user_id ON audit_events (user_id, id);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor, id);
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at);
+
+INSERT INTO permissions (name, description) VALUES ('audit:read', 'Read the audit log of user changes');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'audit:read'
+WHERE r.name = 'admin';
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'audit:read';
+DROP TABLE audit_events;